@@ -0,0 +1,47 @@
+// Command admin grants or revokes the admin role by email. Use it to create
+// the first administrator; after that, admins can manage roles through
+// PATCH /api/admin/users/:id/role.
+//
+//	go run ./cmd/admin -email someone@example.com [-role admin|user]
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/joho/godotenv"
+	"github.com/satyam-svg/hr-message-backend/internals/services"
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+)
+
+func main() {
+	email := flag.String("email", "", "email of the user to update")
+	role := flag.String("role", "admin", "role to assign: admin or user")
+	flag.Parse()
+
+	if *email == "" {
+		log.Fatal("-email is required")
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: .env file not found")
+	}
+
+	client := db.NewClient()
+	if err := client.Prisma.Connect(); err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer func() {
+		if err := client.Prisma.Disconnect(); err != nil {
+			log.Printf("Failed to disconnect from database: %v", err)
+		}
+	}()
+
+	if err := services.NewAdminService(client).SetRole(context.Background(), *email, *role); err != nil {
+		log.Fatalf("Failed to set role: %v", err)
+	}
+
+	log.Printf("Set role of %s to %s", *email, *role)
+}
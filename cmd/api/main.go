@@ -5,16 +5,21 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
+	// Embed the time zone database so send windows work on hosts without one
+	_ "time/tzdata"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/joho/godotenv"
 	"github.com/satyam-svg/hr-message-backend/internals/handlers"
+	"github.com/satyam-svg/hr-message-backend/internals/middleware"
 	"github.com/satyam-svg/hr-message-backend/internals/routes"
 	"github.com/satyam-svg/hr-message-backend/internals/services"
+	"github.com/satyam-svg/hr-message-backend/internals/utils"
 	"github.com/satyam-svg/hr-message-backend/prisma/db"
 )
 
@@ -24,6 +29,11 @@ func main() {
 		log.Println("Warning: .env file not found")
 	}
 
+	// Refuse to start without access token signing keys in production
+	if err := utils.LoadSigningKeys(); err != nil {
+		log.Fatalf("Failed to load JWT signing keys: %v", err)
+	}
+
 	// Initialize Prisma client
 	client := db.NewClient()
 	if err := client.Prisma.Connect(); err != nil {
@@ -38,7 +48,7 @@ func main() {
 	log.Println("✅ Connected to database")
 
 	// Initialize Fiber app
-	app := fiber.New(fiber.Config{
+	config := fiber.Config{
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			code := fiber.StatusInternalServerError
 			if e, ok := err.(*fiber.Error); ok {
@@ -49,30 +59,76 @@ func main() {
 				"message": err.Error(),
 			})
 		},
-	})
+	}
+
+	// Behind a reverse proxy (e.g. Render) the client address comes from a
+	// header, which is only read on requests from the trusted proxies.
+	// Without this every request has the proxy's address and the login
+	// throttle would apply to all users together.
+	if proxies := splitList(os.Getenv("TRUSTED_PROXIES")); len(proxies) > 0 {
+		config.ProxyHeader = os.Getenv("PROXY_HEADER")
+		if config.ProxyHeader == "" {
+			config.ProxyHeader = fiber.HeaderXForwardedFor
+		}
+		config.EnableTrustedProxyCheck = true
+		config.TrustedProxies = proxies
+		config.EnableIPValidation = true
+	}
+	app := fiber.New(config)
 
 	// Middleware
 	app.Use(logger.New())
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     "*",
 		AllowMethods:     "GET,POST,PUT,PATCH,DELETE,OPTIONS",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Organization-Id",
 		AllowCredentials: false,
 	}))
 
 	// Initialize services
 	authService := services.NewAuthService(client)
-	emailService := services.NewEmailService(client)
+	emailService := services.NewEmailService(client, services.RealClock{})
 	templateService := services.NewTemplateService(client)
 	contactService := services.NewContactServcie(client)
 	userService := services.NewUserService(client)
+	activityService := services.NewActivityService(client)
+	companyService := services.NewCompanyService(client)
+	trashService := services.NewTrashService(client)
+	sessionService := services.NewSessionService(client)
+	mfaService := services.NewMFAService(client)
+	loginGuardService := services.NewLoginGuardService(client)
+	apiKeyService := services.NewAPIKeyService(client)
+	organizationService := services.NewOrganizationService(client)
+	adminService := services.NewAdminService(client)
+	oidcService := services.NewOIDCService(client)
+	mailOAuthService := services.NewMailOAuthService(client)
+	senderAccountService := services.NewSenderAccountService(client)
+	sequenceService := services.NewSequenceService(client)
+	replySyncService := services.NewReplySyncService(client, services.RealClock{})
+
+	// Let the auth middleware reject access tokens of revoked sessions, check API keys and look up admins
+	middleware.SetSessionValidator(sessionService)
+	middleware.SetAPIKeyValidator(apiKeyService)
+	middleware.SetAdminChecker(adminService)
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
 	emailHandler := handlers.NewEmailHandler(emailService)
-	pdfHandler := handlers.NewPDFHandler(client, userService)
+	pdfHandler := handlers.NewPDFHandler(client, userService, companyService, organizationService)
 	templateHandler := handlers.NewTemplateHandler(templateService)
 	contactHandler := handlers.NewContactHandler(contactService)
+	activityHandler := handlers.NewActivityHandler(activityService)
+	companyHandler := handlers.NewCompanyHandler(companyService)
+	trashHandler := handlers.NewTrashHandler(trashService)
+	mfaHandler := handlers.NewMFAHandler(mfaService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	organizationHandler := handlers.NewOrganizationHandler(organizationService)
+	adminHandler := handlers.NewAdminHandler(adminService)
+	wellKnownHandler := handlers.NewWellKnownHandler()
+	oidcHandler := handlers.NewOIDCHandler(oidcService)
+	mailOAuthHandler := handlers.NewMailOAuthHandler(mailOAuthService)
+	senderAccountHandler := handlers.NewSenderAccountHandler(senderAccountService, replySyncService)
+	sequenceHandler := handlers.NewSequenceHandler(sequenceService)
 
 	// Setup routes
 	routes.SetupAuthRoutes(app, authHandler)
@@ -80,6 +136,31 @@ func main() {
 	routes.SetupPDFRoutes(app, pdfHandler)
 	routes.SetupTemplateRoutes(app, templateHandler)
 	routes.SetupContactRoutes(app, contactHandler)
+	routes.SetupActivityRoutes(app, activityHandler)
+	routes.SetupCompanyRoutes(app, companyHandler)
+	routes.SetupTrashRoutes(app, trashHandler)
+	routes.SetupMFARoutes(app, mfaHandler)
+	routes.SetupAPIKeyRoutes(app, apiKeyHandler)
+	routes.SetupOrganizationRoutes(app, organizationHandler)
+	routes.SetupAdminRoutes(app, adminHandler)
+	routes.SetupWellKnownRoutes(app, wellKnownHandler)
+	routes.SetupOIDCRoutes(app, oidcHandler)
+	routes.SetupMailOAuthRoutes(app, mailOAuthHandler)
+	routes.SetupSenderAccountRoutes(app, senderAccountHandler)
+	routes.SetupSequenceRoutes(app, sequenceHandler)
+
+	// Permanently delete trashed items and old login attempts once their retention period is over
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	go trashService.RunPurgeLoop(purgeCtx, time.Hour)
+	go loginGuardService.RunCleanupLoop(purgeCtx, time.Hour)
+
+	// Start scheduled campaigns, resume paused ones when their send window opens
+	// and send due follow-ups
+	go emailService.RunSchedulerLoop(purgeCtx, time.Minute)
+
+	// Check the inboxes of sender accounts for replies from contacts
+	go replySyncService.RunSyncLoop(purgeCtx, 5*time.Minute)
 
 	// Health check endpoint
 	app.Get("/", func(c *fiber.Ctx) error {
@@ -126,6 +207,7 @@ func main() {
 	<-quit
 
 	log.Println("⚠️  Shutdown initiated...")
+	stopPurge()
 
 	_, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -142,3 +224,15 @@ func main() {
 	log.Println("✅ Fiber server shutdown successfully")
 
 }
+
+// splitList splits a comma separated environment variable, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,218 @@
+// Command mockoidc runs a minimal OpenID Connect issuer for trying single
+// sign-on locally. It signs in everyone without a password as -email (or the
+// login_hint of the authorization request) and supports PKCE.
+//
+//	go run ./cmd/mockoidc [-addr :9000] [-email dev@example.com] [-verified=false]
+//
+// Point the API at it with OIDC_ISSUER_URL=http://localhost:9000 and
+// OIDC_CLIENT_ID set to any value.
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+)
+
+// codeExpiry is how long an authorization code can be exchanged
+const codeExpiry = time.Minute
+
+// authorization is a pending code issued by /authorize
+type authorization struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	email         string
+	expiresAt     time.Time
+}
+
+type issuer struct {
+	url      string
+	email    string
+	verified bool
+	key      *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]authorization
+}
+
+func main() {
+	addr := flag.String("addr", ":9000", "address to listen on")
+	issuerURL := flag.String("issuer", "", "issuer URL (default: http://localhost<addr>)")
+	email := flag.String("email", "dev@example.com", "email of the signed in user")
+	verified := flag.Bool("verified", true, "whether the email is reported as verified")
+	flag.Parse()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		log.Fatalf("Failed to generate key: %v", err)
+	}
+
+	iss := &issuer{
+		url:      *issuerURL,
+		email:    *email,
+		verified: *verified,
+		key:      key,
+		codes:    map[string]authorization{},
+	}
+	if iss.url == "" {
+		iss.url = "http://localhost" + *addr
+	}
+
+	http.HandleFunc("/.well-known/openid-configuration", iss.configuration)
+	http.HandleFunc("/authorize", iss.authorize)
+	http.HandleFunc("/token", iss.token)
+	http.HandleFunc("/jwks", iss.jwks)
+
+	log.Printf("Mock OpenID Connect issuer %s signing in %s", iss.url, iss.email)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
+
+func (i *issuer) configuration(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                i.url,
+		"authorization_endpoint":                i.url + "/authorize",
+		"token_endpoint":                        i.url + "/token",
+		"jwks_uri":                              i.url + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+	})
+}
+
+// authorize signs the user in immediately and redirects back with a code
+func (i *issuer) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "only the code flow with S256 PKCE is supported", http.StatusBadRequest)
+		return
+	}
+
+	email := i.email
+	if hint := query.Get("login_hint"); hint != "" {
+		email = hint
+	}
+
+	code := randomString()
+	i.mu.Lock()
+	i.codes[code] = authorization{
+		clientID:      query.Get("client_id"),
+		redirectURI:   query.Get("redirect_uri"),
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+		email:         email,
+		expiresAt:     time.Now().Add(codeExpiry),
+	}
+	i.mu.Unlock()
+
+	params := redirectURI.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	redirectURI.RawQuery = params.Encode()
+
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+// token exchanges a code for an ID token after checking the PKCE verifier
+func (i *issuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.Method != http.MethodPost {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	i.mu.Lock()
+	auth, ok := i.codes[r.PostForm.Get("code")]
+	delete(i.codes, r.PostForm.Get("code"))
+	i.mu.Unlock()
+
+	clientID := r.PostForm.Get("client_id")
+	if basicID, _, hasBasic := r.BasicAuth(); hasBasic {
+		clientID, _ = url.QueryUnescape(basicID)
+	}
+
+	challenge := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	switch {
+	case !ok || auth.expiresAt.Before(time.Now()),
+		auth.clientID != clientID,
+		auth.redirectURI != r.PostForm.Get("redirect_uri"),
+		base64.RawURLEncoding.EncodeToString(challenge[:]) != auth.codeChallenge:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	subject := sha256.Sum256([]byte(auth.email))
+	name, _, _ := strings.Cut(auth.email, "@")
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            i.url,
+		"sub":            hex.EncodeToString(subject[:16]),
+		"aud":            auth.clientID,
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          auth.nonce,
+		"email":          auth.email,
+		"email_verified": i.verified,
+		"name":           name,
+	})
+	idToken.Header["kid"] = "mock"
+	signed, err := idToken.SignedString(i.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     signed,
+	})
+}
+
+func (i *issuer) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, models.JWKSResponse{Keys: []models.JWK{{
+		KeyType:   "RSA",
+		KeyID:     "mock",
+		Use:       "sig",
+		Algorithm: "RS256",
+		Modulus:   base64.RawURLEncoding.EncodeToString(i.key.N.Bytes()),
+		Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(i.key.E)).Bytes()),
+	}}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("Failed to write response: %v", err)
+	}
+}
+
+func randomString() string {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		log.Fatalf("Failed to generate random value: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,204 @@
+// Command reencrypt encrypts stored secrets (SMTP app passwords, TOTP
+// secrets, Gmail refresh tokens and sender account passwords) with the
+// active encryption key. Run it after adding a new key to ENCRYPTION_KEYS
+// and making it active, or once to encrypt passwords saved before
+// encryption existed.
+//
+//	go run ./cmd/reencrypt [-dry-run]
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/joho/godotenv"
+	"github.com/satyam-svg/hr-message-backend/internals/utils"
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+)
+
+// secretField describes an encrypted column of the User table
+type secretField struct {
+	name   string
+	isNull db.UserWhereParam
+	get    func(user *db.UserModel) (string, bool)
+	equals func(value string) db.UserWhereParam
+	set    func(value string) db.UserSetParam
+}
+
+var secretFields = []secretField{
+	{
+		name:   "mail app password",
+		isNull: db.User.MailAppPassword.IsNull(),
+		get:    func(user *db.UserModel) (string, bool) { return user.MailAppPassword() },
+		equals: func(value string) db.UserWhereParam { return db.User.MailAppPassword.Equals(value) },
+		set:    func(value string) db.UserSetParam { return db.User.MailAppPassword.Set(value) },
+	},
+	{
+		name:   "TOTP secret",
+		isNull: db.User.TotpSecret.IsNull(),
+		get:    func(user *db.UserModel) (string, bool) { return user.TotpSecret() },
+		equals: func(value string) db.UserWhereParam { return db.User.TotpSecret.Equals(value) },
+		set:    func(value string) db.UserSetParam { return db.User.TotpSecret.Set(value) },
+	},
+	{
+		name:   "Gmail refresh token",
+		isNull: db.User.MailOAuthRefreshToken.IsNull(),
+		get:    func(user *db.UserModel) (string, bool) { return user.MailOAuthRefreshToken() },
+		equals: func(value string) db.UserWhereParam { return db.User.MailOAuthRefreshToken.Equals(value) },
+		set:    func(value string) db.UserSetParam { return db.User.MailOAuthRefreshToken.Set(value) },
+	},
+}
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report what would be re-encrypted without writing")
+	flag.Parse()
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: .env file not found")
+	}
+
+	client := db.NewClient()
+	if err := client.Prisma.Connect(); err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer func() {
+		if err := client.Prisma.Disconnect(); err != nil {
+			log.Printf("Failed to disconnect from database: %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+
+	updated, failed := 0, 0
+	for _, field := range secretFields {
+		u, f := reencryptField(ctx, client, field, *dryRun)
+		updated += u
+		failed += f
+	}
+	u, f := reencryptSenderAccounts(ctx, client, *dryRun)
+	updated += u
+	failed += f
+
+	log.Printf("Re-encrypted %d secrets, %d failed (dry run: %v)", updated, failed, *dryRun)
+	if failed > 0 {
+		log.Fatal("Some secrets could not be re-encrypted")
+	}
+}
+
+// reencryptField re-encrypts one column for every user and returns the
+// number of updated and failed rows
+func reencryptField(ctx context.Context, client *db.PrismaClient, field secretField, dryRun bool) (int, int) {
+	users, err := client.User.FindMany(
+		db.User.Not(field.isNull),
+	).Exec(ctx)
+	if err != nil {
+		log.Fatalf("Failed to fetch users: %v", err)
+	}
+
+	updated, failed := 0, 0
+	for i := range users {
+		user := &users[i]
+		stored, _ := field.get(user)
+		if stored == "" {
+			continue
+		}
+
+		needed, err := utils.NeedsReencryption(stored)
+		if err != nil {
+			log.Fatalf("Failed to load encryption keys: %v", err)
+		}
+		if !needed {
+			continue
+		}
+
+		plaintext, err := utils.DecryptSecret(stored)
+		if err != nil {
+			log.Printf("Failed to decrypt %s of user %s: %v", field.name, user.ID, err)
+			failed++
+			continue
+		}
+
+		if dryRun {
+			log.Printf("Would re-encrypt %s of user %s", field.name, user.ID)
+			updated++
+			continue
+		}
+
+		encrypted, err := utils.EncryptSecret(plaintext)
+		if err != nil {
+			log.Fatalf("Failed to encrypt: %v", err)
+		}
+
+		// Only write if the value is unchanged since it was read
+		result, err := client.User.FindMany(
+			db.User.ID.Equals(user.ID),
+			field.equals(stored),
+		).Update(
+			field.set(encrypted),
+		).Exec(ctx)
+		if err != nil {
+			log.Printf("Failed to update user %s: %v", user.ID, err)
+			failed++
+			continue
+		}
+		updated += result.Count
+	}
+
+	return updated, failed
+}
+
+// reencryptSenderAccounts re-encrypts the password of every sender account
+// and returns the number of updated and failed rows
+func reencryptSenderAccounts(ctx context.Context, client *db.PrismaClient, dryRun bool) (int, int) {
+	accounts, err := client.SenderAccount.FindMany().Exec(ctx)
+	if err != nil {
+		log.Fatalf("Failed to fetch sender accounts: %v", err)
+	}
+
+	updated, failed := 0, 0
+	for _, account := range accounts {
+		needed, err := utils.NeedsReencryption(account.Password)
+		if err != nil {
+			log.Fatalf("Failed to load encryption keys: %v", err)
+		}
+		if !needed {
+			continue
+		}
+
+		plaintext, err := utils.DecryptSecret(account.Password)
+		if err != nil {
+			log.Printf("Failed to decrypt password of sender account %s: %v", account.ID, err)
+			failed++
+			continue
+		}
+
+		if dryRun {
+			log.Printf("Would re-encrypt password of sender account %s", account.ID)
+			updated++
+			continue
+		}
+
+		encrypted, err := utils.EncryptSecret(plaintext)
+		if err != nil {
+			log.Fatalf("Failed to encrypt: %v", err)
+		}
+
+		// Only write if the value is unchanged since it was read
+		result, err := client.SenderAccount.FindMany(
+			db.SenderAccount.ID.Equals(account.ID),
+			db.SenderAccount.Password.Equals(account.Password),
+		).Update(
+			db.SenderAccount.Password.Set(encrypted),
+		).Exec(ctx)
+		if err != nil {
+			log.Printf("Failed to update sender account %s: %v", account.ID, err)
+			failed++
+			continue
+		}
+		updated += result.Count
+	}
+
+	return updated, failed
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/internals/services"
+)
+
+// ActivityHandler handles activity log HTTP requests
+type ActivityHandler struct {
+	activityService *services.ActivityService
+}
+
+// NewActivityHandler creates a new activity handler
+func NewActivityHandler(activityService *services.ActivityService) *ActivityHandler {
+	return &ActivityHandler{
+		activityService: activityService,
+	}
+}
+
+// ListActivities handles listing the authenticated user's activities
+// GET /api/activities?type=login,signup&target_type=contact&target_id=...&page=1&limit=20
+func (h *ActivityHandler) ListActivities(c *fiber.Ctx) error {
+	// Get user ID from context (set by middleware)
+	userID := c.Locals("userId").(string)
+
+	query := models.ListActivitiesQuery{
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+		Page:       c.QueryInt("page", 1),
+		Limit:      c.QueryInt("limit", 20),
+	}
+	if types := c.Query("type"); types != "" {
+		for _, t := range strings.Split(types, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				query.Types = append(query.Types, t)
+			}
+		}
+	}
+
+	activities, err := h.activityService.ListActivities(c.Context(), userID, query)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidActivityType) {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "validation_error",
+				Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to fetch activities",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(activities)
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/internals/services"
+)
+
+// AdminHandler handles admin-only HTTP requests
+type AdminHandler struct {
+	adminService *services.AdminService
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(adminService *services.AdminService) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+	}
+}
+
+// ListUsers handles listing and searching users
+// GET /api/admin/users?search=&role=&suspended=&page=&limit=
+func (h *AdminHandler) ListUsers(c *fiber.Ctx) error {
+	query := models.ListUsersQuery{
+		Search: c.Query("search"),
+		Role:   c.Query("role"),
+		Page:   c.QueryInt("page", 1),
+		Limit:  c.QueryInt("limit", 20),
+	}
+	if value := c.Query("suspended"); value != "" {
+		suspended, err := strconv.ParseBool(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "validation_error",
+				Message: "suspended must be true or false",
+			})
+		}
+		query.Suspended = &suspended
+	}
+
+	users, err := h.adminService.ListUsers(c.Context(), query)
+	if err != nil {
+		return adminError(c, err, "Failed to fetch users")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(users)
+}
+
+// GetUser handles fetching a single user
+// GET /api/admin/users/:id
+func (h *AdminHandler) GetUser(c *fiber.Ctx) error {
+	user, err := h.adminService.GetUser(c.Context(), c.Params("id"))
+	if err != nil {
+		return adminError(c, err, "Failed to fetch user")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
+// UpdateDailyLimit handles changing a user's daily send limit
+// PATCH /api/admin/users/:id/limits
+func (h *AdminHandler) UpdateDailyLimit(c *fiber.Ctx) error {
+	var req models.UpdateDailyLimitRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	adminID := c.Locals("userId").(string)
+
+	user, err := h.adminService.UpdateDailyLimit(c.Context(), adminID, c.Params("id"), req)
+	if err != nil {
+		return adminError(c, err, "Failed to update daily limit")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
+// UpdateRole handles granting or revoking the admin role
+// PATCH /api/admin/users/:id/role
+func (h *AdminHandler) UpdateRole(c *fiber.Ctx) error {
+	var req models.UpdateUserRoleRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	adminID := c.Locals("userId").(string)
+
+	user, err := h.adminService.UpdateRole(c.Context(), adminID, c.Params("id"), req)
+	if err != nil {
+		return adminError(c, err, "Failed to update role")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
+// SuspendUser handles suspending an account
+// POST /api/admin/users/:id/suspend
+func (h *AdminHandler) SuspendUser(c *fiber.Ctx) error {
+	var req models.AdminReasonRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	adminID := c.Locals("userId").(string)
+
+	user, err := h.adminService.Suspend(c.Context(), adminID, c.Params("id"), req)
+	if err != nil {
+		return adminError(c, err, "Failed to suspend user")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
+// UnsuspendUser handles lifting an account suspension
+// POST /api/admin/users/:id/unsuspend
+func (h *AdminHandler) UnsuspendUser(c *fiber.Ctx) error {
+	var req models.AdminReasonRequest
+
+	// The reason is optional, so an empty body is accepted
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Invalid request body",
+			})
+		}
+	}
+
+	adminID := c.Locals("userId").(string)
+
+	user, err := h.adminService.Unsuspend(c.Context(), adminID, c.Params("id"), req)
+	if err != nil {
+		return adminError(c, err, "Failed to unsuspend user")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
+// ImpersonateUser handles starting a support session as a user
+// POST /api/admin/users/:id/impersonate
+func (h *AdminHandler) ImpersonateUser(c *fiber.Ctx) error {
+	var req models.AdminReasonRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	adminID := c.Locals("userId").(string)
+
+	response, err := h.adminService.Impersonate(c.Context(), adminID, c.Params("id"), req, clientInfo(c))
+	if err != nil {
+		return adminError(c, err, "Failed to impersonate user")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// ListCampaigns handles listing campaigns across all users
+// GET /api/admin/campaigns?user_id=&status=&page=&limit=
+func (h *AdminHandler) ListCampaigns(c *fiber.Ctx) error {
+	query := models.ListCampaignsQuery{
+		UserID: c.Query("user_id"),
+		Status: c.Query("status"),
+		Page:   c.QueryInt("page", 1),
+		Limit:  c.QueryInt("limit", 20),
+	}
+
+	campaigns, err := h.adminService.ListCampaigns(c.Context(), query)
+	if err != nil {
+		return adminError(c, err, "Failed to fetch campaigns")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(campaigns)
+}
+
+// GetCampaign handles fetching a campaign with its failed sends
+// GET /api/admin/campaigns/:id
+func (h *AdminHandler) GetCampaign(c *fiber.Ctx) error {
+	campaign, err := h.adminService.GetCampaign(c.Context(), c.Params("id"))
+	if err != nil {
+		return adminError(c, err, "Failed to fetch campaign")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(campaign)
+}
+
+// ListEmailFailures handles listing failed sends across all users
+// GET /api/admin/email-failures?user_id=&campaign_id=&page=&limit=
+func (h *AdminHandler) ListEmailFailures(c *fiber.Ctx) error {
+	query := models.ListEmailFailuresQuery{
+		UserID:     c.Query("user_id"),
+		CampaignID: c.Query("campaign_id"),
+		Page:       c.QueryInt("page", 1),
+		Limit:      c.QueryInt("limit", 20),
+	}
+
+	failures, err := h.adminService.ListEmailFailures(c.Context(), query)
+	if err != nil {
+		return adminError(c, err, "Failed to fetch email failures")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(failures)
+}
+
+// ListAuditLog handles listing admin actions
+// GET /api/admin/audit-log?admin_id=&target_user_id=&page=&limit=
+func (h *AdminHandler) ListAuditLog(c *fiber.Ctx) error {
+	query := models.ListAuditLogQuery{
+		AdminID:      c.Query("admin_id"),
+		TargetUserID: c.Query("target_user_id"),
+		Page:         c.QueryInt("page", 1),
+		Limit:        c.QueryInt("limit", 20),
+	}
+
+	entries, err := h.adminService.ListAuditLog(c.Context(), query)
+	if err != nil {
+		return adminError(c, err, "Failed to fetch audit log")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(entries)
+}
+
+// adminError maps admin service errors to HTTP responses
+func adminError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound),
+		errors.Is(err, services.ErrCampaignNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "not_found",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidAdminRequest):
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrCannotTargetSelf),
+		errors.Is(err, services.ErrCannotTargetAdmin),
+		errors.Is(err, services.ErrAccountSuspended):
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Error:   "conflict",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+		Error:   "server_error",
+		Message: message,
+	})
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/internals/services"
+)
+
+// APIKeyHandler handles API key HTTP requests
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// CreateAPIKey handles creating a personal API key
+// POST /api/api-keys
+func (h *APIKeyHandler) CreateAPIKey(c *fiber.Ctx) error {
+	var req models.CreateAPIKeyRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	userID := c.Locals("userId").(string)
+
+	key, err := h.apiKeyService.CreateAPIKey(c.Context(), userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidAPIKeyRequest):
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "validation_error",
+				Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrTooManyAPIKeys):
+			return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+				Error:   "limit_reached",
+				Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to create API key",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(key)
+}
+
+// ListAPIKeys handles listing the user's API keys
+// GET /api/api-keys
+func (h *APIKeyHandler) ListAPIKeys(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	keys, err := h.apiKeyService.ListAPIKeys(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to fetch API keys",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(keys)
+}
+
+// RevokeAPIKey handles revoking an API key
+// DELETE /api/api-keys/:id
+func (h *APIKeyHandler) RevokeAPIKey(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	if err := h.apiKeyService.RevokeAPIKey(c.Context(), userID, c.Params("id")); err != nil {
+		if errors.Is(err, services.ErrAPIKeyNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+				Error:   "not_found",
+				Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to revoke API key",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "API key revoked successfully",
+	})
+}
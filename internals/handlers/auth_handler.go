@@ -1,6 +1,10 @@
 package handlers
 
 import (
+	"errors"
+	"math"
+	"strconv"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/satyam-svg/hr-message-backend/internals/models"
 	"github.com/satyam-svg/hr-message-backend/internals/services"
@@ -48,7 +52,7 @@ func (h *AuthHandler) Signup(c *fiber.Ctx) error {
 	}
 
 	// Call service
-	response, err := h.authService.Signup(c.Context(), req)
+	response, err := h.authService.Signup(c.Context(), req, clientInfo(c))
 	if err != nil {
 		if err.Error() == "user with this email already exists" {
 			return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
@@ -87,8 +91,15 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	}
 
 	// Call service
-	response, err := h.authService.Login(c.Context(), req)
+	response, challenge, err := h.authService.Login(c.Context(), req, clientInfo(c))
 	if err != nil {
+		var blocked *services.LoginBlockedError
+		if errors.As(err, &blocked) {
+			return loginBlocked(c, blocked)
+		}
+		if errors.Is(err, services.ErrAccountSuspended) {
+			return accountSuspended(c)
+		}
 		if err.Error() == "invalid email or password" {
 			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
 				Error:   "invalid_credentials",
@@ -101,9 +112,471 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		})
 	}
 
+	// The client continues with POST /api/auth/login/mfa
+	if challenge != nil {
+		return c.Status(fiber.StatusOK).JSON(challenge)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// LoginMFA handles the second login step for accounts with two-factor authentication
+// POST /api/auth/login/mfa
+func (h *AuthHandler) LoginMFA(c *fiber.Ctx) error {
+	var req models.LoginMFARequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	if req.MFAToken == "" || req.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "MFA token and code are required",
+		})
+	}
+
+	response, err := h.authService.CompleteMFALogin(c.Context(), req, clientInfo(c))
+	if err != nil {
+		var blocked *services.LoginBlockedError
+		if errors.As(err, &blocked) {
+			return loginBlocked(c, blocked)
+		}
+		switch {
+		case errors.Is(err, services.ErrInvalidAuthToken), errors.Is(err, services.ErrMFANotEnabled):
+			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+				Error:   "invalid_token",
+				Message: services.ErrInvalidAuthToken.Error(),
+			})
+		case errors.Is(err, services.ErrInvalidMFACode):
+			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+				Error:   "invalid_code",
+				Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrAccountSuspended):
+			return accountSuspended(c)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to authenticate user",
+		})
+	}
+
 	return c.Status(fiber.StatusOK).JSON(response)
 }
 
+// UnlockAccount handles lifting a login lockout with the emailed unlock token
+// POST /api/auth/unlock
+func (h *AuthHandler) UnlockAccount(c *fiber.Ctx) error {
+	var req models.UnlockAccountRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	if req.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Token is required",
+		})
+	}
+
+	if err := h.authService.UnlockAccount(c.Context(), req.Token); err != nil {
+		if errors.Is(err, services.ErrInvalidAuthToken) {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "invalid_token",
+				Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to unlock account",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Account unlocked successfully",
+	})
+}
+
+// Refresh handles exchanging a refresh token for a new token pair
+// POST /api/auth/refresh
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	var req models.RefreshTokenRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	if req.RefreshToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Refresh token is required",
+		})
+	}
+
+	tokens, err := h.authService.RefreshToken(c.Context(), req.RefreshToken, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrRefreshTokenReused) {
+			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+				Error:   "invalid_token",
+				Message: err.Error(),
+			})
+		}
+		if errors.Is(err, services.ErrAccountSuspended) {
+			return accountSuspended(c)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to refresh token",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(tokens)
+}
+
+// Logout handles revoking the current session
+// POST /api/auth/logout
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	// Get user and session ID from context (set by middleware)
+	userID := c.Locals("userId").(string)
+	sessionID := c.Locals("sessionId").(string)
+
+	if err := h.authService.Logout(c.Context(), userID, sessionID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to log out",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Logged out successfully",
+	})
+}
+
+// ChangePassword handles changing the password of the signed in user
+// PUT /api/auth/password
+func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
+	var req models.ChangePasswordRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Current password and new password are required",
+		})
+	}
+
+	// Validate password length
+	if len(req.NewPassword) < 8 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Password must be at least 8 characters long",
+		})
+	}
+
+	// Get user and session ID from context (set by middleware)
+	userID := c.Locals("userId").(string)
+	sessionID := c.Locals("sessionId").(string)
+
+	if err := h.authService.ChangePassword(c.Context(), userID, sessionID, req); err != nil {
+		if errors.Is(err, services.ErrIncorrectPassword) {
+			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+				Error:   "invalid_credentials",
+				Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to change password",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Password changed successfully",
+	})
+}
+
+// ChangeEmail handles changing the login email of the signed in user
+// PUT /api/auth/email
+func (h *AuthHandler) ChangeEmail(c *fiber.Ctx) error {
+	var req models.ChangeEmailRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	if req.CurrentPassword == "" || req.NewEmail == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Current password and new email are required",
+		})
+	}
+
+	// Get user and session ID from context (set by middleware)
+	userID := c.Locals("userId").(string)
+	sessionID := c.Locals("sessionId").(string)
+
+	user, err := h.authService.ChangeEmail(c.Context(), userID, sessionID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrIncorrectPassword):
+			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+				Error:   "invalid_credentials",
+				Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrSameEmail):
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "validation_error",
+				Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrEmailTaken):
+			return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+				Error:   "user_exists",
+				Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to change email",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
+// VerifyEmail handles confirming the account email with an emailed token
+// POST /api/auth/email/verify
+func (h *AuthHandler) VerifyEmail(c *fiber.Ctx) error {
+	var req models.VerifyEmailRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	if req.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Token is required",
+		})
+	}
+
+	if err := h.authService.VerifyEmail(c.Context(), req.Token); err != nil {
+		if errors.Is(err, services.ErrInvalidAuthToken) {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "invalid_token",
+				Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to verify email",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Email verified successfully",
+	})
+}
+
+// ResendVerification handles sending a new verification email
+// POST /api/auth/email/verify/resend
+func (h *AuthHandler) ResendVerification(c *fiber.Ctx) error {
+	// Get user ID from context (set by middleware)
+	userID := c.Locals("userId").(string)
+
+	wait, err := h.authService.ResendVerification(c.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrEmailAlreadyVerified):
+			return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+				Error:   "already_verified",
+				Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrVerificationRateLimited):
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			return c.Status(fiber.StatusTooManyRequests).JSON(models.ErrorResponse{
+				Error:   "rate_limited",
+				Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to send verification email",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Verification email sent",
+	})
+}
+
+// ForgotPassword handles requesting a password reset email
+// POST /api/auth/password/forgot
+func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req models.ForgotPasswordRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	if req.Email == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Email is required",
+		})
+	}
+
+	if err := h.authService.ForgotPassword(c.Context(), req.Email); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to send password reset email",
+		})
+	}
+
+	// Same response whether or not the account exists
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "If an account exists for this email, a password reset link has been sent",
+	})
+}
+
+// ResetPassword handles setting a new password with a reset token
+// POST /api/auth/password/reset
+func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
+	var req models.ResetPasswordRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	if req.Token == "" || req.Password == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Token and password are required",
+		})
+	}
+
+	// Validate password length
+	if len(req.Password) < 8 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Password must be at least 8 characters long",
+		})
+	}
+
+	if err := h.authService.ResetPassword(c.Context(), req.Token, req.Password); err != nil {
+		if errors.Is(err, services.ErrInvalidAuthToken) {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "invalid_token",
+				Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to reset password",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Password reset successfully. Please log in again.",
+	})
+}
+
+// ListSessions handles listing the user's active sessions
+// GET /api/auth/sessions
+func (h *AuthHandler) ListSessions(c *fiber.Ctx) error {
+	// Get user and session ID from context (set by middleware)
+	userID := c.Locals("userId").(string)
+	sessionID := c.Locals("sessionId").(string)
+
+	sessions, err := h.authService.ListSessions(c.Context(), userID, sessionID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to fetch sessions",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(sessions)
+}
+
+// RevokeSession handles logging out one of the user's sessions
+// DELETE /api/auth/sessions/:id
+func (h *AuthHandler) RevokeSession(c *fiber.Ctx) error {
+	// Get user ID from context (set by middleware)
+	userID := c.Locals("userId").(string)
+
+	if err := h.authService.RevokeSession(c.Context(), userID, c.Params("id")); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+				Error:   "not_found",
+				Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to revoke session",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Session revoked successfully",
+	})
+}
+
+// RevokeOtherSessions handles logging out everywhere except the current session
+// DELETE /api/auth/sessions
+func (h *AuthHandler) RevokeOtherSessions(c *fiber.Ctx) error {
+	// Get user and session ID from context (set by middleware)
+	userID := c.Locals("userId").(string)
+	sessionID := c.Locals("sessionId").(string)
+
+	result, err := h.authService.RevokeOtherSessions(c.Context(), userID, sessionID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to revoke sessions",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
 // UpdateEmailSettings handles updating professional email and mail app password
 // PUT /api/auth/email-settings
 func (h *AuthHandler) UpdateEmailSettings(c *fiber.Ctx) error {
@@ -141,14 +614,50 @@ func (h *AuthHandler) UpdateEmailSettings(c *fiber.Ctx) error {
 	})
 }
 
+// UpdateTimezone handles changing the user's time zone
+// PUT /api/auth/timezone
+func (h *AuthHandler) UpdateTimezone(c *fiber.Ctx) error {
+	var req models.UpdateTimezoneRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	userID := c.Locals("userId").(string)
+
+	if err := h.authService.UpdateTimezone(c.Context(), userID, req); err != nil {
+		if errors.Is(err, services.ErrInvalidTimezone) {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "validation_error",
+				Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to update time zone",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Time zone updated successfully",
+	})
+}
+
 // GetProfile handles fetching the authenticated user's profile
 // GET /api/auth/me
 func (h *AuthHandler) GetProfile(c *fiber.Ctx) error {
 	// Get user ID from context (set by middleware)
 	userID := c.Locals("userId").(string)
 
-	profile, err := h.authService.GetProfile(c.Context(), userID)
+	profile, err := h.authService.GetProfile(c.Context(), userID, organizationID(c))
 	if err != nil {
+		if isOrganizationError(err) {
+			return organizationError(c, err, "Failed to fetch user profile")
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
 			Error:   "server_error",
 			Message: "Failed to fetch user profile- " + err.Error(),
@@ -157,3 +666,36 @@ func (h *AuthHandler) GetProfile(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(profile)
 }
+
+// loginBlocked responds to a login refused because of earlier failed attempts
+func loginBlocked(c *fiber.Ctx, blocked *services.LoginBlockedError) error {
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(blocked.RetryAfter.Seconds()))))
+
+	if errors.Is(blocked, services.ErrAccountLocked) {
+		return c.Status(fiber.StatusLocked).JSON(models.ErrorResponse{
+			Error:   "account_locked",
+			Message: blocked.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusTooManyRequests).JSON(models.ErrorResponse{
+		Error:   "too_many_attempts",
+		Message: blocked.Error(),
+	})
+}
+
+// accountSuspended responds to a login or refresh by a suspended account
+func accountSuspended(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+		Error:   "account_suspended",
+		Message: services.ErrAccountSuspended.Error(),
+	})
+}
+
+// clientInfo captures the device details stored with a session
+func clientInfo(c *fiber.Ctx) models.ClientInfo {
+	return models.ClientInfo{
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IPAddress: c.IP(),
+	}
+}
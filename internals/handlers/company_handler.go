@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/internals/services"
+)
+
+// CompanyHandler handles company HTTP requests
+type CompanyHandler struct {
+	companyService *services.CompanyService
+}
+
+// NewCompanyHandler creates a new company handler
+func NewCompanyHandler(companyService *services.CompanyService) *CompanyHandler {
+	return &CompanyHandler{
+		companyService: companyService,
+	}
+}
+
+// ListCompanies handles listing the organization's companies
+// GET /api/companies
+func (h *CompanyHandler) ListCompanies(c *fiber.Ctx) error {
+	userId := c.Locals("userId").(string)
+
+	companies, err := h.companyService.ListCompanies(c.Context(), userId, organizationID(c))
+	if err != nil {
+		return companyError(c, err, "Failed to fetch companies")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(companies)
+}
+
+// GetCompany handles fetching a company with its contacts
+// GET /api/companies/:id
+func (h *CompanyHandler) GetCompany(c *fiber.Ctx) error {
+	userId := c.Locals("userId").(string)
+
+	company, err := h.companyService.GetCompany(c.Context(), userId, organizationID(c), c.Params("id"))
+	if err != nil {
+		return companyError(c, err, "Failed to fetch company")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(company)
+}
+
+// UpdateCompany handles updating a company
+// PATCH /api/companies/:id
+func (h *CompanyHandler) UpdateCompany(c *fiber.Ctx) error {
+	var req models.UpdateCompanyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	userId := c.Locals("userId").(string)
+
+	company, err := h.companyService.UpdateCompany(c.Context(), userId, organizationID(c), c.Params("id"), req)
+	if err != nil {
+		return companyError(c, err, "Failed to update company")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(company)
+}
+
+// RematchContacts handles linking contacts without a company to a matching company
+// POST /api/companies/rematch
+func (h *CompanyHandler) RematchContacts(c *fiber.Ctx) error {
+	userId := c.Locals("userId").(string)
+
+	result, err := h.companyService.RematchContacts(c.Context(), userId, organizationID(c))
+	if err != nil {
+		return companyError(c, err, "Failed to match contacts to companies")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
+// companyError maps company and organization errors to responses, falling
+// back to a 500 with the given message
+func companyError(c *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case isOrganizationError(err):
+		return organizationError(c, err, fallback)
+	case errors.Is(err, services.ErrCompanyNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "not_found",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrCompanyExists):
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Error:   "conflict",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+		Error:   "server_error",
+		Message: fallback,
+	})
+}
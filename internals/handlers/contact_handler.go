@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/satyam-svg/hr-message-backend/internals/models"
 	"github.com/satyam-svg/hr-message-backend/internals/services"
@@ -31,8 +33,136 @@ func (h *ContactHandler) UpdateContact(c *fiber.Ctx) error {
 
 	updatedContact, err := h.service.UpdateContact(c.Context(), contactId, userId, req)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidContactStatus) || errors.Is(err, services.ErrInvalidTimezone) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	return c.JSON(updatedContact)
 }
+
+// DeleteContact handles moving a contact to the trash
+// DELETE /api/contacts/:id
+func (h *ContactHandler) DeleteContact(c *fiber.Ctx) error {
+	contactId := c.Params("id")
+	userId := c.Locals("userId").(string)
+
+	if err := h.service.DeleteContact(c.Context(), contactId, userId); err != nil {
+		if errors.Is(err, services.ErrContactNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, services.ErrInsufficientRole) {
+			return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Contact moved to trash"})
+}
+
+// AddNote handles attaching a note to a contact
+// POST /api/contacts/:id/notes
+func (h *ContactHandler) AddNote(c *fiber.Ctx) error {
+	contactId := c.Params("id")
+
+	var req models.CreateContactNoteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	if req.Body == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "note body is required"})
+	}
+
+	userId := c.Locals("userId").(string)
+
+	note, err := h.service.AddNote(c.Context(), contactId, userId, req)
+	if err != nil {
+		if errors.Is(err, services.ErrContactNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(note)
+}
+
+// ListNotes handles listing the notes of a contact
+// GET /api/contacts/:id/notes
+func (h *ContactHandler) ListNotes(c *fiber.Ctx) error {
+	contactId := c.Params("id")
+	userId := c.Locals("userId").(string)
+
+	notes, err := h.service.ListNotes(c.Context(), contactId, userId)
+	if err != nil {
+		if errors.Is(err, services.ErrContactNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(notes)
+}
+
+// DeleteNote handles removing a note from a contact
+// DELETE /api/contacts/:id/notes/:noteId
+func (h *ContactHandler) DeleteNote(c *fiber.Ctx) error {
+	contactId := c.Params("id")
+	noteId := c.Params("noteId")
+	userId := c.Locals("userId").(string)
+
+	if err := h.service.DeleteNote(c.Context(), contactId, noteId, userId); err != nil {
+		if errors.Is(err, services.ErrContactNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "note not found or unauthorized"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Note deleted successfully"})
+}
+
+// GetTimeline handles fetching the chronological activity timeline of a contact
+// GET /api/contacts/:id/timeline
+func (h *ContactHandler) GetTimeline(c *fiber.Ctx) error {
+	contactId := c.Params("id")
+	userId := c.Locals("userId").(string)
+
+	timeline, err := h.service.GetTimeline(c.Context(), contactId, userId)
+	if err != nil {
+		if errors.Is(err, services.ErrContactNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(timeline)
+}
+
+// BulkUpdate handles applying one action to many contacts at once
+// POST /api/contacts/bulk
+func (h *ContactHandler) BulkUpdate(c *fiber.Ctx) error {
+	var req models.BulkContactRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	userId := c.Locals("userId").(string)
+
+	result, err := h.service.BulkUpdate(c.Context(), userId, organizationID(c), req)
+	if err != nil {
+		if errors.Is(err, services.ErrOrganizationNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, services.ErrInvalidBulkRequest) || errors.Is(err, services.ErrInvalidContactStatus) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if result.RolledBack {
+		return c.Status(500).JSON(result)
+	}
+
+	return c.JSON(result)
+}
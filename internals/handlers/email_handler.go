@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"os"
 
 	"github.com/gofiber/fiber/v2"
@@ -25,16 +26,67 @@ func NewEmailHandler(emailService *services.EmailService) *EmailHandler {
 func (h *EmailHandler) StartCampaign(c *fiber.Ctx) error {
 	userId := c.Locals("userId").(string)
 
-	if err := h.emailService.StartEmailCampaign(userId); err != nil {
+	// The body is optional; an empty request starts a campaign without limits
+	var req models.StartCampaignRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Invalid request body",
+			})
+		}
+	}
+
+	campaign, err := h.emailService.StartEmailCampaign(c.Context(), userId, organizationID(c), req)
+	if err != nil {
+		if isOrganizationError(err) {
+			return organizationError(c, err, "Failed to start campaign")
+		}
+		if errors.Is(err, services.ErrEmailNotVerified) {
+			return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+				Error:   "email_not_verified",
+				Message: err.Error(),
+			})
+		}
+		if errors.Is(err, services.ErrInvalidCompanyLimit) ||
+			errors.Is(err, services.ErrInvalidSenderRotation) ||
+			errors.Is(err, services.ErrInvalidSendWindow) ||
+			errors.Is(err, services.ErrInvalidTimezone) ||
+			errors.Is(err, services.ErrInvalidPacing) {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "validation_error",
+				Message: err.Error(),
+			})
+		}
+		if errors.Is(err, services.ErrSenderAccountNotFound) ||
+			errors.Is(err, services.ErrSequenceNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+				Error:   "not_found",
+				Message: err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
 			Error:   "campaign_error",
 			Message: "Failed to start campaign: " + err.Error(),
 		})
 	}
 
+	if campaign == nil {
+		return c.JSON(fiber.Map{
+			"message": "No unsent contacts to email",
+			"success": true,
+		})
+	}
+
+	message := "Email campaign started in background"
+	if campaign.Status == "scheduled" {
+		message = "Email campaign scheduled"
+	}
+
 	return c.JSON(fiber.Map{
-		"message": "Email campaign started in background",
-		"success": true,
+		"message":  message,
+		"success":  true,
+		"campaign": campaign,
 	})
 }
 
@@ -118,7 +170,22 @@ func (h *EmailHandler) SendEmail(c *fiber.Ctx) error {
 
 	// Call service to send email synchronously (Foreground)
 	// This helps catch errors immediately and prevents Render from killing background goroutines
-	if err := h.emailService.SendEmailForUser(userId, req); err != nil {
+	if err := h.emailService.SendEmailForUser(c.Context(), userId, organizationID(c), req); err != nil {
+		if isOrganizationError(err) {
+			return organizationError(c, err, "Failed to send email")
+		}
+		if errors.Is(err, services.ErrEmailNotVerified) {
+			return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+				Error:   "email_not_verified",
+				Message: err.Error(),
+			})
+		}
+		if errors.Is(err, services.ErrDailyLimitReached) {
+			return c.Status(fiber.StatusTooManyRequests).JSON(models.ErrorResponse{
+				Error:   "daily_limit_reached",
+				Message: err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
 			Error:   "email_send_error",
 			Message: "Failed to send email: " + err.Error(),
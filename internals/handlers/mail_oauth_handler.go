@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/internals/services"
+)
+
+// MailOAuthHandler handles connecting a Gmail account for sending
+type MailOAuthHandler struct {
+	mailOAuthService *services.MailOAuthService
+}
+
+// NewMailOAuthHandler creates a new Gmail connection handler
+func NewMailOAuthHandler(mailOAuthService *services.MailOAuthService) *MailOAuthHandler {
+	return &MailOAuthHandler{
+		mailOAuthService: mailOAuthService,
+	}
+}
+
+// GetStatus handles fetching the connected Gmail account
+// GET /api/email/oauth/google
+func (h *MailOAuthHandler) GetStatus(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	response, err := h.mailOAuthService.Status(c.Context(), userID)
+	if err != nil {
+		return mailOAuthError(c, err, "Failed to fetch Gmail connection")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// Connect handles starting to connect a Gmail account
+// POST /api/email/oauth/google/connect
+func (h *MailOAuthHandler) Connect(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	response, err := h.mailOAuthService.Connect(c.Context(), userID)
+	if err != nil {
+		return mailOAuthError(c, err, "Failed to start connecting Gmail")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// Callback handles finishing a Gmail connection with the code and state
+// Google redirected back with
+// POST /api/email/oauth/google/callback
+func (h *MailOAuthHandler) Callback(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	var req models.MailConnectCallbackRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	response, err := h.mailOAuthService.Callback(c.Context(), userID, req)
+	if err != nil {
+		return mailOAuthError(c, err, "Failed to connect Gmail")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// Disconnect handles removing the connected Gmail account. Emails are sent
+// with the app password again afterwards, if one is saved.
+// DELETE /api/email/oauth/google
+func (h *MailOAuthHandler) Disconnect(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	if err := h.mailOAuthService.Disconnect(c.Context(), userID); err != nil {
+		return mailOAuthError(c, err, "Failed to disconnect Gmail")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Gmail account disconnected",
+	})
+}
+
+// mailOAuthError maps Gmail connection errors to HTTP responses
+func mailOAuthError(c *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, services.ErrMailOAuthNotConfigured):
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "mail_oauth_not_configured",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrMailAccountNotConnected):
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "not_connected",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidMailOAuthState):
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_state",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrMailOAuthFailed):
+		return c.Status(fiber.StatusBadGateway).JSON(models.ErrorResponse{
+			Error:   "mail_oauth_failed",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+		Error:   "server_error",
+		Message: fallback,
+	})
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/internals/services"
+)
+
+// MFAHandler handles two-factor authentication HTTP requests
+type MFAHandler struct {
+	mfaService *services.MFAService
+}
+
+// NewMFAHandler creates a new MFA handler
+func NewMFAHandler(mfaService *services.MFAService) *MFAHandler {
+	return &MFAHandler{
+		mfaService: mfaService,
+	}
+}
+
+// GetStatus handles reporting whether two-factor authentication is enabled
+// GET /api/auth/mfa
+func (h *MFAHandler) GetStatus(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	status, err := h.mfaService.GetStatus(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to fetch two-factor status",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(status)
+}
+
+// SetupTOTP handles starting TOTP enrollment
+// POST /api/auth/mfa/totp/setup
+func (h *MFAHandler) SetupTOTP(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	setup, err := h.mfaService.Setup(c.Context(), userID)
+	if err != nil {
+		return mfaError(c, err, "Failed to start two-factor setup")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(setup)
+}
+
+// ConfirmTOTP handles finishing TOTP enrollment with a code from the authenticator app
+// POST /api/auth/mfa/totp/confirm
+func (h *MFAHandler) ConfirmTOTP(c *fiber.Ctx) error {
+	var req models.ConfirmTOTPRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	if req.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Code is required",
+		})
+	}
+
+	userID := c.Locals("userId").(string)
+
+	codes, err := h.mfaService.Confirm(c.Context(), userID, req.Code)
+	if err != nil {
+		return mfaError(c, err, "Failed to enable two-factor authentication")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(codes)
+}
+
+// Disable handles turning off two-factor authentication
+// POST /api/auth/mfa/disable
+func (h *MFAHandler) Disable(c *fiber.Ctx) error {
+	req, ok := parseMFAVerification(c)
+	if !ok {
+		return nil
+	}
+
+	userID := c.Locals("userId").(string)
+
+	if err := h.mfaService.Disable(c.Context(), userID, req); err != nil {
+		return mfaError(c, err, "Failed to disable two-factor authentication")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+// RegenerateRecoveryCodes handles replacing the user's recovery codes
+// POST /api/auth/mfa/recovery-codes
+func (h *MFAHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	req, ok := parseMFAVerification(c)
+	if !ok {
+		return nil
+	}
+
+	userID := c.Locals("userId").(string)
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(c.Context(), userID, req)
+	if err != nil {
+		return mfaError(c, err, "Failed to regenerate recovery codes")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(codes)
+}
+
+// parseMFAVerification parses and validates a password plus code request.
+// It writes the error response itself and reports false when the request is invalid.
+func parseMFAVerification(c *fiber.Ctx) (models.MFAVerificationRequest, bool) {
+	var req models.MFAVerificationRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+		return req, false
+	}
+
+	if req.Password == "" || req.Code == "" {
+		c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Password and code are required",
+		})
+		return req, false
+	}
+
+	return req, true
+}
+
+// mfaError maps MFA service errors to HTTP responses
+func mfaError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, services.ErrMFAAlreadyEnabled), errors.Is(err, services.ErrMFANotEnabled):
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Error:   "mfa_state_conflict",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrMFASetupRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "mfa_setup_required",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrIncorrectPassword):
+		return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error:   "invalid_credentials",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidMFACode):
+		return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+			Error:   "invalid_code",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+		Error:   "server_error",
+		Message: message,
+	})
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/internals/services"
+)
+
+// OIDCHandler handles single sign-on HTTP requests
+type OIDCHandler struct {
+	oidcService *services.OIDCService
+}
+
+// NewOIDCHandler creates a new single sign-on handler
+func NewOIDCHandler(oidcService *services.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+	}
+}
+
+// Authorize handles starting a single sign-on login
+// POST /api/auth/sso/authorize
+func (h *OIDCHandler) Authorize(c *fiber.Ctx) error {
+	response, err := h.oidcService.Authorize(c.Context())
+	if err != nil {
+		if errors.Is(err, services.ErrOIDCNotConfigured) {
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+				Error:   "sso_not_configured",
+				Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusBadGateway).JSON(models.ErrorResponse{
+			Error:   "sso_unavailable",
+			Message: "Failed to reach the identity provider",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// Callback handles finishing a single sign-on login with the code and state
+// the identity provider redirected back with
+// POST /api/auth/sso/callback
+func (h *OIDCHandler) Callback(c *fiber.Ctx) error {
+	var req models.OIDCCallbackRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	response, challenge, err := h.oidcService.Callback(c.Context(), req, clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrOIDCNotConfigured):
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+				Error:   "sso_not_configured",
+				Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrInvalidOIDCState):
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "invalid_state",
+				Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrOIDCLoginFailed):
+			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+				Error:   "sso_failed",
+				Message: services.ErrOIDCLoginFailed.Error(),
+			})
+		case errors.Is(err, services.ErrOIDCEmailNotVerified):
+			return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+				Error:   "email_not_verified",
+				Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrAccountSuspended):
+			return accountSuspended(c)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to authenticate user",
+		})
+	}
+
+	// The client continues with POST /api/auth/login/mfa
+	if challenge != nil {
+		return c.Status(fiber.StatusOK).JSON(challenge)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/internals/services"
+)
+
+// OrganizationHandler handles organization, member and invitation HTTP requests
+type OrganizationHandler struct {
+	organizationService *services.OrganizationService
+}
+
+// NewOrganizationHandler creates a new organization handler
+func NewOrganizationHandler(organizationService *services.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationService: organizationService,
+	}
+}
+
+// ListOrganizations handles listing the organizations the user belongs to
+// GET /api/organizations
+func (h *OrganizationHandler) ListOrganizations(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	organizations, err := h.organizationService.ListOrganizations(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to fetch organizations",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(organizations)
+}
+
+// CreateOrganization handles creating a team organization
+// POST /api/organizations
+func (h *OrganizationHandler) CreateOrganization(c *fiber.Ctx) error {
+	var req models.CreateOrganizationRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	userID := c.Locals("userId").(string)
+
+	organization, err := h.organizationService.CreateOrganization(c.Context(), userID, req)
+	if err != nil {
+		return organizationError(c, err, "Failed to create organization")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(organization)
+}
+
+// GetOrganization handles fetching an organization and its members
+// GET /api/organizations/:id
+func (h *OrganizationHandler) GetOrganization(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	organization, err := h.organizationService.GetOrganization(c.Context(), userID, c.Params("id"))
+	if err != nil {
+		return organizationError(c, err, "Failed to fetch organization")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(organization)
+}
+
+// UpdateOrganization handles renaming an organization
+// PATCH /api/organizations/:id
+func (h *OrganizationHandler) UpdateOrganization(c *fiber.Ctx) error {
+	var req models.UpdateOrganizationRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	userID := c.Locals("userId").(string)
+
+	organization, err := h.organizationService.UpdateOrganization(c.Context(), userID, c.Params("id"), req)
+	if err != nil {
+		return organizationError(c, err, "Failed to update organization")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(organization)
+}
+
+// UpdateMember handles changing a member's role
+// PATCH /api/organizations/:id/members/:userId
+func (h *OrganizationHandler) UpdateMember(c *fiber.Ctx) error {
+	var req models.UpdateMemberRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	userID := c.Locals("userId").(string)
+
+	if err := h.organizationService.UpdateMember(c.Context(), userID, c.Params("id"), c.Params("userId"), req); err != nil {
+		return organizationError(c, err, "Failed to update member")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Member updated successfully",
+	})
+}
+
+// RemoveMember handles removing a member, or leaving when the user removes themselves
+// DELETE /api/organizations/:id/members/:userId
+func (h *OrganizationHandler) RemoveMember(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	if err := h.organizationService.RemoveMember(c.Context(), userID, c.Params("id"), c.Params("userId")); err != nil {
+		return organizationError(c, err, "Failed to remove member")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Member removed successfully",
+	})
+}
+
+// InviteMember handles emailing an invitation to join an organization
+// POST /api/organizations/:id/invitations
+func (h *OrganizationHandler) InviteMember(c *fiber.Ctx) error {
+	var req models.InviteMemberRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	userID := c.Locals("userId").(string)
+
+	invitation, err := h.organizationService.InviteMember(c.Context(), userID, c.Params("id"), req)
+	if err != nil {
+		return organizationError(c, err, "Failed to send invitation")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(invitation)
+}
+
+// ListInvitations handles listing an organization's pending invitations
+// GET /api/organizations/:id/invitations
+func (h *OrganizationHandler) ListInvitations(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	invitations, err := h.organizationService.ListInvitations(c.Context(), userID, c.Params("id"))
+	if err != nil {
+		return organizationError(c, err, "Failed to fetch invitations")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(invitations)
+}
+
+// RevokeInvitation handles cancelling a pending invitation
+// DELETE /api/organizations/:id/invitations/:invitationId
+func (h *OrganizationHandler) RevokeInvitation(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	if err := h.organizationService.RevokeInvitation(c.Context(), userID, c.Params("id"), c.Params("invitationId")); err != nil {
+		return organizationError(c, err, "Failed to revoke invitation")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Invitation revoked successfully",
+	})
+}
+
+// AcceptInvitation handles joining an organization with an invitation token
+// POST /api/organizations/invitations/accept
+func (h *OrganizationHandler) AcceptInvitation(c *fiber.Ctx) error {
+	var req models.AcceptInvitationRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil || req.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invitation token is required",
+		})
+	}
+
+	userID := c.Locals("userId").(string)
+
+	organization, err := h.organizationService.AcceptInvitation(c.Context(), userID, req.Token)
+	if err != nil {
+		return organizationError(c, err, "Failed to accept invitation")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(organization)
+}
+
+// organizationID returns the organization selected with the X-Organization-Id
+// header; empty means the user's personal organization
+func organizationID(c *fiber.Ctx) string {
+	return c.Get(models.OrganizationHeader)
+}
+
+// isOrganizationError reports whether err comes from resolving the
+// organization or checking the user's role in it
+func isOrganizationError(err error) bool {
+	return errors.Is(err, services.ErrOrganizationNotFound) || errors.Is(err, services.ErrInsufficientRole)
+}
+
+// organizationError maps organization, membership and invitation errors to
+// responses, falling back to a 500 with the given message
+func organizationError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, services.ErrOrganizationNotFound),
+		errors.Is(err, services.ErrMemberNotFound),
+		errors.Is(err, services.ErrInvitationNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "not_found",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrInsufficientRole),
+		errors.Is(err, services.ErrInvitationEmailMismatch):
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Error:   "forbidden",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidOrganizationRequest):
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrPersonalOrganization),
+		errors.Is(err, services.ErrLastOwner),
+		errors.Is(err, services.ErrAlreadyMember):
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Error:   "conflict",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+		Error:   "server_error",
+		Message: message,
+	})
+}
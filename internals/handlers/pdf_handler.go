@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -16,15 +17,19 @@ import (
 
 // PDFHandler handles PDF HTTP requests
 type PDFHandler struct {
-	client      *db.PrismaClient
-	userService *services.UserService
+	client              *db.PrismaClient
+	userService         *services.UserService
+	companyService      *services.CompanyService
+	organizationService *services.OrganizationService
 }
 
 // NewPDFHandler creates a new PDF handler
-func NewPDFHandler(client *db.PrismaClient, userService *services.UserService) *PDFHandler {
+func NewPDFHandler(client *db.PrismaClient, userService *services.UserService, companyService *services.CompanyService, organizationService *services.OrganizationService) *PDFHandler {
 	return &PDFHandler{
-		client:      client,
-		userService: userService,
+		client:              client,
+		userService:         userService,
+		companyService:      companyService,
+		organizationService: organizationService,
 	}
 }
 
@@ -41,6 +46,15 @@ func (h *PDFHandler) UploadPDF(c *fiber.Ctx) error {
 	// Get user ID from context (set by middleware)
 	userID := c.Locals("userId").(string)
 
+	// Contacts are imported into the selected organization
+	orgID, err := h.organizationService.ResolveOrganization(c.Context(), userID, organizationID(c))
+	if err != nil {
+		if errors.Is(err, services.ErrOrganizationNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "failed to resolve organization"})
+	}
+
 	// Ensure uploads directory exists
 	if err := os.MkdirAll("./uploads", os.ModePerm); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "failed to create uploads directory"})
@@ -135,11 +149,23 @@ func (h *PDFHandler) UploadPDF(c *fiber.Ctx) error {
 	// Save contacts to database
 	savedContacts := []map[string]interface{}{}
 	for _, company := range result.Companies {
+		var optional []db.ContactSetParam
+
+		// Group contacts of the same firm under one company
+		matched, err := h.companyService.MatchOrCreate(ctx, orgID, company.CompanyName, company.Email)
+		if err != nil {
+			log.Printf("Failed to match company for %s: %v", company.Email, err)
+		} else if matched != nil {
+			optional = append(optional, db.Contact.Company.Link(db.Company.ID.Equals(matched.ID)))
+		}
+
 		contact, err := h.client.Contact.CreateOne(
 			db.Contact.Name.Set(company.Name),
 			db.Contact.CompanyName.Set(company.CompanyName),
 			db.Contact.Email.Set(company.Email),
 			db.Contact.User.Link(db.User.ID.Equals(userID)),
+			db.Contact.Organization.Link(db.Organization.ID.Equals(orgID)),
+			optional...,
 		).Exec(ctx)
 
 		if err != nil {
@@ -147,17 +173,19 @@ func (h *PDFHandler) UploadPDF(c *fiber.Ctx) error {
 			continue
 		}
 
+		companyID, _ := contact.CompanyID()
 		savedContacts = append(savedContacts, map[string]interface{}{
 			"id":           contact.ID,
 			"name":         contact.Name,
 			"company_name": contact.CompanyName,
+			"company_id":   companyID,
 			"email":        contact.Email,
 			"is_sent":      contact.IsSent,
 		})
 	}
 
 	// Increment PDF upload count
-	if err := h.userService.IncrementPDFUploadCount(userID); err != nil {
+	if err := h.userService.IncrementPDFUploadCount(c.Context(), userID, len(savedContacts)); err != nil {
 		log.Printf("Failed to update user stats: %v", err)
 		// Don't fail the request, just log
 	}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/internals/services"
+)
+
+// SenderAccountHandler handles sender account HTTP requests
+type SenderAccountHandler struct {
+	senderAccountService *services.SenderAccountService
+	replySyncService     *services.ReplySyncService
+}
+
+// NewSenderAccountHandler creates a new sender account handler
+func NewSenderAccountHandler(senderAccountService *services.SenderAccountService, replySyncService *services.ReplySyncService) *SenderAccountHandler {
+	return &SenderAccountHandler{
+		senderAccountService: senderAccountService,
+		replySyncService:     replySyncService,
+	}
+}
+
+// CreateSenderAccount handles adding a mailbox to send from
+// POST /api/sender-accounts
+func (h *SenderAccountHandler) CreateSenderAccount(c *fiber.Ctx) error {
+	var req models.CreateSenderAccountRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	userID := c.Locals("userId").(string)
+
+	account, err := h.senderAccountService.CreateSenderAccount(c.Context(), userID, req)
+	if err != nil {
+		return senderAccountError(c, err, "Failed to create sender account")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(account)
+}
+
+// ListSenderAccounts handles listing the user's sender accounts
+// GET /api/sender-accounts
+func (h *SenderAccountHandler) ListSenderAccounts(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	accounts, err := h.senderAccountService.ListSenderAccounts(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to fetch sender accounts",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(accounts)
+}
+
+// UpdateSenderAccount handles changing a sender account
+// PATCH /api/sender-accounts/:id
+func (h *SenderAccountHandler) UpdateSenderAccount(c *fiber.Ctx) error {
+	var req models.UpdateSenderAccountRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	userID := c.Locals("userId").(string)
+
+	account, err := h.senderAccountService.UpdateSenderAccount(c.Context(), userID, c.Params("id"), req)
+	if err != nil {
+		return senderAccountError(c, err, "Failed to update sender account")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(account)
+}
+
+// DeleteSenderAccount handles removing a sender account
+// DELETE /api/sender-accounts/:id
+func (h *SenderAccountHandler) DeleteSenderAccount(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	if err := h.senderAccountService.DeleteSenderAccount(c.Context(), userID, c.Params("id")); err != nil {
+		return senderAccountError(c, err, "Failed to delete sender account")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Sender account deleted",
+	})
+}
+
+// SyncSenderAccount handles checking a sender account's inbox for replies right away
+// POST /api/sender-accounts/:id/sync
+func (h *SenderAccountHandler) SyncSenderAccount(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	result, err := h.replySyncService.SyncSenderAccount(c.Context(), userID, c.Params("id"))
+	if err != nil {
+		return senderAccountError(c, err, "Failed to check inbox")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
+// senderAccountError maps sender account errors to HTTP responses
+func senderAccountError(c *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, services.ErrSenderAccountNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "not_found",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidSenderAccount):
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrSenderAccountExists):
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Error:   "conflict",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrTooManySenderAccounts):
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Error:   "limit_reached",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrReplySyncDisabled):
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrReplySyncInProgress):
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Error:   "conflict",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrIMAPSyncFailed):
+		return c.Status(fiber.StatusBadGateway).JSON(models.ErrorResponse{
+			Error:   "imap_sync_failed",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+		Error:   "server_error",
+		Message: fallback,
+	})
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/internals/services"
+)
+
+// SequenceHandler handles follow-up sequence HTTP requests
+type SequenceHandler struct {
+	sequenceService *services.SequenceService
+}
+
+// NewSequenceHandler creates a new sequence handler
+func NewSequenceHandler(sequenceService *services.SequenceService) *SequenceHandler {
+	return &SequenceHandler{
+		sequenceService: sequenceService,
+	}
+}
+
+// CreateSequence handles adding a follow-up sequence to the organization
+// POST /api/sequences
+func (h *SequenceHandler) CreateSequence(c *fiber.Ctx) error {
+	var req models.SaveSequenceRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	userID := c.Locals("userId").(string)
+
+	sequence, err := h.sequenceService.CreateSequence(c.Context(), userID, organizationID(c), req)
+	if err != nil {
+		return sequenceError(c, err, "Failed to create sequence")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(sequence)
+}
+
+// ListSequences handles listing the organization's sequences
+// GET /api/sequences
+func (h *SequenceHandler) ListSequences(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	sequences, err := h.sequenceService.ListSequences(c.Context(), userID, organizationID(c))
+	if err != nil {
+		return sequenceError(c, err, "Failed to fetch sequences")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(sequences)
+}
+
+// GetSequence handles fetching one sequence
+// GET /api/sequences/:id
+func (h *SequenceHandler) GetSequence(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	sequence, err := h.sequenceService.GetSequence(c.Context(), userID, organizationID(c), c.Params("id"))
+	if err != nil {
+		return sequenceError(c, err, "Failed to fetch sequence")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(sequence)
+}
+
+// UpdateSequence handles renaming a sequence and replacing its steps
+// PUT /api/sequences/:id
+func (h *SequenceHandler) UpdateSequence(c *fiber.Ctx) error {
+	var req models.SaveSequenceRequest
+
+	// Parse request body
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request body",
+		})
+	}
+
+	userID := c.Locals("userId").(string)
+
+	sequence, err := h.sequenceService.UpdateSequence(c.Context(), userID, organizationID(c), c.Params("id"), req)
+	if err != nil {
+		return sequenceError(c, err, "Failed to update sequence")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(sequence)
+}
+
+// DeleteSequence handles deleting a sequence and its pending follow-ups
+// DELETE /api/sequences/:id
+func (h *SequenceHandler) DeleteSequence(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	if err := h.sequenceService.DeleteSequence(c.Context(), userID, organizationID(c), c.Params("id")); err != nil {
+		return sequenceError(c, err, "Failed to delete sequence")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Sequence deleted",
+	})
+}
+
+// ListEnrollments handles listing the contacts enrolled in a sequence
+// GET /api/sequences/:id/enrollments
+func (h *SequenceHandler) ListEnrollments(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	enrollments, err := h.sequenceService.ListEnrollments(c.Context(), userID, organizationID(c), c.Params("id"))
+	if err != nil {
+		return sequenceError(c, err, "Failed to fetch enrollments")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(enrollments)
+}
+
+// StopEnrollment handles stopping the follow-ups of one contact
+// POST /api/sequences/:id/enrollments/:enrollmentId/stop
+func (h *SequenceHandler) StopEnrollment(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	enrollment, err := h.sequenceService.StopEnrollment(c.Context(), userID, organizationID(c), c.Params("id"), c.Params("enrollmentId"))
+	if err != nil {
+		return sequenceError(c, err, "Failed to stop follow-ups")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(enrollment)
+}
+
+// sequenceError maps sequence errors to HTTP responses
+func sequenceError(c *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case isOrganizationError(err):
+		return organizationError(c, err, fallback)
+	case errors.Is(err, services.ErrSequenceNotFound),
+		errors.Is(err, services.ErrEnrollmentNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "not_found",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidSequence):
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+		Error:   "server_error",
+		Message: fallback,
+	})
+}
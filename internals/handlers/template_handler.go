@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -26,14 +27,17 @@ func NewTemplateHandler(templateService *services.TemplateService) *TemplateHand
 	}
 }
 
-// GetTemplate handles fetching the user's template
+// GetTemplate handles fetching the organization's template
 // GET /api/template
 func (h *TemplateHandler) GetTemplate(c *fiber.Ctx) error {
 	// Get user ID from context (set by middleware)
 	userID := c.Locals("userId").(string)
 
-	template, err := h.templateService.GetTemplate(c.Context(), userID)
+	template, err := h.templateService.GetTemplate(c.Context(), userID, organizationID(c))
 	if err != nil {
+		if isOrganizationError(err) {
+			return organizationError(c, err, "Failed to fetch template")
+		}
 		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
 			Error:   "not_found",
 			Message: "Template not found",
@@ -43,7 +47,7 @@ func (h *TemplateHandler) GetTemplate(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(template)
 }
 
-// UpdateTemplate handles updating the user's template
+// UpdateTemplate handles updating the organization's template
 // PUT /api/template
 func (h *TemplateHandler) UpdateTemplate(c *fiber.Ctx) error {
 	var req models.UpdateTemplateRequest
@@ -67,8 +71,11 @@ func (h *TemplateHandler) UpdateTemplate(c *fiber.Ctx) error {
 	// Get user ID from context (set by middleware)
 	userID := c.Locals("userId").(string)
 
-	template, err := h.templateService.UpdateTemplate(c.Context(), userID, req)
+	template, err := h.templateService.UpdateTemplate(c.Context(), userID, organizationID(c), req)
 	if err != nil {
+		if isOrganizationError(err) {
+			return organizationError(c, err, "Failed to update template")
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
 			Error:   "server_error",
 			Message: "Failed to update template",
@@ -78,6 +85,33 @@ func (h *TemplateHandler) UpdateTemplate(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(template)
 }
 
+// DeleteTemplate handles moving the organization's template to the trash
+// DELETE /api/template
+func (h *TemplateHandler) DeleteTemplate(c *fiber.Ctx) error {
+	// Get user ID from context (set by middleware)
+	userID := c.Locals("userId").(string)
+
+	if err := h.templateService.DeleteTemplate(c.Context(), userID, organizationID(c)); err != nil {
+		if isOrganizationError(err) {
+			return organizationError(c, err, "Failed to delete template")
+		}
+		if errors.Is(err, services.ErrTemplateNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+				Error:   "not_found",
+				Message: "Template not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to delete template",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Template moved to trash",
+	})
+}
+
 // EnhanceTemplate handles enhancing the template content using Gemini
 // POST /api/template/enhance
 func (h *TemplateHandler) EnhanceTemplate(c *fiber.Ctx) error {
@@ -179,14 +213,14 @@ func (h *TemplateHandler) EnhanceTemplate(c *fiber.Ctx) error {
 	// Update the template in the database with the enhanced content
 	// We assume the user wants to update the 'Body' of the template.
 	// If the request also included Subject, we could enhance that too, but for now let's stick to Body/Content.
-	updatedTemplate, err := h.templateService.UpdateTemplate(c.Context(), userID, models.UpdateTemplateRequest{
+	updatedTemplate, err := h.templateService.UpdateTemplate(c.Context(), userID, organizationID(c), models.UpdateTemplateRequest{
 		// We need to fetch the existing template first to preserve Name and Subject if we are only updating Body
 		// But for simplicity, let's fetch it first.
 		Body: result.EnhancedContent,
 	})
 
 	// Fetch existing template to preserve other fields
-	existingTemplate, err := h.templateService.GetTemplate(c.Context(), userID)
+	existingTemplate, err := h.templateService.GetTemplate(c.Context(), userID, organizationID(c))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
 			Error:   "server_error",
@@ -195,7 +229,7 @@ func (h *TemplateHandler) EnhanceTemplate(c *fiber.Ctx) error {
 	}
 
 	// Update with enhanced body, keeping existing name and subject
-	updatedTemplate, err = h.templateService.UpdateTemplate(c.Context(), userID, models.UpdateTemplateRequest{
+	updatedTemplate, err = h.templateService.UpdateTemplate(c.Context(), userID, organizationID(c), models.UpdateTemplateRequest{
 		Name:    existingTemplate.Name,
 		Subject: existingTemplate.Subject,
 		Body:    result.EnhancedContent,
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/internals/services"
+)
+
+// TrashHandler handles trash HTTP requests
+type TrashHandler struct {
+	trashService *services.TrashService
+}
+
+// NewTrashHandler creates a new trash handler
+func NewTrashHandler(trashService *services.TrashService) *TrashHandler {
+	return &TrashHandler{
+		trashService: trashService,
+	}
+}
+
+// ListTrash handles listing the organization's deleted contacts and templates
+// GET /api/trash
+func (h *TrashHandler) ListTrash(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	trash, err := h.trashService.ListTrash(c.Context(), userID, organizationID(c))
+	if err != nil {
+		if isOrganizationError(err) {
+			return organizationError(c, err, "Failed to fetch trash")
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to fetch trash",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(trash)
+}
+
+// RestoreContact handles restoring a deleted contact
+// POST /api/trash/contacts/:id/restore
+func (h *TrashHandler) RestoreContact(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	contact, err := h.trashService.RestoreContact(c.Context(), userID, c.Params("id"))
+	if err != nil {
+		return restoreError(c, err, "Failed to restore contact")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(contact)
+}
+
+// RestoreTemplate handles restoring a deleted template
+// POST /api/trash/templates/:id/restore
+func (h *TrashHandler) RestoreTemplate(c *fiber.Ctx) error {
+	userID := c.Locals("userId").(string)
+
+	template, err := h.trashService.RestoreTemplate(c.Context(), userID, c.Params("id"))
+	if err != nil {
+		return restoreError(c, err, "Failed to restore template")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(template)
+}
+
+func restoreError(c *fiber.Ctx, err error, message string) error {
+	if errors.Is(err, services.ErrTrashItemNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "not_found",
+			Message: err.Error(),
+		})
+	}
+	if isOrganizationError(err) {
+		return organizationError(c, err, message)
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+		Error:   "server_error",
+		Message: message,
+	})
+}
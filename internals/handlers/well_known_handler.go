@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/internals/utils"
+)
+
+// jwksMaxAge is how long clients may cache the key set, in seconds. A newly
+// added key should be published for at least this long before it signs tokens.
+const jwksMaxAge = "300"
+
+// WellKnownHandler serves public discovery documents
+type WellKnownHandler struct{}
+
+// NewWellKnownHandler creates a new well-known handler
+func NewWellKnownHandler() *WellKnownHandler {
+	return &WellKnownHandler{}
+}
+
+// JWKS handles publishing the public keys access tokens are signed with
+// GET /.well-known/jwks.json
+func (h *WellKnownHandler) JWKS(c *fiber.Ctx) error {
+	keys, err := utils.SigningKeySet()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to load signing keys",
+		})
+	}
+
+	c.Set(fiber.HeaderCacheControl, "public, max-age="+jwksMaxAge)
+	return c.Status(fiber.StatusOK).JSON(keys)
+}
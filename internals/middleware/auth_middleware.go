@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -8,6 +9,43 @@ import (
 	"github.com/satyam-svg/hr-message-backend/internals/utils"
 )
 
+// SessionValidator checks that the session an access token was issued for is
+// still active and returns the admin impersonating the user, if any
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, userID string, sessionID string) (string, error)
+}
+
+// APIKeyValidator resolves a personal API key to the user and scopes it grants
+type APIKeyValidator interface {
+	ValidateAPIKey(ctx context.Context, key string) (*models.APIKeyPrincipal, error)
+}
+
+// AdminChecker reports whether a user has the admin role
+type AdminChecker interface {
+	IsAdmin(ctx context.Context, userID string) (bool, error)
+}
+
+var sessionValidator SessionValidator
+
+var apiKeyValidator APIKeyValidator
+
+var adminChecker AdminChecker
+
+// SetSessionValidator registers the store AuthRequired uses to reject revoked sessions
+func SetSessionValidator(validator SessionValidator) {
+	sessionValidator = validator
+}
+
+// SetAPIKeyValidator registers the store AuthOrAPIKey uses to check API keys
+func SetAPIKeyValidator(validator APIKeyValidator) {
+	apiKeyValidator = validator
+}
+
+// SetAdminChecker registers the store AdminRequired uses to look up user roles
+func SetAdminChecker(checker AdminChecker) {
+	adminChecker = checker
+}
+
 // AuthRequired middleware validates JWT token
 func AuthRequired() fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -40,9 +78,129 @@ func AuthRequired() fiber.Handler {
 			})
 		}
 
+		// Reject tokens whose session was logged out or revoked
+		if claims.SessionID == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+				Error:   "unauthorized",
+				Message: "Invalid or expired token",
+			})
+		}
+		impersonatorID := ""
+		if sessionValidator != nil {
+			impersonatorID, err = sessionValidator.ValidateSession(c.Context(), claims.UserID, claims.SessionID)
+			if err != nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+					Error:   "unauthorized",
+					Message: "Session has been revoked",
+				})
+			}
+		}
+
 		// Set user ID in context
 		c.Locals("userId", claims.UserID)
 		c.Locals("userEmail", claims.Email)
+		c.Locals("sessionId", claims.SessionID)
+		// Activities recorded with the request context note the impersonating admin
+		if impersonatorID != "" {
+			c.Locals("impersonatorId", impersonatorID)
+		}
+
+		return c.Next()
+	}
+}
+
+// AuthOrAPIKey accepts either an access token, exactly like AuthRequired, or a
+// personal API key that was granted the scope. Requests authenticated by a key
+// have "apiKeyId" instead of "sessionId" in their locals.
+func AuthOrAPIKey(scope string) fiber.Handler {
+	authRequired := AuthRequired()
+
+	return func(c *fiber.Ctx) error {
+		parts := strings.Split(c.Get("Authorization"), " ")
+		if len(parts) != 2 || parts[0] != "Bearer" || !strings.HasPrefix(parts[1], utils.APIKeyPrefix) {
+			return authRequired(c)
+		}
+
+		if apiKeyValidator == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+				Error:   "unauthorized",
+				Message: "API keys are not accepted",
+			})
+		}
+
+		principal, err := apiKeyValidator.ValidateAPIKey(c.Context(), parts[1])
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+				Error:   "unauthorized",
+				Message: "Invalid or expired API key",
+			})
+		}
+
+		if !principal.HasScope(scope) {
+			return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+				Error:   "insufficient_scope",
+				Message: "API key is missing the " + scope + " scope",
+			})
+		}
+
+		// Set user ID in context
+		c.Locals("userId", principal.UserID)
+		c.Locals("userEmail", principal.Email)
+		c.Locals("apiKeyId", principal.KeyID)
+
+		return c.Next()
+	}
+}
+
+// NoImpersonation rejects requests from an impersonation session. It must run
+// after AuthRequired and guards credentials, sessions and keys, whose changes
+// would outlive the support session or lock the user out.
+func NoImpersonation() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if impersonatorID, _ := c.Locals("impersonatorId").(string); impersonatorID != "" {
+			return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+				Error:   "impersonation_forbidden",
+				Message: "Not allowed while impersonating a user",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// AdminRequired rejects users without the admin role. It must run after
+// AuthRequired, which sets the user ID; requests authenticated by an API key
+// are refused.
+func AdminRequired() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, ok := c.Locals("userId").(string)
+		if !ok || userID == "" || c.Locals("sessionId") == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+				Error:   "unauthorized",
+				Message: "Authorization header is required",
+			})
+		}
+
+		if adminChecker == nil {
+			return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+				Error:   "forbidden",
+				Message: "Admin access is required",
+			})
+		}
+
+		isAdmin, err := adminChecker.IsAdmin(c.Context(), userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error:   "server_error",
+				Message: "Failed to check permissions",
+			})
+		}
+		if !isAdmin {
+			return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+				Error:   "forbidden",
+				Message: "Admin access is required",
+			})
+		}
 
 		return c.Next()
 	}
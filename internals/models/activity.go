@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Activity target types
+const (
+	ActivityTargetUser          = "user"
+	ActivityTargetContact       = "contact"
+	ActivityTargetTemplate      = "template"
+	ActivityTargetCampaign      = "campaign"
+	ActivityTargetAPIKey        = "api_key"
+	ActivityTargetOrganization  = "organization"
+	ActivityTargetSenderAccount = "sender_account"
+	ActivityTargetSequence      = "sequence"
+)
+
+// ActivityResponse represents a single activity log
+type ActivityResponse struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	Description string          `json:"description"`
+	TargetType  string          `json:"target_type,omitempty"`
+	TargetID    string          `json:"target_id,omitempty"`
+	Metadata    json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// ListActivitiesQuery represents the filters and pagination for listing activities
+type ListActivitiesQuery struct {
+	Types      []string
+	TargetType string
+	TargetID   string
+	Page       int
+	Limit      int
+}
+
+// ActivityListResponse represents a page of activities
+type ActivityListResponse struct {
+	Activities []ActivityResponse `json:"activities"`
+	Page       int                `json:"page"`
+	Limit      int                `json:"limit"`
+	HasMore    bool               `json:"has_more"`
+}
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// User roles
+const (
+	UserRoleUser  = "user"
+	UserRoleAdmin = "admin"
+)
+
+// AdminUserResponse represents an account as shown to admins
+type AdminUserResponse struct {
+	ID               string     `json:"id"`
+	Name             string     `json:"name"`
+	Email            string     `json:"email"`
+	Role             string     `json:"role"`
+	EmailVerified    bool       `json:"email_verified"`
+	MFAEnabled       bool       `json:"mfa_enabled"`
+	DailyLimit       int        `json:"daily_limit"`
+	EmailsSent       int        `json:"emails_sent"`
+	PdfUploadCount   int        `json:"pdf_upload_count"`
+	SuspendedAt      *time.Time `json:"suspended_at,omitempty"`
+	SuspensionReason string     `json:"suspension_reason,omitempty"`
+	LockedUntil      *time.Time `json:"locked_until,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// ListUsersQuery represents the filters and pagination for listing users.
+// Search matches name or email.
+type ListUsersQuery struct {
+	Search    string
+	Role      string
+	Suspended *bool
+	Page      int
+	Limit     int
+}
+
+// AdminUserListResponse represents a page of users
+type AdminUserListResponse struct {
+	Users   []AdminUserResponse `json:"users"`
+	Page    int                 `json:"page"`
+	Limit   int                 `json:"limit"`
+	HasMore bool                `json:"has_more"`
+}
+
+// UpdateDailyLimitRequest represents the request to change a user's daily send limit
+type UpdateDailyLimitRequest struct {
+	DailyLimit int    `json:"daily_limit" validate:"required"`
+	Reason     string `json:"reason"`
+}
+
+// UpdateUserRoleRequest represents the request to grant or revoke the admin role
+type UpdateUserRoleRequest struct {
+	Role   string `json:"role" validate:"required"`
+	Reason string `json:"reason"`
+}
+
+// AdminReasonRequest carries the reason for suspending, unsuspending or impersonating a user
+type AdminReasonRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+// ImpersonationResponse contains a short-lived session for acting as the user
+type ImpersonationResponse struct {
+	TokenResponse
+	UserID           string    `json:"user_id"`
+	SessionExpiresAt time.Time `json:"session_expires_at"`
+}
+
+// ListCampaignsQuery represents the filters and pagination for listing campaigns
+type ListCampaignsQuery struct {
+	UserID string
+	Status string
+	Page   int
+	Limit  int
+}
+
+// AdminCampaignResponse represents a campaign with the user who started it
+type AdminCampaignResponse struct {
+	CampaignResponse
+	UserID         string `json:"user_id"`
+	UserEmail      string `json:"user_email"`
+	OrganizationID string `json:"organization_id"`
+}
+
+// AdminCampaignListResponse represents a page of campaigns
+type AdminCampaignListResponse struct {
+	Campaigns []AdminCampaignResponse `json:"campaigns"`
+	Page      int                     `json:"page"`
+	Limit     int                     `json:"limit"`
+	HasMore   bool                    `json:"has_more"`
+}
+
+// AdminCampaignDetailResponse represents a campaign and its failed sends
+type AdminCampaignDetailResponse struct {
+	AdminCampaignResponse
+	Failures []EmailFailureResponse `json:"failures"`
+}
+
+// ListEmailFailuresQuery represents the filters and pagination for listing failed sends
+type ListEmailFailuresQuery struct {
+	UserID     string
+	CampaignID string
+	Page       int
+	Limit      int
+}
+
+// EmailFailureResponse represents one failed send attempt
+type EmailFailureResponse struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	Recipient  string    `json:"recipient"`
+	Subject    string    `json:"subject"`
+	Error      string    `json:"error,omitempty"`
+	ContactID  string    `json:"contact_id,omitempty"`
+	CampaignID string    `json:"campaign_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// EmailFailureListResponse represents a page of failed sends
+type EmailFailureListResponse struct {
+	Failures []EmailFailureResponse `json:"failures"`
+	Page     int                    `json:"page"`
+	Limit    int                    `json:"limit"`
+	HasMore  bool                   `json:"has_more"`
+}
+
+// ListAuditLogQuery represents the filters and pagination for the admin audit log
+type ListAuditLogQuery struct {
+	AdminID      string
+	TargetUserID string
+	Page         int
+	Limit        int
+}
+
+// AuditLogEntryResponse represents one admin action
+type AuditLogEntryResponse struct {
+	ID           string          `json:"id"`
+	Action       string          `json:"action"`
+	AdminID      string          `json:"admin_id"`
+	TargetUserID string          `json:"target_user_id"`
+	Reason       string          `json:"reason,omitempty"`
+	Metadata     json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// AuditLogListResponse represents a page of the admin audit log
+type AuditLogListResponse struct {
+	Entries []AuditLogEntryResponse `json:"entries"`
+	Page    int                     `json:"page"`
+	Limit   int                     `json:"limit"`
+	HasMore bool                    `json:"has_more"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// API key scopes
+const (
+	APIKeyScopeContactsRead  = "contacts:read"
+	APIKeyScopeContactsWrite = "contacts:write"
+	APIKeyScopeEmailSend     = "email:send"
+)
+
+// APIKeyScopes lists every scope a key can be granted
+var APIKeyScopes = []string{
+	APIKeyScopeContactsRead,
+	APIKeyScopeContactsWrite,
+	APIKeyScopeEmailSend,
+}
+
+// CreateAPIKeyRequest represents the request to create an API key.
+// Without ExpiresInDays the key does not expire.
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" validate:"required"`
+	Scopes        []string `json:"scopes" validate:"required"`
+	ExpiresInDays *int     `json:"expires_in_days"`
+}
+
+// APIKeyResponse represents an API key without its secret
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyResponse includes the full key, which is shown only once
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+// APIKeyPrincipal is the identity a valid API key authenticates as
+type APIKeyPrincipal struct {
+	KeyID  string
+	UserID string
+	Email  string
+	Scopes []string
+}
+
+// HasScope reports whether the key was granted the scope
+func (p *APIKeyPrincipal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
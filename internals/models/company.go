@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// CompanyResponse represents a company in response
+type CompanyResponse struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Domain       string    `json:"domain,omitempty"`
+	Website      string    `json:"website,omitempty"`
+	Notes        string    `json:"notes,omitempty"`
+	ContactCount int       `json:"contact_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// CompanyDetailResponse represents a company together with its contacts
+type CompanyDetailResponse struct {
+	CompanyResponse
+	Contacts []ContactResponse `json:"contacts"`
+}
+
+// UpdateCompanyRequest represents the request to update a company
+type UpdateCompanyRequest struct {
+	Name    *string `json:"name"`
+	Website *string `json:"website"`
+	Notes   *string `json:"notes"`
+}
+
+// RematchCompaniesResponse reports how many contacts were linked to a company
+type RematchCompaniesResponse struct {
+	Matched int `json:"matched"`
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Contact represents a contact extracted from PDF
 type Contact struct {
@@ -16,12 +19,18 @@ type Contact struct {
 
 // ContactResponse represents contact data in response
 type ContactResponse struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	CompanyName string    `json:"company_name"`
-	Email       string    `json:"email"`
-	IsSent      bool      `json:"is_sent"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	CompanyName    string          `json:"company_name"`
+	CompanyID      string          `json:"company_id,omitempty"`
+	Email          string          `json:"email"`
+	IsSent         bool            `json:"is_sent"`
+	Status         string          `json:"status"`
+	Tags           []string        `json:"tags"`
+	CustomFields   json.RawMessage `json:"custom_fields,omitempty"`
+	Timezone       string          `json:"timezone,omitempty"`
+	UnsubscribedAt *time.Time      `json:"unsubscribed_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
 }
 
 // SaveContactRequest represents the request to save a contact
@@ -33,8 +42,106 @@ type SaveContactRequest struct {
 
 // UpdateContactRequest represents the request to update a contact
 type UpdateContactRequest struct {
-	Name        *string `json:"name"`
-	CompanyName *string `json:"company_name"`
-	Email       *string `json:"email"`
+	Name         *string `json:"name"`
+	CompanyName  *string `json:"company_name"`
+	Email        *string `json:"email"`
+	IsSent       *bool   `json:"is_sent"`
+	Status       *string `json:"status"`
+	Timezone     *string `json:"timezone"`     // IANA name; empty clears it
+	Unsubscribed *bool   `json:"unsubscribed"` // Stops campaign emails and follow-ups
+}
+
+// CreateContactNoteRequest represents the request to add a note to a contact
+type CreateContactNoteRequest struct {
+	Body string `json:"body" validate:"required"`
+}
+
+// ContactNoteResponse represents a contact note in response
+type ContactNoteResponse struct {
+	ID        string    `json:"id"`
+	ContactID string    `json:"contact_id"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Timeline entry types
+const (
+	TimelineEntryNote         = "note"
+	TimelineEntryEmailSent    = "email_sent"
+	TimelineEntryEmailFailed  = "email_failed"
+	TimelineEntryReply        = "reply"
+	TimelineEntryStatusChange = "status_change"
+)
+
+// TimelineEntry represents a single event in a contact's timeline
+type TimelineEntry struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Summary   string    `json:"summary"`
+	Detail    string    `json:"detail,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ContactTimelineResponse represents a contact's merged activity timeline
+type ContactTimelineResponse struct {
+	Contact ContactResponse `json:"contact"`
+	Entries []TimelineEntry `json:"entries"`
+}
+
+// Bulk contact actions
+const (
+	BulkActionDelete         = "delete"
+	BulkActionTag            = "tag"
+	BulkActionUntag          = "untag"
+	BulkActionMarkSent       = "mark_sent"
+	BulkActionMarkUnsent     = "mark_unsent"
+	BulkActionSetStatus      = "set_status"
+	BulkActionSetCustomField = "set_custom_field"
+)
+
+// Bulk item result statuses
+const (
+	BulkItemOK        = "ok"
+	BulkItemUnchanged = "unchanged"
+	BulkItemNotFound  = "not_found"
+	BulkItemFailed    = "failed"
+)
+
+// ContactFilter selects contacts by their attributes
+type ContactFilter struct {
 	IsSent      *bool   `json:"is_sent"`
+	Status      *string `json:"status"`
+	Tag         *string `json:"tag"`
+	CompanyName *string `json:"company_name"`
+	Search      *string `json:"search"`
+}
+
+// BulkContactRequest represents a bulk operation on a list of IDs or on a filter
+type BulkContactRequest struct {
+	Action string         `json:"action" validate:"required"`
+	IDs    []string       `json:"ids"`
+	Filter *ContactFilter `json:"filter"`
+	Tag    string         `json:"tag"`
+	Status string         `json:"status"`
+	Field  string         `json:"field"`
+	Value  interface{}    `json:"value"`
+}
+
+// BulkContactItemResult reports the outcome for a single contact
+type BulkContactItemResult struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkContactResponse represents the per-item report of a bulk operation
+type BulkContactResponse struct {
+	Action     string                  `json:"action"`
+	Total      int                     `json:"total"`
+	Succeeded  int                     `json:"succeeded"`
+	Unchanged  int                     `json:"unchanged"`
+	Failed     int                     `json:"failed"`
+	RolledBack bool                    `json:"rolled_back"`
+	Results    []BulkContactItemResult `json:"results"`
 }
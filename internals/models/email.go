@@ -1,9 +1,23 @@
 package models
 
+import "time"
+
 // SendEmailRequest represents the request payload for sending an email
 type SendEmailRequest struct {
-	SenderEmail     string   `json:"sender_email" validate:"required,email"`
-	SenderPassword  string   `json:"sender_password"` // Fetched from DB internally
+	SenderEmail    string `json:"sender_email" validate:"required,email"`
+	SenderPassword string `json:"sender_password"` // Fetched from DB internally
+	// SenderAccessToken is an OAuth access token used instead of the password when set
+	SenderAccessToken string `json:"-"`
+	// SenderUsername is the SMTP login when it differs from the sender email
+	SenderUsername string `json:"-"`
+	// SMTPHost and SMTPPort default to Gmail on port 465
+	SMTPHost string `json:"-"`
+	SMTPPort int    `json:"-"`
+	// MessageID is the Message-ID header without angle brackets; SendEmail makes one up when empty
+	MessageID string `json:"-"`
+	// InReplyTo and References thread a follow-up under earlier emails by their Message-IDs
+	InReplyTo       string   `json:"-"`
+	References      []string `json:"-"`
 	RecipientEmail  string   `json:"recipient_email"`
 	Subject         string   `json:"subject" validate:"required"`
 	Body            string   `json:"body" validate:"required"`
@@ -16,3 +30,86 @@ type SendEmailResponse struct {
 	Message string `json:"message"`
 	Success bool   `json:"success"`
 }
+
+// StartCampaignRequest represents the optional settings of a campaign
+type StartCampaignRequest struct {
+	// MaxPerCompanyPerDay caps how many emails go to one company per day
+	MaxPerCompanyPerDay *int `json:"max_per_company_per_day"`
+	// SenderAccountIDs selects the sender accounts to rotate through; without
+	// them the mailbox from the settings is used
+	SenderAccountIDs []string `json:"sender_account_ids"`
+	// SenderRotation is "round_robin" (default) or "least_used"
+	SenderRotation string `json:"sender_rotation"`
+	// ScheduledAt starts the campaign later instead of right away
+	ScheduledAt *time.Time `json:"scheduled_at"`
+	// SendWindows limits sending to these times; empty sends around the clock
+	SendWindows []SendWindow `json:"send_windows"`
+	// Timezone of the send windows (IANA name); defaults to the user's time zone, then UTC
+	Timezone string `json:"timezone"`
+	// UseRecipientTimezone applies the send windows in each contact's time zone when it is known
+	UseRecipientTimezone bool `json:"use_recipient_timezone"`
+	// Pacing controls how fast emails go out; by default one every 2 minutes
+	Pacing *Pacing `json:"pacing"`
+	// SequenceID sends the sequence's follow-ups to every contact the campaign emails
+	SequenceID string `json:"sequence_id"`
+}
+
+// Pacing controls how fast a campaign sends
+type Pacing struct {
+	// MinDelaySeconds and MaxDelaySeconds bound the random delay after every email
+	MinDelaySeconds *int `json:"min_delay_seconds"`
+	MaxDelaySeconds *int `json:"max_delay_seconds"`
+	// MaxPerHour caps the emails sent in any hour
+	MaxPerHour *int `json:"max_per_hour,omitempty"`
+	// WarmUpDays ramps sender accounts younger than this many days up to their daily limit
+	WarmUpDays *int `json:"warm_up_days,omitempty"`
+}
+
+// SendWindow allows sending on the given days between start and end
+type SendWindow struct {
+	Days  []string `json:"days"`  // e.g. ["mon", "tue", "wed", "thu", "fri"]
+	Start string   `json:"start"` // "09:00"
+	End   string   `json:"end"`   // "17:00", exclusive; "24:00" for midnight
+}
+
+// CampaignResponse represents a campaign and its progress
+type CampaignResponse struct {
+	ID                   string       `json:"id"`
+	Status               string       `json:"status"`
+	TotalContacts        int          `json:"total_contacts"`
+	SentCount            int          `json:"sent_count"`
+	FailedCount          int          `json:"failed_count"`
+	SkippedCount         int          `json:"skipped_count"`
+	MaxPerCompanyPerDay  *int         `json:"max_per_company_per_day,omitempty"`
+	SenderRotation       string       `json:"sender_rotation"`
+	SenderAccountIDs     []string     `json:"sender_account_ids,omitempty"`
+	SequenceID           string       `json:"sequence_id,omitempty"`
+	Pacing               Pacing       `json:"pacing"`
+	SendWindows          []SendWindow `json:"send_windows,omitempty"`
+	Timezone             string       `json:"timezone,omitempty"`
+	UseRecipientTimezone bool         `json:"use_recipient_timezone"`
+	ScheduledAt          *time.Time   `json:"scheduled_at,omitempty"`
+	NextRunAt            *time.Time   `json:"next_run_at,omitempty"`
+	StartedAt            time.Time    `json:"started_at"`
+	FinishedAt           *time.Time   `json:"finished_at,omitempty"`
+}
+
+// MailConnectResponse contains the Google URL to send the browser to for connecting Gmail
+type MailConnectResponse struct {
+	AuthorizationURL string    `json:"authorization_url"`
+	State            string    `json:"state"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+// MailConnectCallbackRequest represents the parameters Google redirected back with
+type MailConnectCallbackRequest struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
+
+// MailConnectionResponse represents the connected Gmail account
+type MailConnectionResponse struct {
+	Provider    string    `json:"provider"`
+	Email       string    `json:"email"`
+	ConnectedAt time.Time `json:"connected_at"`
+}
@@ -0,0 +1,21 @@
+package models
+
+// JWK is the public part of an access token signing key (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA keys
+	Modulus  string `json:"n,omitempty"`
+	Exponent string `json:"e,omitempty"`
+	// Ed25519 and EC keys
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
+// JWKSResponse is the JSON Web Key Set served at /.well-known/jwks.json
+type JWKSResponse struct {
+	Keys []JWK `json:"keys"`
+}
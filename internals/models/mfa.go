@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// TOTPSetupResponse carries the secret to add to an authenticator app
+type TOTPSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// ConfirmTOTPRequest represents the request to finish enrollment with a code from the app
+type ConfirmTOTPRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// MFAVerificationRequest represents a sensitive MFA change that needs both factors.
+// Code accepts a TOTP code or an unused recovery code.
+type MFAVerificationRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// RecoveryCodesResponse returns freshly generated recovery codes; they are shown only once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAStatusResponse reports whether two-factor authentication is enabled
+type MFAStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+// MFAChallengeResponse is returned by login instead of tokens when a second factor is required
+type MFAChallengeResponse struct {
+	MFARequired bool      `json:"mfa_required"`
+	MFAToken    string    `json:"mfa_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// LoginMFARequest represents the second login step
+type LoginMFARequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
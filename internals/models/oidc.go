@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// OIDCAuthorizeResponse contains the identity provider URL to send the browser to
+type OIDCAuthorizeResponse struct {
+	AuthorizationURL string    `json:"authorization_url"`
+	State            string    `json:"state"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+// OIDCCallbackRequest represents the parameters the identity provider redirected back with
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
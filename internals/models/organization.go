@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// OrganizationHeader selects the organization a request works in. Without it
+// the user's personal organization is used.
+const OrganizationHeader = "X-Organization-Id"
+
+// Organization roles
+const (
+	OrganizationRoleOwner  = "owner"
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleMember = "member"
+)
+
+// CreateOrganizationRequest represents the request to create a team organization
+type CreateOrganizationRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// UpdateOrganizationRequest represents the request to rename an organization
+type UpdateOrganizationRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// OrganizationResponse represents an organization and the caller's role in it
+type OrganizationResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Personal  bool      `json:"personal"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MemberResponse represents a member of an organization
+type MemberResponse struct {
+	UserID   string    `json:"user_id"`
+	Name     string    `json:"name"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// OrganizationDetailResponse represents an organization with its members
+type OrganizationDetailResponse struct {
+	OrganizationResponse
+	Members []MemberResponse `json:"members"`
+}
+
+// UpdateMemberRequest represents the request to change a member's role
+type UpdateMemberRequest struct {
+	Role string `json:"role" validate:"required"`
+}
+
+// InviteMemberRequest represents the request to invite someone by email.
+// Role defaults to member.
+type InviteMemberRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role"`
+}
+
+// InvitationResponse represents a pending invitation
+type InvitationResponse struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	InvitedBy string    `json:"invited_by"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AcceptInvitationRequest represents the request to join an organization
+type AcceptInvitationRequest struct {
+	Token string `json:"token" validate:"required"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// CreateSenderAccountRequest represents the request to add a mailbox to send from
+type CreateSenderAccountRequest struct {
+	Name       string `json:"name"`
+	Email      string `json:"email" validate:"required,email"`
+	SMTPHost   string `json:"smtp_host"` // Default: smtp.gmail.com
+	SMTPPort   int    `json:"smtp_port"` // Default: 465
+	Username   string `json:"username"`  // Default: the email
+	Password   string `json:"password" validate:"required"`
+	DailyLimit int    `json:"daily_limit"` // Default: 20
+	Signature  string `json:"signature"`
+	// Reply detection logs in to the inbox over IMAP with the same username and password
+	IMAPEnabled bool   `json:"imap_enabled"`
+	IMAPHost    string `json:"imap_host"` // Default: imap.gmail.com
+	IMAPPort    int    `json:"imap_port"` // Default: 993
+}
+
+// UpdateSenderAccountRequest represents a partial update of a sender account
+type UpdateSenderAccountRequest struct {
+	Name        *string `json:"name"`
+	SMTPHost    *string `json:"smtp_host"`
+	SMTPPort    *int    `json:"smtp_port"`
+	Username    *string `json:"username"`
+	Password    *string `json:"password"`
+	DailyLimit  *int    `json:"daily_limit"`
+	Signature   *string `json:"signature"`
+	IMAPEnabled *bool   `json:"imap_enabled"`
+	IMAPHost    *string `json:"imap_host"`
+	IMAPPort    *int    `json:"imap_port"`
+}
+
+// SenderAccountResponse represents a sender account; the password is never returned
+type SenderAccountResponse struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Email        string     `json:"email"`
+	SMTPHost     string     `json:"smtp_host"`
+	SMTPPort     int        `json:"smtp_port"`
+	Username     string     `json:"username"`
+	DailyLimit   int        `json:"daily_limit"`
+	SentToday    int        `json:"sent_today"`
+	Signature    string     `json:"signature,omitempty"`
+	IMAPEnabled  bool       `json:"imap_enabled"`
+	IMAPHost     string     `json:"imap_host"`
+	IMAPPort     int        `json:"imap_port"`
+	IMAPSyncedAt *time.Time `json:"imap_synced_at,omitempty"`
+	IMAPError    string     `json:"imap_error,omitempty"` // Why the last inbox check failed
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// ReplySyncResponse reports the result of checking a sender account's inbox for replies
+type ReplySyncResponse struct {
+	Checked  int       `json:"checked"` // New messages fetched
+	Replies  int       `json:"replies"` // Messages recorded as replies from contacts
+	SyncedAt time.Time `json:"synced_at"`
+}
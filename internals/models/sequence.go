@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// SequenceStepRequest is one follow-up of a sequence
+type SequenceStepRequest struct {
+	// DelayDays is how long after the previous email the follow-up is sent
+	DelayDays int `json:"delay_days"`
+	// Subject of the follow-up; empty replies with "Re: " and the previous subject
+	Subject string `json:"subject"`
+	// Body supports the same {name}, {company} and {hr_name} placeholders as the template
+	Body string `json:"body" validate:"required"`
+}
+
+// SaveSequenceRequest represents the request to create or replace a sequence
+type SaveSequenceRequest struct {
+	Name  string                `json:"name" validate:"required"`
+	Steps []SequenceStepRequest `json:"steps" validate:"required"`
+}
+
+// SequenceStepResponse represents one follow-up of a sequence
+type SequenceStepResponse struct {
+	Position  int    `json:"position"`
+	DelayDays int    `json:"delay_days"`
+	Subject   string `json:"subject,omitempty"`
+	Body      string `json:"body"`
+}
+
+// SequenceResponse represents a sequence and how many contacts are in it
+type SequenceResponse struct {
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	Steps     []SequenceStepResponse `json:"steps"`
+	Active    int                    `json:"active"`
+	Completed int                    `json:"completed"`
+	Stopped   int                    `json:"stopped"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+// EnrollmentResponse represents the progress of one contact through a sequence
+type EnrollmentResponse struct {
+	ID         string     `json:"id"`
+	SequenceID string     `json:"sequence_id"`
+	CampaignID string     `json:"campaign_id"`
+	ContactID  string     `json:"contact_id"`
+	Status     string     `json:"status"` // active, completed or stopped
+	StepsSent  int        `json:"steps_sent"`
+	NextSendAt *time.Time `json:"next_send_at,omitempty"`
+	StopReason string     `json:"stop_reason,omitempty"` // replied, bounced, unsubscribed, manual or failed
+	StoppedAt  *time.Time `json:"stopped_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
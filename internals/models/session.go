@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// ClientInfo describes the device a session was started or refreshed from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// SessionResponse represents an active login session
+type SessionResponse struct {
+	ID           string    `json:"id"`
+	UserAgent    string    `json:"user_agent,omitempty"`
+	IPAddress    string    `json:"ip_address,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastSeenAt   time.Time `json:"last_seen_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	Current      bool      `json:"current"`
+	Impersonated bool      `json:"impersonated"`
+}
+
+// RevokeSessionsResponse reports how many sessions were revoked
+type RevokeSessionsResponse struct {
+	Revoked int `json:"revoked"`
+}
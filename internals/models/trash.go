@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// TrashedContact represents a contact in the trash
+type TrashedContact struct {
+	ContactResponse
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// TrashedTemplate represents a template in the trash
+type TrashedTemplate struct {
+	TemplateResponse
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// TrashResponse lists the user's deleted contacts and templates
+type TrashResponse struct {
+	Contacts      []TrashedContact  `json:"contacts"`
+	Templates     []TrashedTemplate `json:"templates"`
+	RetentionDays int               `json:"retention_days"`
+}
@@ -21,41 +21,90 @@ type UpdateEmailSettingsRequest struct {
 	MailAppPassword   string `json:"mail_app_password" validate:"required"`
 }
 
+// UpdateTimezoneRequest represents the request to change the user's time zone
+type UpdateTimezoneRequest struct {
+	Timezone string `json:"timezone" validate:"required"` // IANA name, e.g. Europe/Berlin
+}
+
+// ForgotPasswordRequest represents the request to email a password reset link
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest represents the request to set a new password with a reset token
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// ChangePasswordRequest represents the request to change the password of a signed in user
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8"`
+}
+
+// ChangeEmailRequest represents the request to change the login email of a signed in user
+type ChangeEmailRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewEmail        string `json:"new_email" validate:"required,email"`
+}
+
+// VerifyEmailRequest represents the request to confirm an email address
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// UnlockAccountRequest represents the request to lift a login lockout
+type UnlockAccountRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// RefreshTokenRequest represents the request to exchange a refresh token
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// TokenResponse represents a freshly issued access and refresh token pair
+type TokenResponse struct {
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
 // AuthResponse represents the authentication response
 type AuthResponse struct {
-	User  UserResponse `json:"user"`
-	Token string       `json:"token"`
+	User UserResponse `json:"user"`
+	TokenResponse
 }
 
 // UserResponse represents user data in response
 type UserResponse struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"createdAt"`
-}
-
-// ActivityResponse represents a single activity log
-type ActivityResponse struct {
-	ID          string    `json:"id"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID            string    `json:"id"`
+	Email         string    `json:"email"`
+	Name          string    `json:"name"`
+	EmailVerified bool      `json:"emailVerified"`
+	CreatedAt     time.Time `json:"createdAt"`
 }
 
 // UserProfileResponse represents full user profile data
 type UserProfileResponse struct {
-	ID                string             `json:"id"`
-	Email             string             `json:"email"`
-	Name              string             `json:"name"`
-	ProfessionalEmail string             `json:"professional_email,omitempty"`
-	MailAppPassword   string             `json:"mail_app_password,omitempty"`
-	DailyLimit        int                `json:"daily_limit"`
-	PdfUploadCount    int                `json:"pdf_upload_count"`
-	EmailsSent        int                `json:"emails_sent"`
-	CreatedAt         time.Time          `json:"created_at"`
-	Contacts          []ContactResponse  `json:"contacts"`
-	Activities        []ActivityResponse `json:"activities"`
-	Template          *TemplateResponse  `json:"template,omitempty"`
+	ID                        string               `json:"id"`
+	Email                     string               `json:"email"`
+	Name                      string               `json:"name"`
+	Role                      string               `json:"role"`
+	ProfessionalEmail         string               `json:"professional_email,omitempty"`
+	MailAppPasswordConfigured bool                 `json:"mail_app_password_configured"`
+	MailOAuthEmail            string               `json:"mail_oauth_email,omitempty"`
+	Timezone                  string               `json:"timezone,omitempty"`
+	EmailVerified             bool                 `json:"email_verified"`
+	MFAEnabled                bool                 `json:"mfa_enabled"`
+	DailyLimit                int                  `json:"daily_limit"`
+	PdfUploadCount            int                  `json:"pdf_upload_count"`
+	EmailsSent                int                  `json:"emails_sent"`
+	CreatedAt                 time.Time            `json:"created_at"`
+	Organization              OrganizationResponse `json:"organization"`
+	Contacts                  []ContactResponse    `json:"contacts"`
+	Template                  *TemplateResponse    `json:"template,omitempty"`
 }
 
 // ErrorResponse represents error response
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/handlers"
+	"github.com/satyam-svg/hr-message-backend/internals/middleware"
+)
+
+func SetupActivityRoutes(app *fiber.App, activityHandler *handlers.ActivityHandler) {
+	activities := app.Group("/api/activities", middleware.AuthRequired())
+
+	activities.Get("/", activityHandler.ListActivities)
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/handlers"
+	"github.com/satyam-svg/hr-message-backend/internals/middleware"
+)
+
+// SetupAdminRoutes sets up the admin API, available only to users with the admin role
+func SetupAdminRoutes(app *fiber.App, adminHandler *handlers.AdminHandler) {
+	admin := app.Group("/api/admin", middleware.AuthRequired(), middleware.AdminRequired())
+
+	admin.Get("/users", adminHandler.ListUsers)
+	admin.Get("/users/:id", adminHandler.GetUser)
+	admin.Patch("/users/:id/limits", adminHandler.UpdateDailyLimit)
+	admin.Patch("/users/:id/role", adminHandler.UpdateRole)
+	admin.Post("/users/:id/suspend", adminHandler.SuspendUser)
+	admin.Post("/users/:id/unsuspend", adminHandler.UnsuspendUser)
+	admin.Post("/users/:id/impersonate", adminHandler.ImpersonateUser)
+	admin.Get("/campaigns", adminHandler.ListCampaigns)
+	admin.Get("/campaigns/:id", adminHandler.GetCampaign)
+	admin.Get("/email-failures", adminHandler.ListEmailFailures)
+	admin.Get("/audit-log", adminHandler.ListAuditLog)
+}
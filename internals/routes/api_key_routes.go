@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/handlers"
+	"github.com/satyam-svg/hr-message-backend/internals/middleware"
+)
+
+// SetupAPIKeyRoutes sets up API key management routes. Keys are managed with a
+// login session only, so a leaked key cannot mint more keys, and not while
+// impersonating, so a key cannot outlive the support session.
+func SetupAPIKeyRoutes(app *fiber.App, apiKeyHandler *handlers.APIKeyHandler) {
+	apiKeys := app.Group("/api/api-keys", middleware.AuthRequired(), middleware.NoImpersonation())
+
+	apiKeys.Get("/", apiKeyHandler.ListAPIKeys)
+	apiKeys.Post("/", apiKeyHandler.CreateAPIKey)
+	apiKeys.Delete("/:id", apiKeyHandler.RevokeAPIKey)
+}
@@ -14,8 +14,24 @@ func SetupAuthRoutes(app *fiber.App, authHandler *handlers.AuthHandler) {
 	// Public routes
 	auth.Post("/signup", authHandler.Signup)
 	auth.Post("/login", authHandler.Login)
+	auth.Post("/login/mfa", authHandler.LoginMFA)
+	auth.Post("/refresh", authHandler.Refresh)
+	auth.Post("/password/forgot", authHandler.ForgotPassword)
+	auth.Post("/password/reset", authHandler.ResetPassword)
+	auth.Post("/email/verify", authHandler.VerifyEmail)
+	auth.Post("/unlock", authHandler.UnlockAccount)
 
 	// Protected routes
-	auth.Put("/email-settings", middleware.AuthRequired(), authHandler.UpdateEmailSettings)
+	auth.Put("/timezone", middleware.AuthRequired(), authHandler.UpdateTimezone)
 	auth.Get("/me", middleware.AuthRequired(), authHandler.GetProfile)
+	auth.Post("/logout", middleware.AuthRequired(), authHandler.Logout)
+
+	// Credentials and sessions cannot be changed while impersonating
+	auth.Put("/email-settings", middleware.AuthRequired(), middleware.NoImpersonation(), authHandler.UpdateEmailSettings)
+	auth.Put("/password", middleware.AuthRequired(), middleware.NoImpersonation(), authHandler.ChangePassword)
+	auth.Put("/email", middleware.AuthRequired(), middleware.NoImpersonation(), authHandler.ChangeEmail)
+	auth.Post("/email/verify/resend", middleware.AuthRequired(), middleware.NoImpersonation(), authHandler.ResendVerification)
+	auth.Get("/sessions", middleware.AuthRequired(), middleware.NoImpersonation(), authHandler.ListSessions)
+	auth.Delete("/sessions", middleware.AuthRequired(), middleware.NoImpersonation(), authHandler.RevokeOtherSessions)
+	auth.Delete("/sessions/:id", middleware.AuthRequired(), middleware.NoImpersonation(), authHandler.RevokeSession)
 }
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/handlers"
+	"github.com/satyam-svg/hr-message-backend/internals/middleware"
+)
+
+func SetupCompanyRoutes(app *fiber.App, companyHandler *handlers.CompanyHandler) {
+	companies := app.Group("/api/companies", middleware.AuthRequired())
+
+	companies.Get("/", companyHandler.ListCompanies)
+	companies.Post("/rematch", companyHandler.RematchContacts)
+	companies.Get("/:id", companyHandler.GetCompany)
+	companies.Patch("/:id", companyHandler.UpdateCompany)
+}
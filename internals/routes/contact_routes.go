@@ -4,12 +4,22 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/satyam-svg/hr-message-backend/internals/handlers"
 	"github.com/satyam-svg/hr-message-backend/internals/middleware"
+	"github.com/satyam-svg/hr-message-backend/internals/models"
 )
 
 func SetupContactRoutes(app *fiber.App, contactHandler *handlers.ContactHandler) {
 	api := app.Group("/api")
 
-	// Protected routes
-	contacts := api.Group("/contacts", middleware.AuthRequired())
-	contacts.Patch("/:id", contactHandler.UpdateContact)
+	// Protected routes, also usable with API keys that have the matching scope
+	read := middleware.AuthOrAPIKey(models.APIKeyScopeContactsRead)
+	write := middleware.AuthOrAPIKey(models.APIKeyScopeContactsWrite)
+
+	contacts := api.Group("/contacts")
+	contacts.Post("/bulk", write, contactHandler.BulkUpdate)
+	contacts.Patch("/:id", write, contactHandler.UpdateContact)
+	contacts.Delete("/:id", write, contactHandler.DeleteContact)
+	contacts.Get("/:id/timeline", read, contactHandler.GetTimeline)
+	contacts.Get("/:id/notes", read, contactHandler.ListNotes)
+	contacts.Post("/:id/notes", write, contactHandler.AddNote)
+	contacts.Delete("/:id/notes/:noteId", write, contactHandler.DeleteNote)
 }
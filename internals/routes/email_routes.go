@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/satyam-svg/hr-message-backend/internals/handlers"
 	"github.com/satyam-svg/hr-message-backend/internals/middleware"
+	"github.com/satyam-svg/hr-message-backend/internals/models"
 )
 
 // SetupEmailRoutes sets up email routes
@@ -11,7 +12,8 @@ func SetupEmailRoutes(app *fiber.App, emailHandler *handlers.EmailHandler) {
 	// Create email group
 	email := app.Group("/api/email")
 
-	// Protected routes
-	email.Post("/send", middleware.AuthRequired(), emailHandler.SendEmail)
-	email.Post("/campaign/start", middleware.AuthRequired(), emailHandler.StartCampaign)
+	// Protected routes, also usable with API keys that have the email:send scope
+	send := middleware.AuthOrAPIKey(models.APIKeyScopeEmailSend)
+	email.Post("/send", send, emailHandler.SendEmail)
+	email.Post("/campaign/start", send, emailHandler.StartCampaign)
 }
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/handlers"
+	"github.com/satyam-svg/hr-message-backend/internals/middleware"
+)
+
+// SetupMailOAuthRoutes sets up routes for connecting a Gmail account
+func SetupMailOAuthRoutes(app *fiber.App, mailOAuthHandler *handlers.MailOAuthHandler) {
+	google := app.Group("/api/email/oauth/google", middleware.AuthRequired())
+
+	google.Get("/", mailOAuthHandler.GetStatus)
+
+	// Mail credentials cannot be changed while impersonating
+	google.Post("/connect", middleware.NoImpersonation(), mailOAuthHandler.Connect)
+	google.Post("/callback", middleware.NoImpersonation(), mailOAuthHandler.Callback)
+	google.Delete("/", middleware.NoImpersonation(), mailOAuthHandler.Disconnect)
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/handlers"
+	"github.com/satyam-svg/hr-message-backend/internals/middleware"
+)
+
+// SetupMFARoutes sets up two-factor authentication routes. They are not
+// available while impersonating.
+func SetupMFARoutes(app *fiber.App, mfaHandler *handlers.MFAHandler) {
+	mfa := app.Group("/api/auth/mfa", middleware.AuthRequired(), middleware.NoImpersonation())
+
+	mfa.Get("/", mfaHandler.GetStatus)
+	mfa.Post("/totp/setup", mfaHandler.SetupTOTP)
+	mfa.Post("/totp/confirm", mfaHandler.ConfirmTOTP)
+	mfa.Post("/disable", mfaHandler.Disable)
+	mfa.Post("/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+}
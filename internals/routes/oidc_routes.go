@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/handlers"
+)
+
+// SetupOIDCRoutes sets up single sign-on routes
+func SetupOIDCRoutes(app *fiber.App, oidcHandler *handlers.OIDCHandler) {
+	sso := app.Group("/api/auth/sso")
+
+	sso.Post("/authorize", oidcHandler.Authorize)
+	sso.Post("/callback", oidcHandler.Callback)
+}
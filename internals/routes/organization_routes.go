@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/handlers"
+	"github.com/satyam-svg/hr-message-backend/internals/middleware"
+)
+
+// SetupOrganizationRoutes sets up organization, member and invitation routes
+func SetupOrganizationRoutes(app *fiber.App, organizationHandler *handlers.OrganizationHandler) {
+	organizations := app.Group("/api/organizations", middleware.AuthRequired())
+
+	organizations.Get("/", organizationHandler.ListOrganizations)
+	organizations.Post("/", organizationHandler.CreateOrganization)
+	organizations.Post("/invitations/accept", organizationHandler.AcceptInvitation)
+	organizations.Get("/:id", organizationHandler.GetOrganization)
+	organizations.Patch("/:id", organizationHandler.UpdateOrganization)
+	organizations.Patch("/:id/members/:userId", organizationHandler.UpdateMember)
+	organizations.Delete("/:id/members/:userId", organizationHandler.RemoveMember)
+	organizations.Get("/:id/invitations", organizationHandler.ListInvitations)
+	organizations.Post("/:id/invitations", organizationHandler.InviteMember)
+	organizations.Delete("/:id/invitations/:invitationId", organizationHandler.RevokeInvitation)
+}
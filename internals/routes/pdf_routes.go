@@ -4,9 +4,10 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/satyam-svg/hr-message-backend/internals/handlers"
 	"github.com/satyam-svg/hr-message-backend/internals/middleware"
+	"github.com/satyam-svg/hr-message-backend/internals/models"
 )
 
 func SetupPDFRoutes(app *fiber.App, pdfHandler *handlers.PDFHandler) {
-	// Protected route - requires authentication
-	app.Post("/upload", middleware.AuthRequired(), pdfHandler.UploadPDF)
+	// Protected route - requires authentication; imports contacts, so API keys need contacts:write
+	app.Post("/upload", middleware.AuthOrAPIKey(models.APIKeyScopeContactsWrite), pdfHandler.UploadPDF)
 }
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/handlers"
+	"github.com/satyam-svg/hr-message-backend/internals/middleware"
+)
+
+// SetupSenderAccountRoutes sets up routes for managing the mailboxes a user sends from
+func SetupSenderAccountRoutes(app *fiber.App, senderAccountHandler *handlers.SenderAccountHandler) {
+	senderAccounts := app.Group("/api/sender-accounts", middleware.AuthRequired())
+
+	senderAccounts.Get("/", senderAccountHandler.ListSenderAccounts)
+	senderAccounts.Post("/:id/sync", senderAccountHandler.SyncSenderAccount)
+
+	// Mail credentials cannot be changed while impersonating
+	senderAccounts.Post("/", middleware.NoImpersonation(), senderAccountHandler.CreateSenderAccount)
+	senderAccounts.Patch("/:id", middleware.NoImpersonation(), senderAccountHandler.UpdateSenderAccount)
+	senderAccounts.Delete("/:id", middleware.NoImpersonation(), senderAccountHandler.DeleteSenderAccount)
+}
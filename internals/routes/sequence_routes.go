@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/handlers"
+	"github.com/satyam-svg/hr-message-backend/internals/middleware"
+)
+
+// SetupSequenceRoutes sets up routes for follow-up sequences and their enrolled contacts
+func SetupSequenceRoutes(app *fiber.App, sequenceHandler *handlers.SequenceHandler) {
+	sequences := app.Group("/api/sequences", middleware.AuthRequired())
+
+	sequences.Get("/", sequenceHandler.ListSequences)
+	sequences.Post("/", sequenceHandler.CreateSequence)
+	sequences.Get("/:id", sequenceHandler.GetSequence)
+	sequences.Put("/:id", sequenceHandler.UpdateSequence)
+	sequences.Delete("/:id", sequenceHandler.DeleteSequence)
+	sequences.Get("/:id/enrollments", sequenceHandler.ListEnrollments)
+	sequences.Post("/:id/enrollments/:enrollmentId/stop", sequenceHandler.StopEnrollment)
+}
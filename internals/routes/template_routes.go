@@ -12,5 +12,6 @@ func SetupTemplateRoutes(app *fiber.App, templateHandler *handlers.TemplateHandl
 	// Protected routes - require authentication
 	template.Get("/", middleware.AuthRequired(), templateHandler.GetTemplate)
 	template.Put("/", middleware.AuthRequired(), templateHandler.UpdateTemplate)
+	template.Delete("/", middleware.AuthRequired(), templateHandler.DeleteTemplate)
 	template.Post("/enhance", middleware.AuthRequired(), templateHandler.EnhanceTemplate)
 }
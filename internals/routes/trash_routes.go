@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/handlers"
+	"github.com/satyam-svg/hr-message-backend/internals/middleware"
+)
+
+func SetupTrashRoutes(app *fiber.App, trashHandler *handlers.TrashHandler) {
+	trash := app.Group("/api/trash", middleware.AuthRequired())
+
+	trash.Get("/", trashHandler.ListTrash)
+	trash.Post("/contacts/:id/restore", trashHandler.RestoreContact)
+	trash.Post("/templates/:id/restore", trashHandler.RestoreTemplate)
+}
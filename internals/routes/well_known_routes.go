@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/satyam-svg/hr-message-backend/internals/handlers"
+)
+
+// SetupWellKnownRoutes sets up public discovery routes
+func SetupWellKnownRoutes(app *fiber.App, wellKnownHandler *handlers.WellKnownHandler) {
+	wellKnown := app.Group("/.well-known")
+
+	wellKnown.Get("/jwks.json", wellKnownHandler.JWKS)
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+)
+
+const (
+	defaultActivityPageSize = 20
+	maxActivityPageSize     = 100
+)
+
+// ErrInvalidActivityType is returned when a type filter is not a known activity type
+var ErrInvalidActivityType = errors.New("invalid activity type")
+
+// ActivityEvent describes a single entry to write to a user's activity log
+type ActivityEvent struct {
+	Type        db.ActivityType
+	Description string
+	TargetType  string
+	TargetID    string
+	Metadata    map[string]interface{}
+}
+
+// ActivityService handles the typed activity/audit log
+type ActivityService struct {
+	client *db.PrismaClient
+}
+
+// NewActivityService creates a new activity service
+func NewActivityService(client *db.PrismaClient) *ActivityService {
+	return &ActivityService{
+		client: client,
+	}
+}
+
+// Record writes an activity for the user. Logging must never break the
+// operation being logged, so failures are only printed. Activities of an
+// impersonated request have the admin's ID in "impersonated_by".
+func (s *ActivityService) Record(ctx context.Context, userID string, event ActivityEvent) {
+	var params []db.ActivitySetParam
+	if event.TargetType != "" {
+		params = append(params, db.Activity.TargetType.Set(event.TargetType))
+	}
+	if event.TargetID != "" {
+		params = append(params, db.Activity.TargetID.Set(event.TargetID))
+	}
+	if adminID := impersonatorID(ctx); adminID != "" {
+		metadata := make(map[string]interface{}, len(event.Metadata)+1)
+		for key, value := range event.Metadata {
+			metadata[key] = value
+		}
+		metadata["impersonated_by"] = adminID
+		event.Metadata = metadata
+	}
+	if len(event.Metadata) > 0 {
+		metadata, err := json.Marshal(event.Metadata)
+		if err != nil {
+			fmt.Printf("Failed to encode activity metadata: %v\n", err)
+		} else {
+			params = append(params, db.Activity.Metadata.Set(metadata))
+		}
+	}
+
+	_, err := s.client.Activity.CreateOne(
+		db.Activity.Type.Set(event.Type),
+		db.Activity.Description.Set(event.Description),
+		db.Activity.User.Link(db.User.ID.Equals(userID)),
+		params...,
+	).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to record %s activity for user %s: %v\n", event.Type, userID, err)
+	}
+}
+
+// impersonatorID returns the admin acting as the user when ctx is the context
+// of an impersonated request. The auth middleware stores it in the locals.
+func impersonatorID(ctx context.Context) string {
+	adminID, _ := ctx.Value("impersonatorId").(string)
+	return adminID
+}
+
+// ListActivities returns a page of the user's activities, newest first
+func (s *ActivityService) ListActivities(ctx context.Context, userID string, query models.ListActivitiesQuery) (*models.ActivityListResponse, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = defaultActivityPageSize
+	}
+	if query.Limit > maxActivityPageSize {
+		query.Limit = maxActivityPageSize
+	}
+
+	where := []db.ActivityWhereParam{
+		db.Activity.UserID.Equals(userID),
+	}
+
+	if len(query.Types) > 0 {
+		var types []db.ActivityType
+		for _, t := range query.Types {
+			activityType, err := parseActivityType(t)
+			if err != nil {
+				return nil, err
+			}
+			types = append(types, activityType)
+		}
+		where = append(where, db.Activity.Type.In(types))
+	}
+	if query.TargetType != "" {
+		where = append(where, db.Activity.TargetType.Equals(query.TargetType))
+	}
+	if query.TargetID != "" {
+		where = append(where, db.Activity.TargetID.Equals(query.TargetID))
+	}
+
+	// Fetch one extra row to know whether another page exists
+	activities, err := s.client.Activity.FindMany(
+		where...,
+	).OrderBy(
+		db.Activity.CreatedAt.Order(db.SortOrderDesc),
+	).Skip(
+		(query.Page - 1) * query.Limit,
+	).Take(
+		query.Limit + 1,
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch activities: %w", err)
+	}
+
+	hasMore := len(activities) > query.Limit
+	if hasMore {
+		activities = activities[:query.Limit]
+	}
+
+	response := &models.ActivityListResponse{
+		Activities: []models.ActivityResponse{},
+		Page:       query.Page,
+		Limit:      query.Limit,
+		HasMore:    hasMore,
+	}
+	for i := range activities {
+		response.Activities = append(response.Activities, toActivityResponse(&activities[i]))
+	}
+
+	return response, nil
+}
+
+// parseActivityType converts a client supplied type (e.g. "login") into an ActivityType
+func parseActivityType(value string) (db.ActivityType, error) {
+	activityType := db.ActivityType(strings.ToUpper(strings.TrimSpace(value)))
+	switch activityType {
+	case db.ActivityTypeSignup,
+		db.ActivityTypeLogin,
+		db.ActivityTypeSettingsUpdated,
+		db.ActivityTypeTemplateUpdated,
+		db.ActivityTypeContactUpdated,
+		db.ActivityTypePdfUploaded,
+		db.ActivityTypeCampaignStarted,
+		db.ActivityTypeCampaignFinished,
+		db.ActivityTypeEmailSendFailed,
+		db.ActivityTypeContactDeleted,
+		db.ActivityTypeContactRestored,
+		db.ActivityTypeTemplateDeleted,
+		db.ActivityTypeTemplateRestored,
+		db.ActivityTypeLogout,
+		db.ActivityTypeRefreshTokenReused,
+		db.ActivityTypeSessionRevoked,
+		db.ActivityTypePasswordResetRequested,
+		db.ActivityTypePasswordReset,
+		db.ActivityTypeEmailVerified,
+		db.ActivityTypePasswordChanged,
+		db.ActivityTypeEmailChanged,
+		db.ActivityTypeMfaEnabled,
+		db.ActivityTypeMfaDisabled,
+		db.ActivityTypeMfaRecoveryCodesRegenerated,
+		db.ActivityTypeMfaRecoveryCodeUsed,
+		db.ActivityTypeAccountLocked,
+		db.ActivityTypeAccountUnlocked,
+		db.ActivityTypeAPIKeyCreated,
+		db.ActivityTypeAPIKeyRevoked,
+		db.ActivityTypeOrganizationCreated,
+		db.ActivityTypeOrganizationUpdated,
+		db.ActivityTypeMemberInvited,
+		db.ActivityTypeMemberJoined,
+		db.ActivityTypeMemberRoleChanged,
+		db.ActivityTypeMemberRemoved,
+		db.ActivityTypeAccountSuspended,
+		db.ActivityTypeAccountUnsuspended,
+		db.ActivityTypeImpersonatedByAdmin,
+		db.ActivityTypeIdentityLinked,
+		db.ActivityTypeMailAccountConnected,
+		db.ActivityTypeMailAccountDisconnected,
+		db.ActivityTypeSenderAccountCreated,
+		db.ActivityTypeSenderAccountUpdated,
+		db.ActivityTypeSenderAccountDeleted,
+		db.ActivityTypeSequenceCreated,
+		db.ActivityTypeSequenceUpdated,
+		db.ActivityTypeSequenceDeleted,
+		db.ActivityTypeFollowUpsStopped,
+		db.ActivityTypeContactUnsubscribed,
+		db.ActivityTypeReplyReceived:
+		return activityType, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrInvalidActivityType, value)
+}
+
+func toActivityResponse(a *db.ActivityModel) models.ActivityResponse {
+	response := models.ActivityResponse{
+		ID:          a.ID,
+		Type:        strings.ToLower(string(a.Type)),
+		Description: a.Description,
+		CreatedAt:   a.CreatedAt,
+	}
+	if v, ok := a.TargetType(); ok {
+		response.TargetType = v
+	}
+	if v, ok := a.TargetID(); ok {
+		response.TargetID = v
+	}
+	if v, ok := a.Metadata(); ok {
+		response.Metadata = json.RawMessage(v)
+	}
+
+	return response
+}
@@ -0,0 +1,710 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+)
+
+const (
+	defaultAdminPageSize = 20
+	maxAdminPageSize     = 100
+)
+
+// impersonationExpiry is how long a support session started by an admin lasts.
+// Refreshing it does not extend it.
+const impersonationExpiry = time.Hour
+
+// maxDailyLimit caps the daily send limit an admin can grant
+const maxDailyLimit = 10000
+
+// ErrAccountSuspended is returned when a suspended user tries to log in or use the API
+var ErrAccountSuspended = errors.New("account is suspended")
+
+// ErrUserNotFound is returned when an admin targets an unknown user
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrInvalidAdminRequest is returned when a limit, role, status or reason is missing or invalid
+var ErrInvalidAdminRequest = errors.New("invalid admin request")
+
+// ErrCannotTargetSelf is returned when an admin tries to suspend, demote or impersonate themselves
+var ErrCannotTargetSelf = errors.New("admins cannot perform this action on their own account")
+
+// ErrCannotTargetAdmin is returned when impersonating or suspending another admin
+var ErrCannotTargetAdmin = errors.New("this action cannot be performed on an admin account")
+
+// ErrCampaignNotFound is returned when a campaign does not exist
+var ErrCampaignNotFound = errors.New("campaign not found")
+
+// AdminService handles the admin API: user management, support impersonation and the admin audit log
+type AdminService struct {
+	client     *db.PrismaClient
+	activities *ActivityService
+	sessions   *SessionService
+}
+
+// NewAdminService creates a new admin service
+func NewAdminService(client *db.PrismaClient) *AdminService {
+	return &AdminService{
+		client:     client,
+		activities: NewActivityService(client),
+		sessions:   NewSessionService(client),
+	}
+}
+
+// IsAdmin reports whether the user has the admin role and is not suspended
+func (s *AdminService) IsAdmin(ctx context.Context, userID string) (bool, error) {
+	user, err := s.client.User.FindUnique(
+		db.User.ID.Equals(userID),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	return user.Role == db.UserRoleAdmin && !isSuspended(user), nil
+}
+
+// ListUsers returns a page of users, newest first
+func (s *AdminService) ListUsers(ctx context.Context, query models.ListUsersQuery) (*models.AdminUserListResponse, error) {
+	query.Page, query.Limit = normalizeAdminPage(query.Page, query.Limit)
+
+	var where []db.UserWhereParam
+	if search := strings.TrimSpace(query.Search); search != "" {
+		where = append(where, db.User.Or(
+			db.User.And(db.User.Name.Contains(search), db.User.Name.Mode(db.QueryModeInsensitive)),
+			db.User.And(db.User.Email.Contains(search), db.User.Email.Mode(db.QueryModeInsensitive)),
+		))
+	}
+	if query.Role != "" {
+		role, err := parseUserRole(query.Role)
+		if err != nil {
+			return nil, err
+		}
+		where = append(where, db.User.Role.Equals(role))
+	}
+	if query.Suspended != nil {
+		if *query.Suspended {
+			where = append(where, db.User.Not(db.User.SuspendedAt.IsNull()))
+		} else {
+			where = append(where, db.User.SuspendedAt.IsNull())
+		}
+	}
+
+	users, err := s.client.User.FindMany(
+		where...,
+	).OrderBy(
+		db.User.CreatedAt.Order(db.SortOrderDesc),
+	).Skip(
+		(query.Page - 1) * query.Limit,
+	).Take(
+		query.Limit + 1,
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch users: %w", err)
+	}
+
+	hasMore := len(users) > query.Limit
+	if hasMore {
+		users = users[:query.Limit]
+	}
+
+	response := &models.AdminUserListResponse{
+		Users:   []models.AdminUserResponse{},
+		Page:    query.Page,
+		Limit:   query.Limit,
+		HasMore: hasMore,
+	}
+	for i := range users {
+		response.Users = append(response.Users, toAdminUserResponse(&users[i]))
+	}
+
+	return response, nil
+}
+
+// GetUser returns a single user
+func (s *AdminService) GetUser(ctx context.Context, userID string) (*models.AdminUserResponse, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toAdminUserResponse(user)
+	return &response, nil
+}
+
+// UpdateDailyLimit changes how many emails the user may send per day
+func (s *AdminService) UpdateDailyLimit(ctx context.Context, adminID string, userID string, req models.UpdateDailyLimitRequest) (*models.AdminUserResponse, error) {
+	if req.DailyLimit < 1 || req.DailyLimit > maxDailyLimit {
+		return nil, fmt.Errorf("%w: daily limit must be between 1 and %d", ErrInvalidAdminRequest, maxDailyLimit)
+	}
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.client.User.FindUnique(
+		db.User.ID.Equals(user.ID),
+	).Update(
+		db.User.DailyLimit.Set(req.DailyLimit),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update daily limit: %w", err)
+	}
+
+	s.audit(ctx, adminID, user.ID, db.AdminActionDailyLimitChanged, req.Reason, map[string]interface{}{
+		"from": user.DailyLimit,
+		"to":   req.DailyLimit,
+	})
+
+	response := toAdminUserResponse(updated)
+	return &response, nil
+}
+
+// UpdateRole grants or revokes the admin role. Admins cannot demote themselves
+// so the last admin cannot lock everyone out by accident.
+func (s *AdminService) UpdateRole(ctx context.Context, adminID string, userID string, req models.UpdateUserRoleRequest) (*models.AdminUserResponse, error) {
+	role, err := parseUserRole(req.Role)
+	if err != nil {
+		return nil, err
+	}
+	if adminID == userID {
+		return nil, ErrCannotTargetSelf
+	}
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == role {
+		response := toAdminUserResponse(user)
+		return &response, nil
+	}
+
+	updated, err := s.client.User.FindUnique(
+		db.User.ID.Equals(user.ID),
+	).Update(
+		db.User.Role.Set(role),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+
+	s.audit(ctx, adminID, user.ID, db.AdminActionRoleChanged, req.Reason, map[string]interface{}{
+		"from": formatUserRole(user.Role),
+		"to":   formatUserRole(role),
+	})
+
+	response := toAdminUserResponse(updated)
+	return &response, nil
+}
+
+// Suspend blocks the user from logging in and ends all of their sessions.
+// Their API keys stop working until the account is unsuspended. In the same
+// transaction their running campaigns are paused; the scheduler holds their
+// campaigns and follow-ups until they are unsuspended.
+func (s *AdminService) Suspend(ctx context.Context, adminID string, userID string, req models.AdminReasonRequest) (*models.AdminUserResponse, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidAdminRequest)
+	}
+	if adminID == userID {
+		return nil, ErrCannotTargetSelf
+	}
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == db.UserRoleAdmin {
+		return nil, ErrCannotTargetAdmin
+	}
+	if isSuspended(user) {
+		response := toAdminUserResponse(user)
+		return &response, nil
+	}
+
+	now := time.Now()
+	suspend := s.client.User.FindUnique(
+		db.User.ID.Equals(user.ID),
+	).Update(
+		db.User.SuspendedAt.Set(now),
+		db.User.SuspensionReason.Set(reason),
+	).Tx()
+
+	// Runners stop after their current email; paused campaigns are due again
+	// as soon as the account is unsuspended. Follow-ups are held by the
+	// scheduler while the account is suspended and continue afterwards.
+	pauseCampaigns := s.client.Campaign.FindMany(
+		db.Campaign.UserID.Equals(user.ID),
+		db.Campaign.Status.Equals(db.CampaignStatusRunning),
+	).Update(
+		db.Campaign.Status.Set(db.CampaignStatusPaused),
+		db.Campaign.NextRunAt.Set(now),
+	).Tx()
+
+	if err := s.client.Prisma.Transaction(suspend, pauseCampaigns).Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to suspend user: %w", err)
+	}
+	updated := suspend.Result()
+
+	if err := s.sessions.RevokeAll(ctx, user.ID); err != nil {
+		fmt.Printf("Failed to revoke sessions of suspended user %s: %v\n", user.ID, err)
+	}
+
+	s.audit(ctx, adminID, user.ID, db.AdminActionUserSuspended, reason, map[string]interface{}{
+		"campaigns_paused": pauseCampaigns.Result().Count,
+	})
+	s.activities.Record(ctx, user.ID, ActivityEvent{
+		Type:        db.ActivityTypeAccountSuspended,
+		Description: "Account suspended by an administrator",
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    user.ID,
+	})
+
+	response := toAdminUserResponse(updated)
+	return &response, nil
+}
+
+// Unsuspend lets a suspended user log in again
+func (s *AdminService) Unsuspend(ctx context.Context, adminID string, userID string, req models.AdminReasonRequest) (*models.AdminUserResponse, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isSuspended(user) {
+		response := toAdminUserResponse(user)
+		return &response, nil
+	}
+
+	updated, err := s.client.User.FindUnique(
+		db.User.ID.Equals(user.ID),
+	).Update(
+		db.User.SuspendedAt.SetOptional(nil),
+		db.User.SuspensionReason.SetOptional(nil),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unsuspend user: %w", err)
+	}
+
+	s.audit(ctx, adminID, user.ID, db.AdminActionUserUnsuspended, strings.TrimSpace(req.Reason), nil)
+	s.activities.Record(ctx, user.ID, ActivityEvent{
+		Type:        db.ActivityTypeAccountUnsuspended,
+		Description: "Account suspension lifted by an administrator",
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    user.ID,
+	})
+
+	response := toAdminUserResponse(updated)
+	return &response, nil
+}
+
+// Impersonate starts a short-lived session as the user for support. The
+// session is marked as impersonated in the user's session list, the user's
+// activity log shows it and the admin audit log records who started it and why.
+func (s *AdminService) Impersonate(ctx context.Context, adminID string, userID string, req models.AdminReasonRequest, client models.ClientInfo) (*models.ImpersonationResponse, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidAdminRequest)
+	}
+	if adminID == userID {
+		return nil, ErrCannotTargetSelf
+	}
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == db.UserRoleAdmin {
+		return nil, ErrCannotTargetAdmin
+	}
+	if isSuspended(user) {
+		return nil, ErrAccountSuspended
+	}
+
+	tokens, err := s.sessions.IssueImpersonation(ctx, user.ID, user.Email, adminID, impersonationExpiry, client)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, adminID, user.ID, db.AdminActionImpersonationStarted, reason, map[string]interface{}{
+		"ip_address": client.IPAddress,
+	})
+	s.activities.Record(ctx, user.ID, ActivityEvent{
+		Type:        db.ActivityTypeImpersonatedByAdmin,
+		Description: "An administrator signed in to your account for support",
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    user.ID,
+		Metadata: map[string]interface{}{
+			"reason": reason,
+		},
+	})
+
+	return &models.ImpersonationResponse{
+		TokenResponse:    *tokens,
+		UserID:           user.ID,
+		SessionExpiresAt: time.Now().Add(impersonationExpiry),
+	}, nil
+}
+
+// ListCampaigns returns a page of campaigns across all users, newest first
+func (s *AdminService) ListCampaigns(ctx context.Context, query models.ListCampaignsQuery) (*models.AdminCampaignListResponse, error) {
+	query.Page, query.Limit = normalizeAdminPage(query.Page, query.Limit)
+
+	var where []db.CampaignWhereParam
+	if query.UserID != "" {
+		where = append(where, db.Campaign.UserID.Equals(query.UserID))
+	}
+	if query.Status != "" {
+		status, err := parseCampaignStatus(query.Status)
+		if err != nil {
+			return nil, err
+		}
+		where = append(where, db.Campaign.Status.Equals(status))
+	}
+
+	campaigns, err := s.client.Campaign.FindMany(
+		where...,
+	).With(
+		db.Campaign.User.Fetch(),
+	).OrderBy(
+		db.Campaign.StartedAt.Order(db.SortOrderDesc),
+	).Skip(
+		(query.Page - 1) * query.Limit,
+	).Take(
+		query.Limit + 1,
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch campaigns: %w", err)
+	}
+
+	hasMore := len(campaigns) > query.Limit
+	if hasMore {
+		campaigns = campaigns[:query.Limit]
+	}
+
+	response := &models.AdminCampaignListResponse{
+		Campaigns: []models.AdminCampaignResponse{},
+		Page:      query.Page,
+		Limit:     query.Limit,
+		HasMore:   hasMore,
+	}
+	for i := range campaigns {
+		response.Campaigns = append(response.Campaigns, toAdminCampaignResponse(&campaigns[i]))
+	}
+
+	return response, nil
+}
+
+// GetCampaign returns a campaign with its failed sends
+func (s *AdminService) GetCampaign(ctx context.Context, campaignID string) (*models.AdminCampaignDetailResponse, error) {
+	campaign, err := s.client.Campaign.FindUnique(
+		db.Campaign.ID.Equals(campaignID),
+	).With(
+		db.Campaign.User.Fetch(),
+		db.Campaign.EmailLogs.Fetch(
+			db.EmailLog.Status.Equals(db.EmailStatusFailed),
+		).OrderBy(
+			db.EmailLog.CreatedAt.Order(db.SortOrderDesc),
+		).Take(maxAdminPageSize),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrCampaignNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch campaign: %w", err)
+	}
+
+	response := &models.AdminCampaignDetailResponse{
+		AdminCampaignResponse: toAdminCampaignResponse(campaign),
+		Failures:              []models.EmailFailureResponse{},
+	}
+	failures := campaign.EmailLogs()
+	for i := range failures {
+		response.Failures = append(response.Failures, toEmailFailureResponse(&failures[i]))
+	}
+
+	return response, nil
+}
+
+// ListEmailFailures returns a page of failed sends across all users, newest first
+func (s *AdminService) ListEmailFailures(ctx context.Context, query models.ListEmailFailuresQuery) (*models.EmailFailureListResponse, error) {
+	query.Page, query.Limit = normalizeAdminPage(query.Page, query.Limit)
+
+	where := []db.EmailLogWhereParam{
+		db.EmailLog.Status.Equals(db.EmailStatusFailed),
+	}
+	if query.UserID != "" {
+		where = append(where, db.EmailLog.UserID.Equals(query.UserID))
+	}
+	if query.CampaignID != "" {
+		where = append(where, db.EmailLog.CampaignID.Equals(query.CampaignID))
+	}
+
+	logs, err := s.client.EmailLog.FindMany(
+		where...,
+	).OrderBy(
+		db.EmailLog.CreatedAt.Order(db.SortOrderDesc),
+	).Skip(
+		(query.Page - 1) * query.Limit,
+	).Take(
+		query.Limit + 1,
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch email failures: %w", err)
+	}
+
+	hasMore := len(logs) > query.Limit
+	if hasMore {
+		logs = logs[:query.Limit]
+	}
+
+	response := &models.EmailFailureListResponse{
+		Failures: []models.EmailFailureResponse{},
+		Page:     query.Page,
+		Limit:    query.Limit,
+		HasMore:  hasMore,
+	}
+	for i := range logs {
+		response.Failures = append(response.Failures, toEmailFailureResponse(&logs[i]))
+	}
+
+	return response, nil
+}
+
+// ListAuditLog returns a page of admin actions, newest first
+func (s *AdminService) ListAuditLog(ctx context.Context, query models.ListAuditLogQuery) (*models.AuditLogListResponse, error) {
+	query.Page, query.Limit = normalizeAdminPage(query.Page, query.Limit)
+
+	var where []db.AdminAuditLogWhereParam
+	if query.AdminID != "" {
+		where = append(where, db.AdminAuditLog.AdminID.Equals(query.AdminID))
+	}
+	if query.TargetUserID != "" {
+		where = append(where, db.AdminAuditLog.TargetUserID.Equals(query.TargetUserID))
+	}
+
+	entries, err := s.client.AdminAuditLog.FindMany(
+		where...,
+	).OrderBy(
+		db.AdminAuditLog.CreatedAt.Order(db.SortOrderDesc),
+	).Skip(
+		(query.Page - 1) * query.Limit,
+	).Take(
+		query.Limit + 1,
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch audit log: %w", err)
+	}
+
+	hasMore := len(entries) > query.Limit
+	if hasMore {
+		entries = entries[:query.Limit]
+	}
+
+	response := &models.AuditLogListResponse{
+		Entries: []models.AuditLogEntryResponse{},
+		Page:    query.Page,
+		Limit:   query.Limit,
+		HasMore: hasMore,
+	}
+	for i := range entries {
+		response.Entries = append(response.Entries, toAuditLogEntryResponse(&entries[i]))
+	}
+
+	return response, nil
+}
+
+// SetRole assigns a role by email without an acting admin. It is used by the
+// admin command to bootstrap the first administrator.
+func (s *AdminService) SetRole(ctx context.Context, email string, role string) error {
+	userRole, err := parseUserRole(role)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.User.FindUnique(
+		db.User.Email.Equals(strings.TrimSpace(email)),
+	).Update(
+		db.User.Role.Set(userRole),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+
+	return nil
+}
+
+// audit writes an entry to the admin audit log. Like activities, a failed
+// write is printed rather than failing the action.
+func (s *AdminService) audit(ctx context.Context, adminID string, targetUserID string, action db.AdminAction, reason string, metadata map[string]interface{}) {
+	var params []db.AdminAuditLogSetParam
+	if reason != "" {
+		params = append(params, db.AdminAuditLog.Reason.Set(reason))
+	}
+	if len(metadata) > 0 {
+		encoded, err := json.Marshal(metadata)
+		if err != nil {
+			fmt.Printf("Failed to encode audit metadata: %v\n", err)
+		} else {
+			params = append(params, db.AdminAuditLog.Metadata.Set(encoded))
+		}
+	}
+
+	_, err := s.client.AdminAuditLog.CreateOne(
+		db.AdminAuditLog.Action.Set(action),
+		db.AdminAuditLog.Admin.Link(db.User.ID.Equals(adminID)),
+		db.AdminAuditLog.TargetUser.Link(db.User.ID.Equals(targetUserID)),
+		params...,
+	).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to record %s audit entry by admin %s: %v\n", action, adminID, err)
+	}
+}
+
+// findUser fetches a user by ID for an admin action
+func (s *AdminService) findUser(ctx context.Context, userID string) (*db.UserModel, error) {
+	user, err := s.client.User.FindUnique(
+		db.User.ID.Equals(userID),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	return user, nil
+}
+
+// isSuspended reports whether an admin has suspended the account
+func isSuspended(user *db.UserModel) bool {
+	_, suspended := user.SuspendedAt()
+	return suspended
+}
+
+// normalizeAdminPage applies the default and maximum page size
+func normalizeAdminPage(page int, limit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultAdminPageSize
+	}
+	if limit > maxAdminPageSize {
+		limit = maxAdminPageSize
+	}
+	return page, limit
+}
+
+// parseUserRole converts a client supplied role (e.g. "admin") into a UserRole
+func parseUserRole(value string) (db.UserRole, error) {
+	role := db.UserRole(strings.ToUpper(strings.TrimSpace(value)))
+	switch role {
+	case db.UserRoleUser, db.UserRoleAdmin:
+		return role, nil
+	}
+
+	return "", fmt.Errorf("%w: unknown role %q", ErrInvalidAdminRequest, value)
+}
+
+func formatUserRole(role db.UserRole) string {
+	return strings.ToLower(string(role))
+}
+
+// parseCampaignStatus converts a client supplied status (e.g. "running") into a CampaignStatus
+func parseCampaignStatus(value string) (db.CampaignStatus, error) {
+	status := db.CampaignStatus(strings.ToUpper(strings.TrimSpace(value)))
+	switch status {
+	case db.CampaignStatusScheduled, db.CampaignStatusRunning, db.CampaignStatusPaused, db.CampaignStatusCompleted:
+		return status, nil
+	}
+
+	return "", fmt.Errorf("%w: unknown campaign status %q", ErrInvalidAdminRequest, value)
+}
+
+func toAdminUserResponse(u *db.UserModel) models.AdminUserResponse {
+	response := models.AdminUserResponse{
+		ID:             u.ID,
+		Name:           u.Name,
+		Email:          u.Email,
+		Role:           formatUserRole(u.Role),
+		EmailVerified:  isEmailVerified(u),
+		MFAEnabled:     isMFAEnabled(u),
+		DailyLimit:     u.DailyLimit,
+		EmailsSent:     u.EmailsSent,
+		PdfUploadCount: u.PdfUploadCount,
+		CreatedAt:      u.CreatedAt,
+	}
+	if v, ok := u.SuspendedAt(); ok {
+		response.SuspendedAt = &v
+	}
+	if v, ok := u.SuspensionReason(); ok {
+		response.SuspensionReason = v
+	}
+	if v, ok := u.LockedUntil(); ok && v.After(time.Now()) {
+		response.LockedUntil = &v
+	}
+
+	return response
+}
+
+func toAdminCampaignResponse(c *db.CampaignModel) models.AdminCampaignResponse {
+	return models.AdminCampaignResponse{
+		CampaignResponse: *toCampaignResponse(c),
+		UserID:           c.UserID,
+		UserEmail:        c.User().Email,
+		OrganizationID:   c.OrganizationID,
+	}
+}
+
+func toEmailFailureResponse(l *db.EmailLogModel) models.EmailFailureResponse {
+	response := models.EmailFailureResponse{
+		ID:        l.ID,
+		UserID:    l.UserID,
+		Recipient: l.Recipient,
+		Subject:   l.Subject,
+		CreatedAt: l.CreatedAt,
+	}
+	if v, ok := l.Error(); ok {
+		response.Error = v
+	}
+	if v, ok := l.ContactID(); ok {
+		response.ContactID = v
+	}
+	if v, ok := l.CampaignID(); ok {
+		response.CampaignID = v
+	}
+
+	return response
+}
+
+func toAuditLogEntryResponse(e *db.AdminAuditLogModel) models.AuditLogEntryResponse {
+	response := models.AuditLogEntryResponse{
+		ID:           e.ID,
+		Action:       strings.ToLower(string(e.Action)),
+		AdminID:      e.AdminID,
+		TargetUserID: e.TargetUserID,
+		CreatedAt:    e.CreatedAt,
+	}
+	if v, ok := e.Reason(); ok {
+		response.Reason = v
+	}
+	if v, ok := e.Metadata(); ok {
+		response.Metadata = json.RawMessage(v)
+	}
+
+	return response
+}
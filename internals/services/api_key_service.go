@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/internals/utils"
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+)
+
+const (
+	// maxAPIKeysPerUser limits how many active keys a user can hold
+	maxAPIKeysPerUser = 20
+	// apiKeyLastUsedInterval limits how often a key's last used time is written
+	apiKeyLastUsedInterval = time.Minute
+)
+
+// ErrAPIKeyNotFound is returned when a key does not exist, is revoked or belongs to another user
+var ErrAPIKeyNotFound = errors.New("API key not found")
+
+// ErrInvalidAPIKey is returned when a presented key is unknown, revoked or expired
+var ErrInvalidAPIKey = errors.New("invalid or expired API key")
+
+// ErrInvalidAPIKeyRequest is returned when the name, scopes or expiry of a new key are invalid
+var ErrInvalidAPIKeyRequest = errors.New("invalid API key request")
+
+// ErrTooManyAPIKeys is returned when the user already has the maximum number of active keys
+var ErrTooManyAPIKeys = fmt.Errorf("a user can have at most %d active API keys", maxAPIKeysPerUser)
+
+// APIKeyService handles personal API keys used by scripts
+type APIKeyService struct {
+	client     *db.PrismaClient
+	activities *ActivityService
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(client *db.PrismaClient) *APIKeyService {
+	return &APIKeyService{
+		client:     client,
+		activities: NewActivityService(client),
+	}
+}
+
+// CreateAPIKey creates a key with the requested scopes. The full key is only
+// part of this response; afterwards only its prefix is known.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, userID string, req models.CreateAPIKeyRequest) (*models.CreateAPIKeyResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidAPIKeyRequest)
+	}
+
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := s.client.APIKey.FindMany(
+		db.APIKey.UserID.Equals(userID),
+		db.APIKey.RevokedAt.IsNull(),
+		db.APIKey.Or(
+			db.APIKey.ExpiresAt.IsNull(),
+			db.APIKey.ExpiresAt.After(time.Now()),
+		),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch API keys: %w", err)
+	}
+	if len(active) >= maxAPIKeysPerUser {
+		return nil, ErrTooManyAPIKeys
+	}
+
+	key, prefix, keyHash, err := utils.GenerateAPIKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	params := []db.APIKeySetParam{db.APIKey.Scopes.Set(scopes)}
+	if req.ExpiresInDays != nil {
+		if *req.ExpiresInDays < 1 {
+			return nil, fmt.Errorf("%w: expires_in_days must be at least 1", ErrInvalidAPIKeyRequest)
+		}
+		params = append(params, db.APIKey.ExpiresAt.Set(time.Now().AddDate(0, 0, *req.ExpiresInDays)))
+	}
+
+	apiKey, err := s.client.APIKey.CreateOne(
+		db.APIKey.Name.Set(name),
+		db.APIKey.Prefix.Set(prefix),
+		db.APIKey.KeyHash.Set(keyHash),
+		db.APIKey.User.Link(db.User.ID.Equals(userID)),
+		params...,
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeAPIKeyCreated,
+		Description: "Created API key " + name,
+		TargetType:  models.ActivityTargetAPIKey,
+		TargetID:    apiKey.ID,
+		Metadata: map[string]interface{}{
+			"prefix": prefix,
+			"scopes": scopes,
+		},
+	})
+
+	return &models.CreateAPIKeyResponse{
+		APIKeyResponse: toAPIKeyResponse(apiKey),
+		Key:            key,
+	}, nil
+}
+
+// ListAPIKeys returns the user's keys that are not revoked, newest first
+func (s *APIKeyService) ListAPIKeys(ctx context.Context, userID string) ([]models.APIKeyResponse, error) {
+	keys, err := s.client.APIKey.FindMany(
+		db.APIKey.UserID.Equals(userID),
+		db.APIKey.RevokedAt.IsNull(),
+	).OrderBy(
+		db.APIKey.CreatedAt.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch API keys: %w", err)
+	}
+
+	response := []models.APIKeyResponse{}
+	for i := range keys {
+		response = append(response, toAPIKeyResponse(&keys[i]))
+	}
+
+	return response, nil
+}
+
+// RevokeAPIKey revokes one of the user's keys; it stops working immediately
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, userID string, keyID string) error {
+	result, err := s.client.APIKey.FindMany(
+		db.APIKey.ID.Equals(keyID),
+		db.APIKey.UserID.Equals(userID),
+		db.APIKey.RevokedAt.IsNull(),
+	).Update(
+		db.APIKey.RevokedAt.Set(time.Now()),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if result.Count == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeAPIKeyRevoked,
+		Description: "Revoked API key",
+		TargetType:  models.ActivityTargetAPIKey,
+		TargetID:    keyID,
+	})
+
+	return nil
+}
+
+// ValidateAPIKey resolves a presented key to its owner and scopes
+func (s *APIKeyService) ValidateAPIKey(ctx context.Context, key string) (*models.APIKeyPrincipal, error) {
+	apiKey, err := s.client.APIKey.FindUnique(
+		db.APIKey.KeyHash.Equals(utils.HashOpaqueToken(key)),
+	).With(
+		db.APIKey.User.Fetch(),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("failed to fetch API key: %w", err)
+	}
+
+	if _, revoked := apiKey.RevokedAt(); revoked {
+		return nil, ErrInvalidAPIKey
+	}
+	if expiresAt, ok := apiKey.ExpiresAt(); ok && expiresAt.Before(time.Now()) {
+		return nil, ErrInvalidAPIKey
+	}
+	if isSuspended(apiKey.User()) {
+		return nil, ErrAccountSuspended
+	}
+
+	// Track usage without writing on every request
+	lastUsedAt, used := apiKey.LastUsedAt()
+	if !used || lastUsedAt.Before(time.Now().Add(-apiKeyLastUsedInterval)) {
+		_, err = s.client.APIKey.FindUnique(
+			db.APIKey.ID.Equals(apiKey.ID),
+		).Update(
+			db.APIKey.LastUsedAt.Set(time.Now()),
+		).Exec(ctx)
+		if err != nil {
+			fmt.Printf("Failed to update last used for API key %s: %v\n", apiKey.ID, err)
+		}
+	}
+
+	return &models.APIKeyPrincipal{
+		KeyID:  apiKey.ID,
+		UserID: apiKey.UserID,
+		Email:  apiKey.User().Email,
+		Scopes: apiKey.Scopes,
+	}, nil
+}
+
+// normalizeScopes validates requested scopes and removes duplicates
+func normalizeScopes(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKeyRequest)
+	}
+
+	seen := map[string]bool{}
+	scopes := []string{}
+	for _, scope := range requested {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if seen[scope] {
+			continue
+		}
+
+		known := false
+		for _, s := range models.APIKeyScopes {
+			if s == scope {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIKeyRequest, scope)
+		}
+
+		seen[scope] = true
+		scopes = append(scopes, scope)
+	}
+
+	return scopes, nil
+}
+
+func toAPIKeyResponse(k *db.APIKeyModel) models.APIKeyResponse {
+	response := models.APIKeyResponse{
+		ID:        k.ID,
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		Scopes:    k.Scopes,
+		CreatedAt: k.CreatedAt,
+	}
+	if v, ok := k.ExpiresAt(); ok {
+		response.ExpiresAt = &v
+	}
+	if v, ok := k.LastUsedAt(); ok {
+		response.LastUsedAt = &v
+	}
+
+	return response
+}
@@ -4,26 +4,72 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/satyam-svg/hr-message-backend/internals/models"
 	"github.com/satyam-svg/hr-message-backend/internals/utils"
 	"github.com/satyam-svg/hr-message-backend/prisma/db"
 )
 
+const (
+	// passwordResetExpiry is how long an emailed reset link stays valid
+	passwordResetExpiry = time.Hour
+	// emailVerificationExpiry is how long an emailed verification link stays valid
+	emailVerificationExpiry = 24 * time.Hour
+	// verificationResendInterval and maxVerificationEmailsPerHour rate limit resends
+	verificationResendInterval   = time.Minute
+	maxVerificationEmailsPerHour = 5
+	// passwordResetInterval and maxPasswordResetEmailsPerHour rate limit reset links
+	passwordResetInterval         = time.Minute
+	maxPasswordResetEmailsPerHour = 5
+	// mfaChallengeExpiry is how long the second login step may take
+	mfaChallengeExpiry = 5 * time.Minute
+	// maxMFAChallengeAttempts is how many wrong codes one login challenge accepts
+	maxMFAChallengeAttempts = 5
+)
+
+// ErrIncorrectPassword is returned when the current password does not match
+var ErrIncorrectPassword = errors.New("current password is incorrect")
+
+// ErrEmailTaken is returned when another account already uses the requested email
+var ErrEmailTaken = errors.New("user with this email already exists")
+
+// ErrSameEmail is returned when the requested email equals the current one
+var ErrSameEmail = errors.New("new email must differ from the current email")
+
+// ErrEmailAlreadyVerified is returned when verification is requested for a verified account
+var ErrEmailAlreadyVerified = errors.New("email is already verified")
+
+// ErrVerificationRateLimited is returned when verification emails are requested too often
+var ErrVerificationRateLimited = errors.New("too many verification emails requested, please try again later")
+
 // AuthService handles authentication business logic
 type AuthService struct {
-	client *db.PrismaClient
+	client     *db.PrismaClient
+	activities *ActivityService
+	sessions   *SessionService
+	tokens     *AuthTokenService
+	mfa        *MFAService
+	guard      *LoginGuardService
+	mailer     *SystemMailer
 }
 
 // NewAuthService creates a new auth service
 func NewAuthService(client *db.PrismaClient) *AuthService {
 	return &AuthService{
-		client: client,
+		client:     client,
+		activities: NewActivityService(client),
+		sessions:   NewSessionService(client),
+		tokens:     NewAuthTokenService(client),
+		mfa:        NewMFAService(client),
+		guard:      NewLoginGuardService(client),
+		mailer:     NewSystemMailer(),
 	}
 }
 
 // Signup registers a new user with concurrent password hashing
-func (s *AuthService) Signup(ctx context.Context, req models.SignupRequest) (*models.AuthResponse, error) {
+func (s *AuthService) Signup(ctx context.Context, req models.SignupRequest, client models.ClientInfo) (*models.AuthResponse, error) {
 	// Channel for concurrent operations
 	type result struct {
 		data interface{}
@@ -65,6 +111,44 @@ func (s *AuthService) Signup(ctx context.Context, req models.SignupRequest) (*mo
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	s.activities.Record(ctx, user.ID, ActivityEvent{
+		Type:        db.ActivityTypeSignup,
+		Description: "Signed up",
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    user.ID,
+	})
+
+	s.setupWorkspace(ctx, user)
+
+	// The account works right away, but sending stays blocked until the email is verified
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		fmt.Printf("Failed to send verification email to %s: %v\n", user.Email, err)
+	}
+
+	// Start a session and issue its access and refresh tokens
+	tokens, err := s.sessions.Issue(ctx, user.ID, user.Email, client)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare response
+	response := &models.AuthResponse{
+		User: models.UserResponse{
+			ID:            user.ID,
+			Email:         user.Email,
+			Name:          user.Name,
+			EmailVerified: isEmailVerified(user),
+			CreatedAt:     user.CreatedAt,
+		},
+		TokenResponse: *tokens,
+	}
+
+	return response, nil
+}
+
+// setupWorkspace creates a new user's personal organization and default
+// template. Failures are logged so they never block the signup.
+func (s *AuthService) setupWorkspace(ctx context.Context, user *db.UserModel) {
 	// Create default email template
 	defaultSubject := "Application for {Position} at {Company}"
 	defaultBody := `Dear {Hiring Manager Name},
@@ -115,170 +199,600 @@ Portfolio: https://iampraveen.vercel.app
 
 LinkedIn: https://www.linkedin.com/in/praveen-maurya-5aa355214/`
 
-	_, err = s.client.Template.CreateOne(
-		db.Template.Name.Set("Default Template"),
-		db.Template.Subject.Set(defaultSubject),
-		db.Template.Body.Set(defaultBody),
-		db.Template.User.Link(db.User.ID.Equals(user.ID)),
+	// Every user works in a personal organization until they join a team.
+	// If it can't be created now, it is created on first use instead.
+	membership, err := createOrganization(ctx, s.client, user.ID, user.Name+"'s workspace", true)
+	if err != nil {
+		fmt.Printf("Failed to create personal organization: %v\n", err)
+	} else {
+		_, err = s.client.Template.CreateOne(
+			db.Template.Name.Set("Default Template"),
+			db.Template.Subject.Set(defaultSubject),
+			db.Template.Body.Set(defaultBody),
+			db.Template.User.Link(db.User.ID.Equals(user.ID)),
+			db.Template.Organization.Link(db.Organization.ID.Equals(membership.OrganizationID)),
+		).Exec(ctx)
+
+		if err != nil {
+			// Log error but don't fail signup (optional: could delete user and fail)
+			fmt.Printf("Failed to create default template: %v\n", err)
+		}
+	}
+}
+
+// Login authenticates a user with concurrent password verification. Accounts
+// with two-factor authentication get an MFA challenge instead of tokens, which
+// is completed with CompleteMFALogin.
+func (s *AuthService) Login(ctx context.Context, req models.LoginRequest, client models.ClientInfo) (*models.AuthResponse, *models.MFAChallengeResponse, error) {
+	// Find user by email
+	user, err := s.client.User.FindUnique(
+		db.User.Email.Equals(req.Email),
 	).Exec(ctx)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return nil, nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
 
-	if err != nil {
-		// Log error but don't fail signup (optional: could delete user and fail)
-		fmt.Printf("Failed to create default template: %v\n", err)
+	// Refuse early while the IP or account has to wait after failed attempts
+	if err := s.guard.Check(ctx, user, client.IPAddress); err != nil {
+		return nil, nil, err
 	}
 
-	// Generate JWT token concurrently
-	tokenChan := make(chan result)
+	if user == nil {
+		s.guard.RecordFailure(ctx, req.Email, nil, client.IPAddress)
+		return nil, nil, errors.New("invalid email or password")
+	}
+
+	// Verify password concurrently
+	passwordChan := make(chan error)
 	go func() {
-		token, err := utils.GenerateToken(user.ID, user.Email)
-		tokenChan <- result{data: token, err: err}
+		err := utils.ComparePassword(user.Password, req.Password)
+		passwordChan <- err
 	}()
 
-	// Wait for token generation
-	tokenResult := <-tokenChan
-	if tokenResult.err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", tokenResult.err)
+	// Wait for password verification
+	if err := <-passwordChan; err != nil {
+		s.guard.RecordFailure(ctx, req.Email, user, client.IPAddress)
+		return nil, nil, errors.New("invalid email or password")
+	}
+
+	if isSuspended(user) {
+		return nil, nil, ErrAccountSuspended
+	}
+
+	return s.completeFirstFactor(ctx, user, client, nil)
+}
+
+// completeFirstFactor continues a login whose first factor was verified: it
+// returns an MFA challenge when two-factor authentication is enabled and
+// starts a session otherwise
+func (s *AuthService) completeFirstFactor(ctx context.Context, user *db.UserModel, client models.ClientInfo, metadata map[string]interface{}) (*models.AuthResponse, *models.MFAChallengeResponse, error) {
+	if isMFAEnabled(user) {
+		mfaToken, err := s.tokens.Issue(ctx, user.ID, db.AuthTokenPurposeMfaChallenge, mfaChallengeExpiry)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return nil, &models.MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			ExpiresAt:   time.Now().Add(mfaChallengeExpiry),
+		}, nil
+	}
+
+	response, err := s.startSession(ctx, user, client, metadata)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return response, nil, nil
+}
+
+// CompleteMFALogin finishes a login that required a second factor. The
+// challenge is invalidated after too many wrong codes.
+func (s *AuthService) CompleteMFALogin(ctx context.Context, req models.LoginMFARequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	challenge, err := s.tokens.Lookup(ctx, req.MFAToken, db.AuthTokenPurposeMfaChallenge)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.client.User.FindUnique(
+		db.User.ID.Equals(challenge.UserID),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	// Wrong codes count towards the same limits as wrong passwords, so
+	// requesting new challenges does not allow unlimited guessing
+	if err := s.guard.Check(ctx, user, client.IPAddress); err != nil {
+		return nil, err
+	}
+	if isSuspended(user) {
+		return nil, ErrAccountSuspended
 	}
 
-	token := tokenResult.data.(string)
+	factor, err := s.mfa.VerifyCode(ctx, user, req.Code)
+	if err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			s.guard.RecordFailure(ctx, user.Email, user, client.IPAddress)
+			if err := s.tokens.RecordFailedAttempt(ctx, challenge, maxMFAChallengeAttempts); err != nil {
+				fmt.Printf("Failed to record MFA attempt: %v\n", err)
+			}
+		}
+		return nil, err
+	}
+
+	// Consume the challenge so it cannot start a second session
+	if _, err := s.tokens.Redeem(ctx, req.MFAToken, db.AuthTokenPurposeMfaChallenge); err != nil {
+		return nil, err
+	}
+
+	return s.startSession(ctx, user, client, map[string]interface{}{
+		"mfa_factor": factor,
+	})
+}
+
+// UnlockAccount lifts a login lockout using the token from the lockout email
+func (s *AuthService) UnlockAccount(ctx context.Context, token string) error {
+	return s.guard.Unlock(ctx, token)
+}
+
+// startSession records the login and issues the session's access and refresh tokens
+func (s *AuthService) startSession(ctx context.Context, user *db.UserModel, client models.ClientInfo, metadata map[string]interface{}) (*models.AuthResponse, error) {
+	s.guard.RecordSuccess(ctx, user, client.IPAddress)
+
+	s.activities.Record(ctx, user.ID, ActivityEvent{
+		Type:        db.ActivityTypeLogin,
+		Description: "Logged in",
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    user.ID,
+		Metadata:    metadata,
+	})
+
+	// Start a session and issue its access and refresh tokens
+	tokens, err := s.sessions.Issue(ctx, user.ID, user.Email, client)
+	if err != nil {
+		return nil, err
+	}
 
 	// Prepare response
 	response := &models.AuthResponse{
 		User: models.UserResponse{
-			ID:        user.ID,
-			Email:     user.Email,
-			Name:      user.Name,
-			CreatedAt: user.CreatedAt,
+			ID:            user.ID,
+			Email:         user.Email,
+			Name:          user.Name,
+			EmailVerified: isEmailVerified(user),
+			CreatedAt:     user.CreatedAt,
 		},
-		Token: token,
+		TokenResponse: *tokens,
 	}
 
 	return response, nil
 }
 
-// Login authenticates a user with concurrent password verification
-func (s *AuthService) Login(ctx context.Context, req models.LoginRequest) (*models.AuthResponse, error) {
-	// Channel for concurrent operations
-	type result struct {
-		data interface{}
-		err  error
+// RefreshToken rotates a refresh token and returns a new token pair
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string, client models.ClientInfo) (*models.TokenResponse, error) {
+	return s.sessions.Refresh(ctx, refreshToken, client)
+}
+
+// ListSessions returns the user's active sessions
+func (s *AuthService) ListSessions(ctx context.Context, userID string, currentSessionID string) ([]models.SessionResponse, error) {
+	return s.sessions.ListSessions(ctx, userID, currentSessionID)
+}
+
+// RevokeSession logs the user out of one session
+func (s *AuthService) RevokeSession(ctx context.Context, userID string, sessionID string) error {
+	return s.sessions.RevokeSession(ctx, userID, sessionID)
+}
+
+// RevokeOtherSessions logs the user out everywhere except the current session
+func (s *AuthService) RevokeOtherSessions(ctx context.Context, userID string, currentSessionID string) (*models.RevokeSessionsResponse, error) {
+	return s.sessions.RevokeOtherSessions(ctx, userID, currentSessionID)
+}
+
+// Logout revokes the session the request was authenticated with
+func (s *AuthService) Logout(ctx context.Context, userID string, sessionID string) error {
+	if err := s.sessions.Revoke(ctx, userID, sessionID); err != nil {
+		return err
 	}
 
-	// Find user by email
-	user, err := s.client.User.FindUnique(
-		db.User.Email.Equals(req.Email),
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeLogout,
+		Description: "Logged out",
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    userID,
+	})
+
+	return nil
+}
+
+// ChangePassword replaces the user's password after checking the current one
+// and signs out every other session
+func (s *AuthService) ChangePassword(ctx context.Context, userID string, sessionID string, req models.ChangePasswordRequest) error {
+	user, err := s.verifyCurrentPassword(ctx, userID, req.CurrentPassword)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	_, err = s.client.User.FindUnique(
+		db.User.ID.Equals(user.ID),
+	).Update(
+		db.User.Password.Set(hashedPassword),
 	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if _, err := s.sessions.RevokeOtherSessions(ctx, user.ID, sessionID); err != nil {
+		return err
+	}
+
+	s.activities.Record(ctx, user.ID, ActivityEvent{
+		Type:        db.ActivityTypePasswordChanged,
+		Description: "Changed password",
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    user.ID,
+	})
+
+	return nil
+}
 
+// ChangeEmail changes the login email after checking the current password.
+// The new address must be verified again and every other session is signed out.
+func (s *AuthService) ChangeEmail(ctx context.Context, userID string, sessionID string, req models.ChangeEmailRequest) (*models.UserResponse, error) {
+	user, err := s.verifyCurrentPassword(ctx, userID, req.CurrentPassword)
 	if err != nil {
-		return nil, errors.New("invalid email or password")
+		return nil, err
 	}
 
-	// Verify password concurrently
-	passwordChan := make(chan error)
-	go func() {
-		err := utils.ComparePassword(user.Password, req.Password)
-		passwordChan <- err
-	}()
+	newEmail := strings.TrimSpace(req.NewEmail)
+	if strings.EqualFold(newEmail, user.Email) {
+		return nil, ErrSameEmail
+	}
 
-	// Wait for password verification
-	if err := <-passwordChan; err != nil {
-		return nil, errors.New("invalid email or password")
+	updated, err := s.client.User.FindUnique(
+		db.User.ID.Equals(user.ID),
+	).Update(
+		db.User.Email.Set(newEmail),
+		db.User.EmailVerifiedAt.SetOptional(nil),
+	).Exec(ctx)
+	if err != nil {
+		if _, ok := db.IsErrUniqueConstraint(err); ok {
+			return nil, ErrEmailTaken
+		}
+		return nil, fmt.Errorf("failed to update email: %w", err)
 	}
 
-	// Generate JWT token concurrently
-	tokenChan := make(chan result)
-	go func() {
-		token, err := utils.GenerateToken(user.ID, user.Email)
-		tokenChan <- result{data: token, err: err}
-	}()
+	if _, err := s.sessions.RevokeOtherSessions(ctx, user.ID, sessionID); err != nil {
+		return nil, err
+	}
 
-	// Wait for token generation
-	tokenResult := <-tokenChan
-	if tokenResult.err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", tokenResult.err)
+	if err := s.sendVerificationEmail(ctx, updated); err != nil {
+		fmt.Printf("Failed to send verification email to %s: %v\n", updated.Email, err)
 	}
 
-	token := tokenResult.data.(string)
+	// Let the previous address know in case the change was not made by the owner
+	notice := fmt.Sprintf(`Hi %s,
 
-	// Prepare response
-	response := &models.AuthResponse{
-		User: models.UserResponse{
-			ID:        user.ID,
-			Email:     user.Email,
-			Name:      user.Name,
-			CreatedAt: user.CreatedAt,
+The login email of your account was changed to %s. If you did not make this change, reset your password right away.`,
+		user.Name, updated.Email)
+	if err := s.mailer.Send(user.Email, "Your login email was changed", notice); err != nil {
+		fmt.Printf("Failed to notify %s of email change: %v\n", user.Email, err)
+	}
+
+	s.activities.Record(ctx, user.ID, ActivityEvent{
+		Type:        db.ActivityTypeEmailChanged,
+		Description: "Changed login email to " + updated.Email,
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    user.ID,
+		Metadata: map[string]interface{}{
+			"old_email": user.Email,
+			"new_email": updated.Email,
 		},
-		Token: token,
+	})
+
+	return &models.UserResponse{
+		ID:            updated.ID,
+		Email:         updated.Email,
+		Name:          updated.Name,
+		EmailVerified: isEmailVerified(updated),
+		CreatedAt:     updated.CreatedAt,
+	}, nil
+}
+
+// verifyCurrentPassword loads the user and checks their current password
+func (s *AuthService) verifyCurrentPassword(ctx context.Context, userID string, password string) (*db.UserModel, error) {
+	user, err := s.client.User.FindUnique(
+		db.User.ID.Equals(userID),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
 	}
 
-	return response, nil
+	if err := utils.ComparePassword(user.Password, password); err != nil {
+		return nil, ErrIncorrectPassword
+	}
+
+	return user, nil
+}
+
+// VerifyEmail marks the account email as verified using an emailed token
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	authToken, err := s.tokens.Redeem(ctx, token, db.AuthTokenPurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.client.User.FindUnique(
+		db.User.ID.Equals(authToken.UserID),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch user: %w", err)
+	}
+	if _, verified := user.EmailVerifiedAt(); verified {
+		return nil
+	}
+
+	_, err = s.client.User.FindUnique(
+		db.User.ID.Equals(user.ID),
+	).Update(
+		db.User.EmailVerifiedAt.Set(time.Now()),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	s.activities.Record(ctx, user.ID, ActivityEvent{
+		Type:        db.ActivityTypeEmailVerified,
+		Description: "Verified email " + user.Email,
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    user.ID,
+	})
+
+	return nil
+}
+
+// ResendVerification emails a new verification link. When rate limited it
+// returns ErrVerificationRateLimited and how long to wait.
+func (s *AuthService) ResendVerification(ctx context.Context, userID string) (time.Duration, error) {
+	user, err := s.client.User.FindUnique(
+		db.User.ID.Equals(userID),
+	).Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	if _, verified := user.EmailVerifiedAt(); verified {
+		return 0, ErrEmailAlreadyVerified
+	}
+
+	wait, err := s.tokens.RetryAfter(ctx, userID, db.AuthTokenPurposeEmailVerification, verificationResendInterval, maxVerificationEmailsPerHour)
+	if err != nil {
+		return 0, err
+	}
+	if wait > 0 {
+		return wait, ErrVerificationRateLimited
+	}
+
+	return 0, s.sendVerificationEmail(ctx, user)
+}
+
+// sendVerificationEmail issues a verification token and emails the link to the user
+func (s *AuthService) sendVerificationEmail(ctx context.Context, user *db.UserModel) error {
+	token, err := s.tokens.Issue(ctx, user.ID, db.AuthTokenPurposeEmailVerification, emailVerificationExpiry)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(`Hi %s,
+
+Please confirm your email address by opening the link below:
+
+%s
+
+The link expires in 24 hours. You can send emails to your contacts once your address is verified.`,
+		user.Name, appURL("/verify-email?token="+token))
+
+	return s.mailer.Send(user.Email, "Verify your email address", body)
+}
+
+// ForgotPassword emails a password reset link to the user. It does not
+// report whether the email belongs to an account, and quietly sends nothing
+// when links were requested too often.
+func (s *AuthService) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.client.User.FindUnique(
+		db.User.Email.Equals(email),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	// A new link invalidates the previous one, so flooding would also break it
+	wait, err := s.tokens.RetryAfter(ctx, user.ID, db.AuthTokenPurposePasswordReset, passwordResetInterval, maxPasswordResetEmailsPerHour)
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return nil
+	}
+
+	token, err := s.tokens.Issue(ctx, user.ID, db.AuthTokenPurposePasswordReset, passwordResetExpiry)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(`Hi %s,
+
+We received a request to reset your password. Open the link below to choose a new one:
+
+%s
+
+The link expires in %d minutes and can be used once. If you did not ask for a reset, you can ignore this email.`,
+		user.Name, appURL("/reset-password?token="+token), int(passwordResetExpiry.Minutes()))
+
+	// A failure is not returned, since only existing accounts get this far
+	if err := s.mailer.Send(user.Email, "Reset your password", body); err != nil {
+		fmt.Printf("Failed to send password reset email to %s: %v\n", user.Email, err)
+		return nil
+	}
+
+	s.activities.Record(ctx, user.ID, ActivityEvent{
+		Type:        db.ActivityTypePasswordResetRequested,
+		Description: "Requested a password reset",
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    user.ID,
+	})
+
+	return nil
+}
+
+// ResetPassword sets a new password using an emailed reset token and signs
+// the user out of every session
+func (s *AuthService) ResetPassword(ctx context.Context, token string, newPassword string) error {
+	authToken, err := s.tokens.Redeem(ctx, token, db.AuthTokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	_, err = s.client.User.FindUnique(
+		db.User.ID.Equals(authToken.UserID),
+	).Update(
+		db.User.Password.Set(hashedPassword),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if err := s.sessions.RevokeAll(ctx, authToken.UserID); err != nil {
+		return err
+	}
+
+	// The reset proves ownership of the mailbox, so any lockout is lifted too
+	if err := s.guard.clearFailures(ctx, authToken.UserID); err != nil {
+		fmt.Printf("Failed to clear failed logins: %v\n", err)
+	}
+
+	s.activities.Record(ctx, authToken.UserID, ActivityEvent{
+		Type:        db.ActivityTypePasswordReset,
+		Description: "Reset password",
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    authToken.UserID,
+	})
+
+	return nil
 }
 
 // UpdateEmailSettings updates the user's professional email and mail app password
 func (s *AuthService) UpdateEmailSettings(ctx context.Context, userID string, req models.UpdateEmailSettingsRequest) error {
-	_, err := s.client.User.FindUnique(
+	// The app password is stored encrypted and never returned to the client
+	encryptedPassword, err := utils.EncryptSecret(req.MailAppPassword)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt mail app password: %w", err)
+	}
+
+	_, err = s.client.User.FindUnique(
 		db.User.ID.Equals(userID),
 	).Update(
 		db.User.ProfessionalEmail.Set(req.ProfessionalEmail),
-		db.User.MailAppPassword.Set(req.MailAppPassword),
+		db.User.MailAppPassword.Set(encryptedPassword),
 	).Exec(ctx)
 
 	if err != nil {
 		return fmt.Errorf("failed to update email settings: %w", err)
 	}
 
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeSettingsUpdated,
+		Description: "Updated email settings",
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    userID,
+		Metadata: map[string]interface{}{
+			"professional_email": req.ProfessionalEmail,
+		},
+	})
+
+	return nil
+}
+
+// UpdateTimezone changes the time zone the send windows of new campaigns default to
+func (s *AuthService) UpdateTimezone(ctx context.Context, userID string, req models.UpdateTimezoneRequest) error {
+	timezone := strings.TrimSpace(req.Timezone)
+	if _, err := loadTimezone(timezone); err != nil {
+		return err
+	}
+
+	_, err := s.client.User.FindUnique(
+		db.User.ID.Equals(userID),
+	).Update(
+		db.User.Timezone.Set(timezone),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update time zone: %w", err)
+	}
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeSettingsUpdated,
+		Description: "Updated time zone",
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    userID,
+		Metadata: map[string]interface{}{
+			"timezone": timezone,
+		},
+	})
+
 	return nil
 }
 
-// GetProfile fetches the user's full profile including contacts and template
-func (s *AuthService) GetProfile(ctx context.Context, userID string) (*models.UserProfileResponse, error) {
+// GetProfile fetches the user's full profile including the contacts and
+// template of the selected organization. Activities are paginated separately
+// through ActivityService.ListActivities.
+func (s *AuthService) GetProfile(ctx context.Context, userID string, organizationID string) (*models.UserProfileResponse, error) {
 	user, err := s.client.User.FindUnique(
 		db.User.ID.Equals(userID),
-	).With(
-		db.User.Contacts.Fetch(),
-		db.User.Template.Fetch(),
-		db.User.Activities.Fetch(),
 	).Exec(ctx)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch user profile: %w", err)
 	}
 
+	membership, err := resolveMembership(ctx, s.client, userID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	organizationContacts, err := s.client.Contact.FindMany(
+		db.Contact.OrganizationID.Equals(membership.OrganizationID),
+		db.Contact.DeletedAt.IsNull(),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch contacts: %w", err)
+	}
+
 	// Map contacts
 	contacts := []models.ContactResponse{} // Initialize as empty array
-	for _, c := range user.Contacts() {
-		contacts = append(contacts, models.ContactResponse{
-			ID:          c.ID,
-			Name:        c.Name,
-			CompanyName: c.CompanyName,
-			Email:       c.Email,
-			IsSent:      c.IsSent,
-			CreatedAt:   c.CreatedAt,
-		})
-	}
-
-	// Map activities
-	activities := []models.ActivityResponse{}
-	for _, a := range user.Activities() {
-		activities = append(activities, models.ActivityResponse{
-			ID:          a.ID,
-			Description: a.Description,
-			CreatedAt:   a.CreatedAt,
-		})
+	for _, c := range organizationContacts {
+		contacts = append(contacts, toContactResponse(&c))
 	}
 
 	// Map template
 	var template *models.TemplateResponse
-	if t, ok := user.Template(); ok {
-		template = &models.TemplateResponse{
-			ID:        t.ID,
-			Name:      t.Name,
-			Subject:   t.Subject,
-			Body:      t.Body,
-			CreatedAt: t.CreatedAt,
-			UpdatedAt: t.UpdatedAt,
-		}
-	} else {
+	t, err := activeTemplate(ctx, s.client, membership.OrganizationID)
+	if err == nil {
+		response := toTemplateResponse(t)
+		template = &response
+	} else if errors.Is(err, ErrTemplateNotFound) {
 		// Create default template for existing users if missing
 		defaultSubject := "Application for {Position} at {Company}"
 		defaultBody := `Dear {Hiring Manager Name},
@@ -330,17 +844,12 @@ Resume: https://drive.google.com/file/d/129hWwlFE7KeGBNSuiWqbU5x9BB7gvrKl/view?u
 			db.Template.Subject.Set(defaultSubject),
 			db.Template.Body.Set(defaultBody),
 			db.Template.User.Link(db.User.ID.Equals(userID)),
+			db.Template.Organization.Link(db.Organization.ID.Equals(membership.OrganizationID)),
 		).Exec(ctx)
 
 		if err == nil {
-			template = &models.TemplateResponse{
-				ID:        newTemplate.ID,
-				Name:      newTemplate.Name,
-				Subject:   newTemplate.Subject,
-				Body:      newTemplate.Body,
-				CreatedAt: newTemplate.CreatedAt,
-				UpdatedAt: newTemplate.UpdatedAt,
-			}
+			response := toTemplateResponse(newTemplate)
+			template = &response
 		}
 	}
 
@@ -349,23 +858,44 @@ Resume: https://drive.google.com/file/d/129hWwlFE7KeGBNSuiWqbU5x9BB7gvrKl/view?u
 		professionalEmail = v
 	}
 
-	mailAppPassword := ""
-	if v, ok := user.MailAppPassword(); ok {
-		mailAppPassword = v
+	mailAppPasswordConfigured := false
+	if v, ok := user.MailAppPassword(); ok && v != "" {
+		mailAppPasswordConfigured = true
+	}
+
+	mailOAuthEmail := ""
+	if v, ok := user.MailOAuthEmail(); ok {
+		mailOAuthEmail = v
+	}
+
+	timezone := ""
+	if v, ok := user.Timezone(); ok {
+		timezone = v
 	}
 
 	return &models.UserProfileResponse{
-		ID:                user.ID,
-		Name:              user.Name,
-		Email:             user.Email,
-		ProfessionalEmail: professionalEmail,
-		MailAppPassword:   mailAppPassword,
-		DailyLimit:        user.DailyLimit,
-		PdfUploadCount:    user.PdfUploadCount, // Added
-		EmailsSent:        user.EmailsSent,     // Added
-		CreatedAt:         user.CreatedAt,
-		Contacts:          contacts,
-		Activities:        activities, // Added
-		Template:          template,
+		ID:                        user.ID,
+		Name:                      user.Name,
+		Email:                     user.Email,
+		Role:                      formatUserRole(user.Role),
+		ProfessionalEmail:         professionalEmail,
+		MailAppPasswordConfigured: mailAppPasswordConfigured,
+		MailOAuthEmail:            mailOAuthEmail,
+		Timezone:                  timezone,
+		EmailVerified:             isEmailVerified(user),
+		MFAEnabled:                isMFAEnabled(user),
+		DailyLimit:                user.DailyLimit,
+		PdfUploadCount:            user.PdfUploadCount, // Added
+		EmailsSent:                user.EmailsSent,     // Added
+		CreatedAt:                 user.CreatedAt,
+		Organization:              toOrganizationResponse(membership),
+		Contacts:                  contacts,
+		Template:                  template,
 	}, nil
 }
+
+// isEmailVerified reports whether the user confirmed their login email
+func isEmailVerified(user *db.UserModel) bool {
+	_, ok := user.EmailVerifiedAt()
+	return ok
+}
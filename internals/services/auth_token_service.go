@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/satyam-svg/hr-message-backend/internals/utils"
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+)
+
+// ErrInvalidAuthToken is returned when a token is unknown, used or expired
+var ErrInvalidAuthToken = errors.New("invalid or expired token")
+
+// AuthTokenService issues and redeems single-use tokens that are sent by email
+// or handed out as short-lived challenges, such as the MFA login step
+type AuthTokenService struct {
+	client *db.PrismaClient
+}
+
+// NewAuthTokenService creates a new auth token service
+func NewAuthTokenService(client *db.PrismaClient) *AuthTokenService {
+	return &AuthTokenService{
+		client: client,
+	}
+}
+
+// Issue creates a token for the purpose and invalidates the user's earlier
+// unused tokens for it. Only the hash is stored; the raw token is returned.
+func (s *AuthTokenService) Issue(ctx context.Context, userID string, purpose db.AuthTokenPurpose, ttl time.Duration) (string, error) {
+	token, tokenHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	invalidate := s.client.AuthToken.FindMany(
+		db.AuthToken.UserID.Equals(userID),
+		db.AuthToken.Purpose.Equals(purpose),
+		db.AuthToken.UsedAt.IsNull(),
+	).Update(
+		db.AuthToken.UsedAt.Set(time.Now()),
+	).Tx()
+
+	create := s.client.AuthToken.CreateOne(
+		db.AuthToken.Purpose.Set(purpose),
+		db.AuthToken.TokenHash.Set(tokenHash),
+		db.AuthToken.ExpiresAt.Set(time.Now().Add(ttl)),
+		db.AuthToken.User.Link(db.User.ID.Equals(userID)),
+	).Tx()
+
+	if err := s.client.Prisma.Transaction(invalidate, create).Exec(ctx); err != nil {
+		return "", fmt.Errorf("failed to store token: %w", err)
+	}
+
+	return token, nil
+}
+
+// RetryAfter enforces a minimum interval and an hourly cap on issuing tokens
+// for a purpose. It returns how long the user must wait, or zero if allowed.
+func (s *AuthTokenService) RetryAfter(ctx context.Context, userID string, purpose db.AuthTokenPurpose, minInterval time.Duration, maxPerHour int) (time.Duration, error) {
+	recent, err := s.client.AuthToken.FindMany(
+		db.AuthToken.UserID.Equals(userID),
+		db.AuthToken.Purpose.Equals(purpose),
+		db.AuthToken.CreatedAt.After(time.Now().Add(-time.Hour)),
+	).OrderBy(
+		db.AuthToken.CreatedAt.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch recent tokens: %w", err)
+	}
+
+	if len(recent) == 0 {
+		return 0, nil
+	}
+	if wait := time.Until(recent[0].CreatedAt.Add(minInterval)); wait > 0 {
+		return wait, nil
+	}
+	if len(recent) >= maxPerHour {
+		// Wait until the oldest token of the window drops out
+		return time.Until(recent[len(recent)-1].CreatedAt.Add(time.Hour)), nil
+	}
+
+	return 0, nil
+}
+
+// Lookup returns an unused, unexpired token without consuming it. Callers that
+// need a second factor before redeeming use it to check the token first.
+func (s *AuthTokenService) Lookup(ctx context.Context, token string, purpose db.AuthTokenPurpose) (*db.AuthTokenModel, error) {
+	authToken, err := s.client.AuthToken.FindUnique(
+		db.AuthToken.TokenHash.Equals(utils.HashOpaqueToken(token)),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrInvalidAuthToken
+		}
+		return nil, fmt.Errorf("failed to fetch token: %w", err)
+	}
+
+	if authToken.Purpose != purpose || authToken.ExpiresAt.Before(time.Now()) {
+		return nil, ErrInvalidAuthToken
+	}
+	if _, used := authToken.UsedAt(); used {
+		return nil, ErrInvalidAuthToken
+	}
+
+	return authToken, nil
+}
+
+// RecordFailedAttempt counts a failed use of a token and invalidates it once
+// maxAttempts is reached, so a token cannot be used to guess codes forever
+func (s *AuthTokenService) RecordFailedAttempt(ctx context.Context, authToken *db.AuthTokenModel, maxAttempts int) error {
+	updated, err := s.client.AuthToken.FindUnique(
+		db.AuthToken.ID.Equals(authToken.ID),
+	).Update(
+		db.AuthToken.Attempts.Increment(1),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to record token attempt: %w", err)
+	}
+
+	if updated.Attempts >= maxAttempts {
+		_, err = s.client.AuthToken.FindMany(
+			db.AuthToken.ID.Equals(authToken.ID),
+			db.AuthToken.UsedAt.IsNull(),
+		).Update(
+			db.AuthToken.UsedAt.Set(time.Now()),
+		).Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to invalidate token: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Redeem marks a token as used and returns it. A token can be redeemed only once.
+func (s *AuthTokenService) Redeem(ctx context.Context, token string, purpose db.AuthTokenPurpose) (*db.AuthTokenModel, error) {
+	authToken, err := s.client.AuthToken.FindUnique(
+		db.AuthToken.TokenHash.Equals(utils.HashOpaqueToken(token)),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrInvalidAuthToken
+		}
+		return nil, fmt.Errorf("failed to fetch token: %w", err)
+	}
+
+	if authToken.Purpose != purpose || authToken.ExpiresAt.Before(time.Now()) {
+		return nil, ErrInvalidAuthToken
+	}
+
+	// Claim the token atomically so it cannot be redeemed twice
+	result, err := s.client.AuthToken.FindMany(
+		db.AuthToken.ID.Equals(authToken.ID),
+		db.AuthToken.UsedAt.IsNull(),
+	).Update(
+		db.AuthToken.UsedAt.Set(time.Now()),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeem token: %w", err)
+	}
+	if result.Count == 0 {
+		return nil, ErrInvalidAuthToken
+	}
+
+	return authToken, nil
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/internals/utils"
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+)
+
+// ErrCompanyNotFound is returned when a company does not exist or belongs to another organization
+var ErrCompanyNotFound = errors.New("company not found or unauthorized")
+
+// ErrCompanyExists is returned when a rename collides with another company of the organization
+var ErrCompanyExists = errors.New("a company with this name already exists")
+
+// CompanyService handles company business logic
+type CompanyService struct {
+	client *db.PrismaClient
+}
+
+// NewCompanyService creates a new company service
+func NewCompanyService(client *db.PrismaClient) *CompanyService {
+	return &CompanyService{
+		client: client,
+	}
+}
+
+// MatchOrCreate finds the organization's company for a contact, first by the
+// domain of the contact's email and then by normalized company name, creating
+// it if needed. It returns nil when there is neither a usable name nor a
+// corporate domain.
+func (s *CompanyService) MatchOrCreate(ctx context.Context, organizationId string, companyName string, email string) (*db.CompanyModel, error) {
+	domain := utils.CompanyDomainFromEmail(email)
+	normalized := utils.NormalizeCompanyName(companyName)
+
+	if domain != "" {
+		company, err := s.client.Company.FindFirst(
+			db.Company.OrganizationID.Equals(organizationId),
+			db.Company.Domain.Equals(domain),
+		).Exec(ctx)
+		if err == nil {
+			return company, nil
+		}
+		if !errors.Is(err, db.ErrNotFound) {
+			return nil, fmt.Errorf("failed to match company by domain: %w", err)
+		}
+	}
+
+	if normalized == "" {
+		if domain == "" {
+			return nil, nil
+		}
+		// No name was extracted; fall back to the domain as the company name
+		companyName = domain
+		normalized = utils.NormalizeCompanyName(domain)
+	}
+
+	company, err := s.client.Company.FindUnique(
+		db.Company.OrganizationIDNormalizedName(
+			db.Company.OrganizationID.Equals(organizationId),
+			db.Company.NormalizedName.Equals(normalized),
+		),
+	).Exec(ctx)
+	if err == nil {
+		// Learn the domain the first time we see a corporate address for this company
+		if _, hasDomain := company.Domain(); !hasDomain && domain != "" {
+			company, err = s.client.Company.FindUnique(
+				db.Company.ID.Equals(company.ID),
+			).Update(
+				db.Company.Domain.Set(domain),
+				db.Company.Website.SetIfPresent(websiteFor(company, domain)),
+			).Exec(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update company domain: %w", err)
+			}
+		}
+		return company, nil
+	}
+	if !errors.Is(err, db.ErrNotFound) {
+		return nil, fmt.Errorf("failed to match company by name: %w", err)
+	}
+
+	var params []db.CompanySetParam
+	if domain != "" {
+		params = append(params,
+			db.Company.Domain.Set(domain),
+			db.Company.Website.Set("https://"+domain),
+		)
+	}
+
+	company, err = s.client.Company.CreateOne(
+		db.Company.Name.Set(strings.TrimSpace(companyName)),
+		db.Company.NormalizedName.Set(normalized),
+		db.Company.Organization.Link(db.Organization.ID.Equals(organizationId)),
+		params...,
+	).Exec(ctx)
+	if err != nil {
+		// Another import created it concurrently
+		if _, ok := db.IsErrUniqueConstraint(err); ok {
+			return s.client.Company.FindUnique(
+				db.Company.OrganizationIDNormalizedName(
+					db.Company.OrganizationID.Equals(organizationId),
+					db.Company.NormalizedName.Equals(normalized),
+				),
+			).Exec(ctx)
+		}
+		return nil, fmt.Errorf("failed to create company: %w", err)
+	}
+
+	return company, nil
+}
+
+// LinkContact matches a contact to a company and stores the link. It returns
+// the matched company, or nil when the contact could not be matched.
+func (s *CompanyService) LinkContact(ctx context.Context, contact *db.ContactModel) (*db.CompanyModel, error) {
+	company, err := s.MatchOrCreate(ctx, contact.OrganizationID, contact.CompanyName, contact.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	param := db.Contact.Company.Unlink()
+	if company != nil {
+		if current, ok := contact.CompanyID(); ok && current == company.ID {
+			return company, nil
+		}
+		param = db.Contact.Company.Link(db.Company.ID.Equals(company.ID))
+	} else if _, ok := contact.CompanyID(); !ok {
+		return nil, nil
+	}
+
+	_, err = s.client.Contact.FindUnique(
+		db.Contact.ID.Equals(contact.ID),
+	).Update(param).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to link contact to company: %w", err)
+	}
+
+	return company, nil
+}
+
+// RematchContacts links every contact of the organization that has no company yet
+func (s *CompanyService) RematchContacts(ctx context.Context, userId string, organizationId string) (*models.RematchCompaniesResponse, error) {
+	membership, err := resolveMembership(ctx, s.client, userId, organizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	contacts, err := s.client.Contact.FindMany(
+		db.Contact.OrganizationID.Equals(membership.OrganizationID),
+		db.Contact.CompanyID.IsNull(),
+		db.Contact.DeletedAt.IsNull(),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch contacts: %w", err)
+	}
+
+	matched := 0
+	for i := range contacts {
+		company, err := s.LinkContact(ctx, &contacts[i])
+		if err != nil {
+			fmt.Printf("Failed to match company for %s: %v\n", contacts[i].Email, err)
+			continue
+		}
+		if company != nil {
+			matched++
+		}
+	}
+
+	return &models.RematchCompaniesResponse{Matched: matched}, nil
+}
+
+// ListCompanies returns the organization's companies with their contact counts
+func (s *CompanyService) ListCompanies(ctx context.Context, userId string, organizationId string) ([]models.CompanyResponse, error) {
+	membership, err := resolveMembership(ctx, s.client, userId, organizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	companies, err := s.client.Company.FindMany(
+		db.Company.OrganizationID.Equals(membership.OrganizationID),
+	).With(
+		db.Company.Contacts.Fetch(
+			db.Contact.DeletedAt.IsNull(),
+		),
+	).OrderBy(
+		db.Company.Name.Order(db.SortOrderAsc),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch companies: %w", err)
+	}
+
+	response := []models.CompanyResponse{}
+	for i := range companies {
+		response = append(response, toCompanyResponse(&companies[i], len(companies[i].Contacts())))
+	}
+
+	return response, nil
+}
+
+// GetCompany returns a company and its contacts
+func (s *CompanyService) GetCompany(ctx context.Context, userId string, organizationId string, companyId string) (*models.CompanyDetailResponse, error) {
+	company, err := s.findCompany(ctx, userId, organizationId, companyId)
+	if err != nil {
+		return nil, err
+	}
+
+	contacts := []models.ContactResponse{}
+	for _, c := range company.Contacts() {
+		contacts = append(contacts, toContactResponse(&c))
+	}
+
+	return &models.CompanyDetailResponse{
+		CompanyResponse: toCompanyResponse(company, len(contacts)),
+		Contacts:        contacts,
+	}, nil
+}
+
+// UpdateCompany updates a company's name, website or notes
+func (s *CompanyService) UpdateCompany(ctx context.Context, userId string, organizationId string, companyId string, req models.UpdateCompanyRequest) (*models.CompanyResponse, error) {
+	existing, err := s.findCompany(ctx, userId, organizationId, companyId)
+	if err != nil {
+		return nil, err
+	}
+
+	var params []db.CompanySetParam
+	if req.Name != nil && strings.TrimSpace(*req.Name) != "" {
+		params = append(params,
+			db.Company.Name.Set(strings.TrimSpace(*req.Name)),
+			db.Company.NormalizedName.Set(utils.NormalizeCompanyName(*req.Name)),
+		)
+	}
+	if req.Website != nil {
+		params = append(params, db.Company.Website.Set(*req.Website))
+	}
+	if req.Notes != nil {
+		params = append(params, db.Company.Notes.Set(*req.Notes))
+	}
+
+	company, err := s.client.Company.FindUnique(
+		db.Company.ID.Equals(companyId),
+	).Update(
+		params...,
+	).Exec(ctx)
+	if err != nil {
+		if _, ok := db.IsErrUniqueConstraint(err); ok {
+			return nil, ErrCompanyExists
+		}
+		return nil, fmt.Errorf("failed to update company: %w", err)
+	}
+
+	response := toCompanyResponse(company, len(existing.Contacts()))
+	return &response, nil
+}
+
+// findCompany returns a company of the organization with its contacts
+func (s *CompanyService) findCompany(ctx context.Context, userId string, organizationId string, companyId string) (*db.CompanyModel, error) {
+	membership, err := resolveMembership(ctx, s.client, userId, organizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	company, err := s.client.Company.FindFirst(
+		db.Company.ID.Equals(companyId),
+		db.Company.OrganizationID.Equals(membership.OrganizationID),
+	).With(
+		db.Company.Contacts.Fetch(
+			db.Contact.DeletedAt.IsNull(),
+		),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrCompanyNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch company: %w", err)
+	}
+
+	return company, nil
+}
+
+// websiteFor returns a default website for a newly learned domain unless one is already set
+func websiteFor(company *db.CompanyModel, domain string) *string {
+	if _, ok := company.Website(); ok {
+		return nil
+	}
+	website := "https://" + domain
+	return &website
+}
+
+func toCompanyResponse(c *db.CompanyModel, contactCount int) models.CompanyResponse {
+	response := models.CompanyResponse{
+		ID:           c.ID,
+		Name:         c.Name,
+		ContactCount: contactCount,
+		CreatedAt:    c.CreatedAt,
+	}
+	if v, ok := c.Domain(); ok {
+		response.Domain = v
+	}
+	if v, ok := c.Website(); ok {
+		response.Website = v
+	}
+	if v, ok := c.Notes(); ok {
+		response.Notes = v
+	}
+
+	return response
+}
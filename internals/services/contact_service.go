@@ -2,45 +2,101 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/satyam-svg/hr-message-backend/internals/models"
 	"github.com/satyam-svg/hr-message-backend/prisma/db"
 )
 
+// ErrContactNotFound is returned when a contact does not exist or belongs to an organization the user is not in
+var ErrContactNotFound = errors.New("contact not found or unauthorized")
+
+// ErrInvalidContactStatus is returned when a status string is not a known contact status
+var ErrInvalidContactStatus = errors.New("invalid contact status")
+
+// ErrInvalidBulkRequest is returned when a bulk operation is missing its action, targets or arguments
+var ErrInvalidBulkRequest = errors.New("invalid bulk request")
+
+// maxBulkContacts caps how many contacts a single bulk request may touch
+const maxBulkContacts = 500
+
 type ContactService struct {
-	client *db.PrismaClient
+	client     *db.PrismaClient
+	activities *ActivityService
+	companies  *CompanyService
 }
 
 func NewContactServcie(client *db.PrismaClient) *ContactService {
 	return &ContactService{
-		client: client,
+		client:     client,
+		activities: NewActivityService(client),
+		companies:  NewCompanyService(client),
 	}
 }
 
 func (s *ContactService) UpdateContact(ctx context.Context, contactId string, userId string, req models.UpdateContactRequest) (*models.ContactResponse, error) {
-	// Verify ownership
-	_, err := s.client.Contact.FindFirst(
-		db.Contact.ID.Equals(contactId),
-		db.Contact.UserID.Equals(userId),
-	).Exec(ctx)
-
+	// Verify membership in the contact's organization
+	existing, err := s.findAccessibleContact(ctx, contactId, userId)
 	if err != nil {
-		return nil, fmt.Errorf("contact not found or unauthorized")
+		return nil, err
 	}
 
 	var params []db.ContactSetParam
+	changes := map[string]interface{}{}
 	if req.Name != nil {
 		params = append(params, db.Contact.Name.Set(*req.Name))
+		changes["name"] = *req.Name
 	}
 	if req.CompanyName != nil {
 		params = append(params, db.Contact.CompanyName.Set(*req.CompanyName))
+		changes["company_name"] = *req.CompanyName
 	}
 	if req.Email != nil {
 		params = append(params, db.Contact.Email.Set(*req.Email))
+		changes["email"] = *req.Email
 	}
 	if req.IsSent != nil {
 		params = append(params, db.Contact.IsSent.Set(*req.IsSent))
+		changes["is_sent"] = *req.IsSent
+	}
+
+	if req.Timezone != nil {
+		timezone := strings.TrimSpace(*req.Timezone)
+		if timezone == "" {
+			params = append(params, db.Contact.Timezone.SetOptional(nil))
+		} else {
+			if _, err := loadTimezone(timezone); err != nil {
+				return nil, err
+			}
+			params = append(params, db.Contact.Timezone.Set(timezone))
+		}
+		changes["timezone"] = timezone
+	}
+
+	var newStatus db.ContactStatus
+	if req.Status != nil {
+		newStatus, err = parseContactStatus(*req.Status)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, db.Contact.Status.Set(newStatus))
+		changes["status"] = formatContactStatus(newStatus)
+	}
+
+	_, wasUnsubscribed := existing.UnsubscribedAt()
+	unsubscribing := req.Unsubscribed != nil && *req.Unsubscribed && !wasUnsubscribed
+	if req.Unsubscribed != nil && *req.Unsubscribed != wasUnsubscribed {
+		if *req.Unsubscribed {
+			params = append(params, db.Contact.UnsubscribedAt.Set(time.Now()))
+		} else {
+			params = append(params, db.Contact.UnsubscribedAt.SetOptional(nil))
+		}
+		changes["unsubscribed"] = *req.Unsubscribed
 	}
 
 	updated, err := s.client.Contact.FindUnique(
@@ -53,12 +109,637 @@ func (s *ContactService) UpdateContact(ctx context.Context, contactId string, us
 		return nil, err
 	}
 
-	return &models.ContactResponse{
-		ID:          updated.ID,
-		Name:        updated.Name,
-		CompanyName: updated.CompanyName,
-		Email:       updated.Email,
-		IsSent:      updated.IsSent,
-		CreatedAt:   updated.CreatedAt,
+	// Re-resolve the company when the fields it is derived from change
+	if req.CompanyName != nil || req.Email != nil {
+		if _, err := s.companies.LinkContact(ctx, updated); err != nil {
+			fmt.Printf("Failed to match company for %s: %v\n", contactId, err)
+		} else if refreshed, err := s.findAccessibleContact(ctx, contactId, userId); err == nil {
+			updated = refreshed
+		}
+	}
+
+	if req.Status != nil && newStatus != existing.Status {
+		if err := recordStatusChange(ctx, s.client, contactId, existing.Status, newStatus); err != nil {
+			fmt.Printf("Failed to record status change for %s: %v\n", contactId, err)
+		}
+	}
+
+	// Follow-ups end when the contact answers or unsubscribes
+	if unsubscribing {
+		if _, err := stopEnrollments(ctx, s.client, db.EnrollmentStopReasonUnsubscribed, time.Now(), db.SequenceEnrollment.ContactID.Equals(contactId)); err != nil {
+			fmt.Printf("Failed to stop follow-ups for %s: %v\n", contactId, err)
+		}
+		s.activities.Record(ctx, userId, ActivityEvent{
+			Type:        db.ActivityTypeContactUnsubscribed,
+			Description: "Unsubscribed contact " + updated.Name,
+			TargetType:  models.ActivityTargetContact,
+			TargetID:    updated.ID,
+		})
+	} else if req.Status != nil && hasReplied(newStatus) {
+		if _, err := stopEnrollments(ctx, s.client, db.EnrollmentStopReasonReplied, time.Now(), db.SequenceEnrollment.ContactID.Equals(contactId)); err != nil {
+			fmt.Printf("Failed to stop follow-ups for %s: %v\n", contactId, err)
+		}
+	}
+
+	s.activities.Record(ctx, userId, ActivityEvent{
+		Type:        db.ActivityTypeContactUpdated,
+		Description: "Updated contact " + updated.Name,
+		TargetType:  models.ActivityTargetContact,
+		TargetID:    updated.ID,
+		Metadata:    changes,
+	})
+
+	response := toContactResponse(updated)
+	return &response, nil
+}
+
+// DeleteContact moves a contact to the trash. Members can only delete
+// contacts they created; owners and admins can delete any.
+func (s *ContactService) DeleteContact(ctx context.Context, contactId string, userId string) error {
+	contact, err := s.findAccessibleContact(ctx, contactId, userId)
+	if err != nil {
+		return err
+	}
+
+	membership, err := findMembership(ctx, s.client, contact.OrganizationID, userId)
+	if err != nil {
+		return err
+	}
+	if !canModerate(membership, contact.UserID) {
+		return ErrInsufficientRole
+	}
+
+	_, err = s.client.Contact.FindUnique(
+		db.Contact.ID.Equals(contactId),
+	).Update(
+		db.Contact.DeletedAt.Set(time.Now()),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete contact: %w", err)
+	}
+
+	s.activities.Record(ctx, userId, ActivityEvent{
+		Type:        db.ActivityTypeContactDeleted,
+		Description: "Moved contact " + contact.Name + " to trash",
+		TargetType:  models.ActivityTargetContact,
+		TargetID:    contact.ID,
+	})
+
+	return nil
+}
+
+// AddNote attaches a free-text note to a contact in one of the user's organizations
+func (s *ContactService) AddNote(ctx context.Context, contactId string, userId string, req models.CreateContactNoteRequest) (*models.ContactNoteResponse, error) {
+	if _, err := s.findAccessibleContact(ctx, contactId, userId); err != nil {
+		return nil, err
+	}
+
+	note, err := s.client.ContactNote.CreateOne(
+		db.ContactNote.Body.Set(req.Body),
+		db.ContactNote.Contact.Link(db.Contact.ID.Equals(contactId)),
+		db.ContactNote.User.Link(db.User.ID.Equals(userId)),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create note: %w", err)
+	}
+
+	response := toContactNoteResponse(note)
+	return &response, nil
+}
+
+// ListNotes returns all notes for a contact, newest first
+func (s *ContactService) ListNotes(ctx context.Context, contactId string, userId string) ([]models.ContactNoteResponse, error) {
+	if _, err := s.findAccessibleContact(ctx, contactId, userId); err != nil {
+		return nil, err
+	}
+
+	notes, err := s.client.ContactNote.FindMany(
+		db.ContactNote.ContactID.Equals(contactId),
+	).OrderBy(
+		db.ContactNote.CreatedAt.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch notes: %w", err)
+	}
+
+	response := []models.ContactNoteResponse{}
+	for i := range notes {
+		response = append(response, toContactNoteResponse(&notes[i]))
+	}
+
+	return response, nil
+}
+
+// DeleteNote removes a note the user wrote
+func (s *ContactService) DeleteNote(ctx context.Context, contactId string, noteId string, userId string) error {
+	result, err := s.client.ContactNote.FindMany(
+		db.ContactNote.ID.Equals(noteId),
+		db.ContactNote.ContactID.Equals(contactId),
+		db.ContactNote.UserID.Equals(userId),
+		db.ContactNote.Contact.Where(
+			db.Contact.DeletedAt.IsNull(),
+			contactAccessibleBy(userId),
+		),
+	).Delete().Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete note: %w", err)
+	}
+
+	if result.Count == 0 {
+		return ErrContactNotFound
+	}
+
+	return nil
+}
+
+// GetTimeline merges notes, sent and failed emails, replies and status changes
+// for a contact into a single chronological list
+func (s *ContactService) GetTimeline(ctx context.Context, contactId string, userId string) (*models.ContactTimelineResponse, error) {
+	contact, err := s.findAccessibleContact(ctx, contactId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	notes, err := s.client.ContactNote.FindMany(
+		db.ContactNote.ContactID.Equals(contactId),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch notes: %w", err)
+	}
+
+	emailLogs, err := s.client.EmailLog.FindMany(
+		db.EmailLog.ContactID.Equals(contactId),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch email history: %w", err)
+	}
+
+	statusChanges, err := s.client.ContactStatusChange.FindMany(
+		db.ContactStatusChange.ContactID.Equals(contactId),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch status changes: %w", err)
+	}
+
+	replies, err := s.client.InboundMessage.FindMany(
+		db.InboundMessage.ContactID.Equals(contactId),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch replies: %w", err)
+	}
+
+	entries := []models.TimelineEntry{}
+
+	for _, n := range notes {
+		entries = append(entries, models.TimelineEntry{
+			ID:        n.ID,
+			Type:      models.TimelineEntryNote,
+			Summary:   "Note added",
+			Detail:    n.Body,
+			CreatedAt: n.CreatedAt,
+		})
+	}
+
+	for _, l := range emailLogs {
+		entry := models.TimelineEntry{
+			ID:        l.ID,
+			Type:      models.TimelineEntryEmailSent,
+			Summary:   "Email sent: " + l.Subject,
+			CreatedAt: l.CreatedAt,
+		}
+		if l.Status == db.EmailStatusFailed {
+			entry.Type = models.TimelineEntryEmailFailed
+			entry.Summary = "Email failed: " + l.Subject
+			if e, ok := l.Error(); ok {
+				entry.Detail = e
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	for _, c := range statusChanges {
+		entry := models.TimelineEntry{
+			ID:        c.ID,
+			Type:      models.TimelineEntryStatusChange,
+			Summary:   fmt.Sprintf("Status changed from %s to %s", formatContactStatus(c.FromStatus), formatContactStatus(c.ToStatus)),
+			CreatedAt: c.CreatedAt,
+		}
+		if c.ToStatus == db.ContactStatusReplied {
+			entry.Type = models.TimelineEntryReply
+			entry.Summary = "Contact replied"
+		}
+		entries = append(entries, entry)
+	}
+
+	for _, r := range replies {
+		entries = append(entries, models.TimelineEntry{
+			ID:        r.ID,
+			Type:      models.TimelineEntryReply,
+			Summary:   "Reply received: " + r.Subject,
+			Detail:    r.Body,
+			CreatedAt: r.ReceivedAt,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+
+	return &models.ContactTimelineResponse{
+		Contact: toContactResponse(contact),
+		Entries: entries,
 	}, nil
 }
+
+// BulkUpdate applies one action to a list of contact IDs or to every contact
+// matching a filter. All changes run in a single transaction; the response
+// reports the outcome for each contact.
+func (s *ContactService) BulkUpdate(ctx context.Context, userId string, organizationId string, req models.BulkContactRequest) (*models.BulkContactResponse, error) {
+	if err := validateBulkRequest(&req); err != nil {
+		return nil, err
+	}
+
+	membership, err := resolveMembership(ctx, s.client, userId, organizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	var newStatus db.ContactStatus
+	if req.Action == models.BulkActionSetStatus {
+		status, err := parseContactStatus(req.Status)
+		if err != nil {
+			return nil, err
+		}
+		newStatus = status
+	}
+
+	// Resolve targets, restricted to the organization's contacts outside the trash
+	where := []db.ContactWhereParam{
+		db.Contact.OrganizationID.Equals(membership.OrganizationID),
+		db.Contact.DeletedAt.IsNull(),
+	}
+	if len(req.IDs) > 0 {
+		where = append(where, db.Contact.ID.In(req.IDs))
+	} else {
+		filterParams, err := contactFilterParams(req.Filter)
+		if err != nil {
+			return nil, err
+		}
+		where = append(where, filterParams...)
+	}
+
+	contacts, err := s.client.Contact.FindMany(where...).Take(maxBulkContacts + 1).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch contacts: %w", err)
+	}
+	if len(contacts) > maxBulkContacts {
+		return nil, fmt.Errorf("%w: at most %d contacts can be changed at once", ErrInvalidBulkRequest, maxBulkContacts)
+	}
+
+	response := &models.BulkContactResponse{
+		Action:  req.Action,
+		Results: []models.BulkContactItemResult{},
+	}
+
+	found := map[string]bool{}
+	var txs []db.PrismaTransaction
+	var planned []int // indexes into response.Results that are part of the transaction
+
+	for i := range contacts {
+		contact := &contacts[i]
+		found[contact.ID] = true
+
+		if req.Action == models.BulkActionDelete && !canModerate(membership, contact.UserID) {
+			response.Results = append(response.Results, models.BulkContactItemResult{
+				ID:     contact.ID,
+				Status: models.BulkItemFailed,
+				Error:  ErrInsufficientRole.Error(),
+			})
+			continue
+		}
+
+		ops, err := bulkOperations(s.client, contact, req, newStatus)
+		if err != nil {
+			response.Results = append(response.Results, models.BulkContactItemResult{
+				ID:     contact.ID,
+				Status: models.BulkItemFailed,
+				Error:  err.Error(),
+			})
+			continue
+		}
+		if len(ops) == 0 {
+			response.Results = append(response.Results, models.BulkContactItemResult{
+				ID:     contact.ID,
+				Status: models.BulkItemUnchanged,
+			})
+			continue
+		}
+
+		txs = append(txs, ops...)
+		planned = append(planned, len(response.Results))
+		response.Results = append(response.Results, models.BulkContactItemResult{
+			ID:     contact.ID,
+			Status: models.BulkItemOK,
+		})
+	}
+
+	// IDs that don't exist or belong to another organization
+	for _, id := range req.IDs {
+		if !found[id] {
+			found[id] = true
+			response.Results = append(response.Results, models.BulkContactItemResult{
+				ID:     id,
+				Status: models.BulkItemNotFound,
+			})
+		}
+	}
+
+	if len(txs) > 0 {
+		if err := s.client.Prisma.Transaction(txs...).Exec(ctx); err != nil {
+			response.RolledBack = true
+			for _, idx := range planned {
+				response.Results[idx].Status = models.BulkItemFailed
+				response.Results[idx].Error = err.Error()
+			}
+		} else if req.Action == models.BulkActionSetStatus && hasReplied(newStatus) {
+			// Follow-ups end when the contact answers
+			ids := make([]string, 0, len(planned))
+			for _, idx := range planned {
+				ids = append(ids, response.Results[idx].ID)
+			}
+			if _, err := stopEnrollments(ctx, s.client, db.EnrollmentStopReasonReplied, time.Now(), db.SequenceEnrollment.ContactID.In(ids)); err != nil {
+				fmt.Printf("Failed to stop follow-ups: %v\n", err)
+			}
+		}
+	}
+
+	for _, r := range response.Results {
+		switch r.Status {
+		case models.BulkItemOK:
+			response.Succeeded++
+		case models.BulkItemUnchanged:
+			response.Unchanged++
+		default:
+			response.Failed++
+		}
+	}
+	response.Total = len(response.Results)
+
+	if response.Succeeded > 0 {
+		s.activities.Record(ctx, userId, ActivityEvent{
+			Type:        db.ActivityTypeContactUpdated,
+			Description: fmt.Sprintf("Bulk %s on %d contacts", req.Action, response.Succeeded),
+			TargetType:  models.ActivityTargetContact,
+			Metadata: map[string]interface{}{
+				"action":    req.Action,
+				"succeeded": response.Succeeded,
+				"failed":    response.Failed,
+			},
+		})
+	}
+
+	return response, nil
+}
+
+// validateBulkRequest checks that the action, its arguments and the targets are present
+func validateBulkRequest(req *models.BulkContactRequest) error {
+	if len(req.IDs) == 0 && req.Filter == nil {
+		return fmt.Errorf("%w: ids or filter is required", ErrInvalidBulkRequest)
+	}
+	if len(req.IDs) > maxBulkContacts {
+		return fmt.Errorf("%w: at most %d ids are allowed", ErrInvalidBulkRequest, maxBulkContacts)
+	}
+
+	req.Tag = strings.TrimSpace(req.Tag)
+	req.Field = strings.TrimSpace(req.Field)
+
+	switch req.Action {
+	case models.BulkActionDelete, models.BulkActionMarkSent, models.BulkActionMarkUnsent:
+	case models.BulkActionTag, models.BulkActionUntag:
+		if req.Tag == "" {
+			return fmt.Errorf("%w: tag is required", ErrInvalidBulkRequest)
+		}
+	case models.BulkActionSetStatus:
+		if req.Status == "" {
+			return fmt.Errorf("%w: status is required", ErrInvalidBulkRequest)
+		}
+	case models.BulkActionSetCustomField:
+		if req.Field == "" {
+			return fmt.Errorf("%w: field is required", ErrInvalidBulkRequest)
+		}
+	default:
+		return fmt.Errorf("%w: unknown action %q", ErrInvalidBulkRequest, req.Action)
+	}
+
+	return nil
+}
+
+// bulkOperations builds the transaction steps for one contact; an empty
+// result means the contact already matches the requested state
+func bulkOperations(client *db.PrismaClient, contact *db.ContactModel, req models.BulkContactRequest, newStatus db.ContactStatus) ([]db.PrismaTransaction, error) {
+	update := func(params ...db.ContactSetParam) db.PrismaTransaction {
+		return client.Contact.FindUnique(
+			db.Contact.ID.Equals(contact.ID),
+		).Update(params...).Tx()
+	}
+
+	switch req.Action {
+	case models.BulkActionDelete:
+		// Deleted contacts go to the trash so their send history survives a mis-click
+		return []db.PrismaTransaction{update(db.Contact.DeletedAt.Set(time.Now()))}, nil
+
+	case models.BulkActionTag:
+		for _, t := range contact.Tags {
+			if t == req.Tag {
+				return nil, nil
+			}
+		}
+		return []db.PrismaTransaction{update(db.Contact.Tags.Set(append(contact.Tags, req.Tag)))}, nil
+
+	case models.BulkActionUntag:
+		tags := []string{}
+		for _, t := range contact.Tags {
+			if t != req.Tag {
+				tags = append(tags, t)
+			}
+		}
+		if len(tags) == len(contact.Tags) {
+			return nil, nil
+		}
+		return []db.PrismaTransaction{update(db.Contact.Tags.Set(tags))}, nil
+
+	case models.BulkActionMarkSent, models.BulkActionMarkUnsent:
+		isSent := req.Action == models.BulkActionMarkSent
+		if contact.IsSent == isSent {
+			return nil, nil
+		}
+		return []db.PrismaTransaction{update(db.Contact.IsSent.Set(isSent))}, nil
+
+	case models.BulkActionSetStatus:
+		if contact.Status == newStatus {
+			return nil, nil
+		}
+		return []db.PrismaTransaction{
+			update(db.Contact.Status.Set(newStatus)),
+			client.ContactStatusChange.CreateOne(
+				db.ContactStatusChange.FromStatus.Set(contact.Status),
+				db.ContactStatusChange.ToStatus.Set(newStatus),
+				db.ContactStatusChange.Contact.Link(db.Contact.ID.Equals(contact.ID)),
+			).Tx(),
+		}, nil
+
+	case models.BulkActionSetCustomField:
+		fields := map[string]interface{}{}
+		if raw, ok := contact.CustomFields(); ok {
+			if err := json.Unmarshal(raw, &fields); err != nil {
+				return nil, fmt.Errorf("existing custom fields are not a JSON object")
+			}
+		}
+		if req.Value == nil {
+			if _, exists := fields[req.Field]; !exists {
+				return nil, nil
+			}
+			delete(fields, req.Field)
+		} else {
+			fields[req.Field] = req.Value
+		}
+		encoded, err := json.Marshal(fields)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode custom fields: %w", err)
+		}
+		return []db.PrismaTransaction{update(db.Contact.CustomFields.Set(encoded))}, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown action %q", ErrInvalidBulkRequest, req.Action)
+}
+
+// contactFilterParams converts a ContactFilter into Prisma where clauses
+func contactFilterParams(filter *models.ContactFilter) ([]db.ContactWhereParam, error) {
+	var params []db.ContactWhereParam
+	if filter == nil {
+		return params, nil
+	}
+
+	if filter.IsSent != nil {
+		params = append(params, db.Contact.IsSent.Equals(*filter.IsSent))
+	}
+	if filter.Status != nil {
+		status, err := parseContactStatus(*filter.Status)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, db.Contact.Status.Equals(status))
+	}
+	if filter.Tag != nil {
+		params = append(params, db.Contact.Tags.Has(*filter.Tag))
+	}
+	if filter.CompanyName != nil {
+		params = append(params,
+			db.Contact.CompanyName.Equals(*filter.CompanyName),
+			db.Contact.CompanyName.Mode(db.QueryModeInsensitive),
+		)
+	}
+	if filter.Search != nil && *filter.Search != "" {
+		params = append(params, db.Contact.Or(
+			db.Contact.And(db.Contact.Name.Contains(*filter.Search), db.Contact.Name.Mode(db.QueryModeInsensitive)),
+			db.Contact.And(db.Contact.Email.Contains(*filter.Search), db.Contact.Email.Mode(db.QueryModeInsensitive)),
+			db.Contact.And(db.Contact.CompanyName.Contains(*filter.Search), db.Contact.CompanyName.Mode(db.QueryModeInsensitive)),
+		))
+	}
+
+	return params, nil
+}
+
+// findAccessibleContact fetches a contact and verifies the user is a member of its organization
+func (s *ContactService) findAccessibleContact(ctx context.Context, contactId string, userId string) (*db.ContactModel, error) {
+	contact, err := s.client.Contact.FindFirst(
+		db.Contact.ID.Equals(contactId),
+		contactAccessibleBy(userId),
+		db.Contact.DeletedAt.IsNull(),
+	).Exec(ctx)
+
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrContactNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch contact: %w", err)
+	}
+
+	return contact, nil
+}
+
+// contactAccessibleBy matches contacts of organizations the user belongs to
+func contactAccessibleBy(userId string) db.ContactWhereParam {
+	return db.Contact.Organization.Where(memberOf(userId))
+}
+
+// recordStatusChange stores a status transition so it shows up in the contact timeline
+func recordStatusChange(ctx context.Context, client *db.PrismaClient, contactId string, from db.ContactStatus, to db.ContactStatus) error {
+	_, err := client.ContactStatusChange.CreateOne(
+		db.ContactStatusChange.FromStatus.Set(from),
+		db.ContactStatusChange.ToStatus.Set(to),
+		db.ContactStatusChange.Contact.Link(db.Contact.ID.Equals(contactId)),
+	).Exec(ctx)
+
+	return err
+}
+
+// parseContactStatus converts a client supplied status (e.g. "replied") into a ContactStatus
+func parseContactStatus(value string) (db.ContactStatus, error) {
+	status := db.ContactStatus(strings.ToUpper(strings.TrimSpace(value)))
+	switch status {
+	case db.ContactStatusNew,
+		db.ContactStatusContacted,
+		db.ContactStatusReplied,
+		db.ContactStatusInterviewing,
+		db.ContactStatusRejected:
+		return status, nil
+	}
+
+	return "", ErrInvalidContactStatus
+}
+
+// formatContactStatus returns the lowercase status name used in API responses
+func formatContactStatus(status db.ContactStatus) string {
+	return strings.ToLower(string(status))
+}
+
+func toContactResponse(c *db.ContactModel) models.ContactResponse {
+	response := models.ContactResponse{
+		ID:          c.ID,
+		Name:        c.Name,
+		CompanyName: c.CompanyName,
+		Email:       c.Email,
+		IsSent:      c.IsSent,
+		Status:      formatContactStatus(c.Status),
+		Tags:        c.Tags,
+		CreatedAt:   c.CreatedAt,
+	}
+	if response.Tags == nil {
+		response.Tags = []string{}
+	}
+	if v, ok := c.CompanyID(); ok {
+		response.CompanyID = v
+	}
+	if v, ok := c.CustomFields(); ok {
+		response.CustomFields = json.RawMessage(v)
+	}
+	if v, ok := c.Timezone(); ok {
+		response.Timezone = v
+	}
+	if v, ok := c.UnsubscribedAt(); ok {
+		response.UnsubscribedAt = &v
+	}
+
+	return response
+}
+
+func toContactNoteResponse(n *db.ContactNoteModel) models.ContactNoteResponse {
+	return models.ContactNoteResponse{
+		ID:        n.ID,
+		ContactID: n.ContactID,
+		Body:      n.Body,
+		CreatedAt: n.CreatedAt,
+		UpdatedAt: n.UpdatedAt,
+	}
+}
@@ -3,123 +3,742 @@ package services
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/smtp"
+	"net/textproto"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/internals/utils"
 	"github.com/satyam-svg/hr-message-backend/prisma/db"
 	"gopkg.in/gomail.v2"
 )
 
+// ErrCredentialsNotConfigured is returned when the user has not saved their SMTP login
+var ErrCredentialsNotConfigured = errors.New("user email credentials not configured. Please configure them in settings")
+
+// ErrEmailNotVerified is returned when an unverified account tries to send emails
+var ErrEmailNotVerified = errors.New("verify your account email before sending emails")
+
+// ErrDailyLimitReached is returned when the user already sent their daily limit of emails
+var ErrDailyLimitReached = errors.New("daily send limit reached, try again tomorrow")
+
+// ErrInvalidCompanyLimit is returned when a per-company daily limit is not positive
+var ErrInvalidCompanyLimit = errors.New("max_per_company_per_day must be at least 1")
+
+// ErrRecipientRejected is returned when the SMTP server permanently rejects the recipient address
+var ErrRecipientRejected = errors.New("recipient rejected")
+
 // EmailService handles email sending business logic
 type EmailService struct {
-	client *db.PrismaClient
+	client     *db.PrismaClient
+	activities *ActivityService
+	mailOAuth  *MailOAuthService
+	senders    *SenderAccountService
+	clock      Clock
+
+	// followUpRuns holds the campaigns whose follow-ups are being sent
+	followUpMu   sync.Mutex
+	followUpRuns map[string]bool
 }
 
-// NewEmailService creates a new email service
-func NewEmailService(client *db.PrismaClient) *EmailService {
+// NewEmailService creates a new email service. Scheduling, pacing and the
+// daily limits read the time from clock.
+func NewEmailService(client *db.PrismaClient, clock Clock) *EmailService {
 	return &EmailService{
-		client: client,
+		client:     client,
+		activities: NewActivityService(client),
+		mailOAuth:  NewMailOAuthService(client),
+		senders:    NewSenderAccountService(client),
+		clock:      clock,
+
+		followUpRuns: map[string]bool{},
 	}
 }
 
-// StartEmailCampaign starts a background process to send emails to unsent contacts
-func (s *EmailService) StartEmailCampaign(userId string) error {
-	ctx := context.Background()
+// StartEmailCampaign creates a campaign that sends the organization's
+// template to its unsent contacts from the user's mailbox. It starts in the
+// background right away, or at ScheduledAt when the scheduler picks it up.
+// It returns nil when there is nothing to send.
+func (s *EmailService) StartEmailCampaign(ctx context.Context, userId string, organizationId string, req models.StartCampaignRequest) (*models.CampaignResponse, error) {
+	if req.MaxPerCompanyPerDay != nil && *req.MaxPerCompanyPerDay < 1 {
+		return nil, ErrInvalidCompanyLimit
+	}
+
+	rotation, err := parseSenderRotation(req.SenderRotation)
+	if err != nil {
+		return nil, err
+	}
+
+	sendWindows, err := normalizeSendWindows(req.SendWindows)
+	if err != nil {
+		return nil, err
+	}
+
+	pace, err := normalizePacing(req.Pacing)
+	if err != nil {
+		return nil, err
+	}
 
 	// 1. Fetch User (for credentials)
 	user, err := s.client.User.FindUnique(
 		db.User.ID.Equals(userId),
 	).Exec(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to fetch user: %w", err)
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	if !isEmailVerified(user) {
+		return nil, ErrEmailNotVerified
 	}
 
-	// Check if user has email credentials
-	professionalEmail, ok1 := user.ProfessionalEmail()
-	mailAppPassword, ok2 := user.MailAppPassword()
-	if !ok1 || !ok2 || professionalEmail == "" || mailAppPassword == "" {
-		return fmt.Errorf("user email credentials not configured")
+	// Send windows apply in the requested time zone, else the user's
+	timezone := strings.TrimSpace(req.Timezone)
+	if timezone == "" {
+		timezone, _ = user.Timezone()
+	}
+	if timezone != "" {
+		if _, err := loadTimezone(timezone); err != nil {
+			return nil, err
+		}
 	}
 
-	// 2. Fetch Template
-	template, err := s.client.Template.FindUnique(
-		db.Template.UserID.Equals(userId),
-	).Exec(ctx)
+	// Campaigns rotate through the selected sender accounts, or use the
+	// mailbox from the settings when none are selected
+	var senderAccountIDs []string
+	if len(req.SenderAccountIDs) > 0 {
+		senderAccountIDs, err = s.senders.resolveSenderAccounts(ctx, userId, req.SenderAccountIDs)
+		if err != nil {
+			return nil, err
+		}
+	} else if _, err := s.senderCredentials(ctx, user); err != nil {
+		// Check if user has email credentials
+		return nil, err
+	}
+
+	membership, err := resolveMembership(ctx, s.client, userId, organizationId)
 	if err != nil {
-		return fmt.Errorf("failed to fetch template: %w", err)
+		return nil, err
+	}
+
+	// 2. Check the template; the one active when sending is used
+	if _, err := activeTemplate(ctx, s.client, membership.OrganizationID); err != nil {
+		return nil, fmt.Errorf("failed to fetch template: %w", err)
+	}
+
+	var sequence *db.SequenceModel
+	if sequenceId := strings.TrimSpace(req.SequenceID); sequenceId != "" {
+		sequence, err = findSequence(ctx, s.client, membership.OrganizationID, sequenceId)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// 3. Fetch Unsent Contacts
+	// 3. Count Unsent Contacts
 	contacts, err := s.client.Contact.FindMany(
-		db.Contact.UserID.Equals(userId),
+		db.Contact.OrganizationID.Equals(membership.OrganizationID),
 		db.Contact.IsSent.Equals(false),
+		db.Contact.DeletedAt.IsNull(),
+		db.Contact.UnsubscribedAt.IsNull(),
 	).Exec(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to fetch contacts: %w", err)
+		return nil, fmt.Errorf("failed to fetch contacts: %w", err)
 	}
 
 	if len(contacts) == 0 {
-		return nil // Nothing to send
+		return nil, nil // Nothing to send
 	}
 
-	// 4. Start Background Process
-	go func() {
-		// Create a new context for the background job
-		bgCtx := context.Background()
+	// 4. Record Campaign
+	params := []db.CampaignSetParam{
+		db.Campaign.MaxPerCompanyPerDay.SetIfPresent(req.MaxPerCompanyPerDay),
+		db.Campaign.SenderRotation.Set(rotation),
+		db.Campaign.MinDelaySeconds.Set(int(pace.minDelay / time.Second)),
+		db.Campaign.MaxDelaySeconds.Set(int(pace.maxDelay / time.Second)),
+	}
+	if pace.maxPerHour > 0 {
+		params = append(params, db.Campaign.MaxPerHour.Set(pace.maxPerHour))
+	}
+	if pace.warmUpDays > 0 {
+		params = append(params, db.Campaign.WarmUpDays.Set(pace.warmUpDays))
+	}
+	if sequence != nil {
+		params = append(params, db.Campaign.Sequence.Link(db.Sequence.ID.Equals(sequence.ID)))
+	}
+	if len(sendWindows) > 0 {
+		encoded, err := json.Marshal(sendWindows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode send windows: %w", err)
+		}
+		params = append(params,
+			db.Campaign.SendWindows.Set(encoded),
+			db.Campaign.UseRecipientTimezone.Set(req.UseRecipientTimezone),
+		)
+		if timezone != "" {
+			params = append(params, db.Campaign.Timezone.Set(timezone))
+		}
+	}
+	scheduled := req.ScheduledAt != nil && req.ScheduledAt.After(s.clock.Now())
+	if scheduled {
+		params = append(params,
+			db.Campaign.Status.Set(db.CampaignStatusScheduled),
+			db.Campaign.ScheduledAt.Set(*req.ScheduledAt),
+			db.Campaign.NextRunAt.Set(*req.ScheduledAt),
+		)
+	}
 
-		for _, contact := range contacts {
-			// Double check if contact is still unsent (in case of race conditions or manual updates)
-			currentContact, err := s.client.Contact.FindUnique(
-				db.Contact.ID.Equals(contact.ID),
-			).Exec(bgCtx)
-			if err != nil || currentContact.IsSent {
-				continue
-			}
+	campaign, err := s.client.Campaign.CreateOne(
+		db.Campaign.TotalContacts.Set(len(contacts)),
+		db.Campaign.User.Link(db.User.ID.Equals(userId)),
+		db.Campaign.Organization.Link(db.Organization.ID.Equals(membership.OrganizationID)),
+		params...,
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create campaign: %w", err)
+	}
 
-			// Prepare Email Body
-			body := template.Body
-			body = strings.ReplaceAll(body, "{name}", contact.Name)
-			body = strings.ReplaceAll(body, "{company}", contact.CompanyName)
-			body = strings.ReplaceAll(body, "{hr_name}", user.Name)
-
-			// Prepare Request
-			req := models.SendEmailRequest{
-				SenderEmail:    professionalEmail,
-				SenderPassword: mailAppPassword,
-				RecipientEmail: contact.Email,
-				Subject:        template.Subject,
-				Body:           body,
-			}
+	for _, accountId := range senderAccountIDs {
+		_, err := s.client.CampaignSender.CreateOne(
+			db.CampaignSender.Campaign.Link(db.Campaign.ID.Equals(campaign.ID)),
+			db.CampaignSender.SenderAccount.Link(db.SenderAccount.ID.Equals(accountId)),
+		).Exec(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select sender account: %w", err)
+		}
+	}
 
-			// Send Email
-			err = s.SendEmail(req)
-			if err != nil {
-				fmt.Printf("Failed to send email to %s: %v\n", contact.Email, err)
-				// Continue to next contact even if one fails
-			} else {
-				// Update Contact Status
-				_, err := s.client.Contact.FindUnique(
-					db.Contact.ID.Equals(contact.ID),
-				).Update(
-					db.Contact.IsSent.Set(true),
-				).Exec(bgCtx)
-				if err != nil {
-					fmt.Printf("Failed to update status for %s: %v\n", contact.Email, err)
+	description := fmt.Sprintf("Started campaign to %d contacts", len(contacts))
+	metadata := map[string]interface{}{
+		"total_contacts":  len(contacts),
+		"sender_accounts": len(senderAccountIDs),
+	}
+	if sequence != nil {
+		metadata["sequence_id"] = sequence.ID
+	}
+	if scheduled {
+		description = fmt.Sprintf("Scheduled campaign to %d contacts", len(contacts))
+		metadata["scheduled_at"] = req.ScheduledAt
+	}
+	s.activities.Record(ctx, userId, ActivityEvent{
+		Type:        db.ActivityTypeCampaignStarted,
+		Description: description,
+		TargetType:  models.ActivityTargetCampaign,
+		TargetID:    campaign.ID,
+		Metadata:    metadata,
+	})
+
+	// 5. Start Background Process
+	if !scheduled {
+		go s.runCampaign(campaign.ID, false)
+	}
+
+	response := toCampaignResponse(campaign)
+	response.SenderAccountIDs = senderAccountIDs
+	return response, nil
+}
+
+// RunSchedulerLoop calls StartDueCampaigns and SendDueFollowUps immediately
+// and then on every interval until ctx is cancelled. Campaigns left running
+// by a previous process are queued to resume first.
+func (s *EmailService) RunSchedulerLoop(ctx context.Context, interval time.Duration) {
+	if err := s.RequeueInterruptedCampaigns(ctx); err != nil {
+		fmt.Printf("Failed to requeue interrupted campaigns: %v\n", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.StartDueCampaigns(ctx); err != nil {
+			fmt.Printf("Failed to start scheduled campaigns: %v\n", err)
+		}
+		if err := s.SendDueFollowUps(ctx); err != nil {
+			fmt.Printf("Failed to send follow-ups: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RequeueInterruptedCampaigns pauses campaigns that are still running, due
+// right away, so StartDueCampaigns resumes them. It is called on startup,
+// when no runner of this process exists yet.
+func (s *EmailService) RequeueInterruptedCampaigns(ctx context.Context) error {
+	result, err := s.client.Campaign.FindMany(
+		db.Campaign.Status.Equals(db.CampaignStatusRunning),
+	).Update(
+		db.Campaign.Status.Set(db.CampaignStatusPaused),
+		db.Campaign.NextRunAt.Set(s.clock.Now()),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to requeue running campaigns: %w", err)
+	}
+	if result.Count > 0 {
+		fmt.Printf("Requeued %d interrupted campaigns\n", result.Count)
+	}
+
+	return nil
+}
+
+// StartDueCampaigns starts scheduled campaigns and resumes paused ones whose
+// next run is due. Campaigns of owners who may not send stay where they are.
+func (s *EmailService) StartDueCampaigns(ctx context.Context) error {
+	due, err := s.client.Campaign.FindMany(
+		db.Campaign.Status.In([]db.CampaignStatus{db.CampaignStatusScheduled, db.CampaignStatusPaused}),
+		db.Campaign.NextRunAt.Lte(s.clock.Now()),
+	).With(
+		db.Campaign.User.Fetch(),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch due campaigns: %w", err)
+	}
+
+	for _, campaign := range due {
+		if sendingBlocked(campaign.User()) != nil {
+			continue
+		}
+
+		params := []db.CampaignSetParam{
+			db.Campaign.Status.Set(db.CampaignStatusRunning),
+			db.Campaign.NextRunAt.SetOptional(nil),
+		}
+		firstRun := campaign.Status == db.CampaignStatusScheduled
+		if firstRun {
+			params = append(params, db.Campaign.StartedAt.Set(s.clock.Now()))
+		}
+
+		// Only the caller that moves the campaign out of its waiting status runs it
+		result, err := s.client.Campaign.FindMany(
+			db.Campaign.ID.Equals(campaign.ID),
+			db.Campaign.Status.Equals(campaign.Status),
+		).Update(
+			params...,
+		).Exec(ctx)
+		if err != nil {
+			fmt.Printf("Failed to start campaign %s: %v\n", campaign.ID, err)
+			continue
+		}
+		if result.Count == 0 {
+			continue
+		}
+
+		go s.runCampaign(campaign.ID, firstRun)
+	}
+
+	return nil
+}
+
+// runCampaign sends a running campaign to the organization's unsent contacts
+// that existed when it started and that it has not emailed yet. Contacts
+// outside the send window wait; when only they are left, the campaign pauses
+// until the next window opens and the scheduler runs it again. On the first
+// run of a scheduled campaign the contacts are counted again.
+func (s *EmailService) runCampaign(campaignId string, recount bool) {
+	// Create a new context for the background job
+	ctx := context.Background()
+
+	campaign, err := s.client.Campaign.FindUnique(
+		db.Campaign.ID.Equals(campaignId),
+	).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to fetch campaign %s: %v\n", campaignId, err)
+		return
+	}
+	userId := campaign.UserID
+
+	user, err := s.client.User.FindUnique(
+		db.User.ID.Equals(userId),
+	).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to fetch user of campaign %s: %v\n", campaignId, err)
+		s.pauseCampaign(ctx, campaignId)
+		return
+	}
+	if err := sendingBlocked(user); err != nil {
+		// The scheduler resumes the campaign once the owner may send again
+		fmt.Printf("Paused campaign %s: %v\n", campaignId, err)
+		s.pauseCampaign(ctx, campaignId)
+		return
+	}
+
+	template, err := activeTemplate(ctx, s.client, campaign.OrganizationID)
+	if err != nil {
+		fmt.Printf("Failed to fetch template of campaign %s: %v\n", campaignId, err)
+		s.finishCampaign(ctx, userId, campaignId)
+		return
+	}
+
+	windows, err := campaignSendWindows(campaign)
+	if err != nil {
+		fmt.Printf("Failed to read send windows of campaign %s: %v\n", campaignId, err)
+		s.finishCampaign(ctx, userId, campaignId)
+		return
+	}
+	location := campaignLocation(campaign)
+
+	senderAccounts, err := s.client.CampaignSender.FindMany(
+		db.CampaignSender.CampaignID.Equals(campaignId),
+	).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to fetch sender accounts of campaign %s: %v\n", campaignId, err)
+		s.pauseCampaign(ctx, campaignId)
+		return
+	}
+	useSenderAccounts := len(senderAccounts) > 0
+
+	contacts, err := s.client.Contact.FindMany(
+		db.Contact.OrganizationID.Equals(campaign.OrganizationID),
+		db.Contact.IsSent.Equals(false),
+		db.Contact.DeletedAt.IsNull(),
+		db.Contact.UnsubscribedAt.IsNull(),
+		db.Contact.CreatedAt.Lte(campaign.StartedAt),
+		db.Contact.EmailLogs.None(
+			db.EmailLog.CampaignID.Equals(campaignId),
+		),
+	).OrderBy(
+		db.Contact.CreatedAt.Order(db.SortOrderAsc),
+	).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to fetch contacts of campaign %s: %v\n", campaignId, err)
+		s.pauseCampaign(ctx, campaignId)
+		return
+	}
+
+	// Skipped contacts are considered again on every run, so they are counted again
+	counts := []db.CampaignSetParam{db.Campaign.SkippedCount.Set(0)}
+	if recount {
+		counts = append(counts, db.Campaign.TotalContacts.Set(len(contacts)))
+	}
+	s.updateCampaignCounts(ctx, campaignId, counts...)
+
+	pace := campaignPacing(campaign)
+	pacer := s.campaignPacer(ctx, campaign)
+
+	lastSenderId := ""
+	var resumeAt time.Time
+
+	// outsideWindow reports whether it is outside the send window in loc and
+	// remembers when the campaign should run again
+	outsideWindow := func(loc *time.Location) bool {
+		now := s.clock.Now()
+		opens := nextWindowOpen(windows, loc, now)
+		if !opens.After(now) {
+			return false
+		}
+		if resumeAt.IsZero() || opens.Before(resumeAt) {
+			resumeAt = opens
+		}
+		return true
+	}
+
+	for _, contact := range contacts {
+		// Stop when the campaign was paused, e.g. because its owner was suspended
+		if !s.campaignRunning(ctx, campaignId) {
+			return
+		}
+
+		// Double check if contact is still unsent (in case of race conditions or manual updates)
+		currentContact, err := s.client.Contact.FindUnique(
+			db.Contact.ID.Equals(contact.ID),
+		).Exec(ctx)
+		if err != nil || currentContact.IsSent {
+			continue
+		}
+		// Skip contacts moved to the trash after the campaign started
+		if _, deleted := currentContact.DeletedAt(); deleted {
+			continue
+		}
+		// Skip contacts that unsubscribed after the campaign started
+		if _, unsubscribed := currentContact.UnsubscribedAt(); unsubscribed {
+			continue
+		}
+
+		// Contacts outside the send window wait for the next run
+		contactLocation := recipientLocation(campaign, currentContact, location)
+		if outsideWindow(contactLocation) {
+			continue
+		}
+
+		// Respect the per-company daily limit; skipped contacts stay unsent for a later campaign
+		if limit, ok := campaign.MaxPerCompanyPerDay(); ok && s.companyLimitReached(ctx, currentContact, limit) {
+			s.updateCampaignCounts(ctx, campaignId, db.Campaign.SkippedCount.Increment(1))
+			continue
+		}
+
+		// Space out emails; the window may close while waiting
+		pacer.Wait()
+		if outsideWindow(contactLocation) {
+			continue
+		}
+
+		// The owner's daily limit counts every mailbox; the campaign resumes tomorrow (UTC)
+		if s.dailyLimitReached(ctx, user) {
+			s.pauseCampaignUntil(ctx, campaignId, nextUTCDay(s.clock.Now()))
+			return
+		}
+
+		// Prepare Email Body
+		body := personalize(template.Body, currentContact, user)
+
+		// Pick the mailbox; credentials are looked up per email since
+		// OAuth access tokens expire during long campaigns
+		var req models.SendEmailRequest
+		senderAccountId := ""
+		if useSenderAccounts {
+			account, pickErr := s.senders.nextSender(ctx, campaignId, campaign.SenderRotation, lastSenderId, pace.warmUpDays, s.clock.Now())
+			if errors.Is(pickErr, ErrNoSenderAvailable) {
+				// Every mailbox reached its daily limit (UTC); the campaign resumes tomorrow
+				s.pauseCampaignUntil(ctx, campaignId, nextUTCDay(s.clock.Now()))
+				return
+			}
+			err = pickErr
+			if err == nil {
+				senderAccountId, lastSenderId = account.ID, account.ID
+				req, err = senderAccountCredentials(account)
+				if signature, ok := account.Signature(); ok {
+					body += "\n\n" + signature
 				}
 			}
+		} else {
+			req, err = s.senderCredentialsFor(ctx, userId)
+		}
+
+		// Prepare Request
+		req.RecipientEmail = currentContact.Email
+		req.Subject = template.Subject
+		req.Body = body
+		req.MessageID = newMessageID(req.SenderEmail)
+
+		// Send Email; only an attempt counts against the pacing
+		if err == nil {
+			err = s.SendEmail(req)
+			pacer.Sent()
+		}
+		s.logEmail(ctx, userId, campaignId, senderAccountId, currentContact.ID, req, err)
+		if err != nil {
+			fmt.Printf("Failed to send email to %s: %v\n", currentContact.Email, err)
+			s.updateCampaignCounts(ctx, campaignId, db.Campaign.FailedCount.Increment(1))
+			// Continue to next contact even if one fails
+		} else {
+			s.updateCampaignCounts(ctx, campaignId, db.Campaign.SentCount.Increment(1))
+			// Update Contact Status
+			s.markContacted(ctx, currentContact)
+			s.enrollContact(ctx, campaign, currentContact.ID, senderAccountId)
+		}
+	}
 
-			// Wait for 2 minutes before next email
-			time.Sleep(2 * time.Minute)
+	if !resumeAt.IsZero() {
+		s.updateCampaignCounts(ctx, campaignId,
+			db.Campaign.Status.Set(db.CampaignStatusPaused),
+			db.Campaign.NextRunAt.Set(resumeAt),
+		)
+		return
+	}
+
+	s.finishCampaign(ctx, userId, campaignId)
+}
+
+// campaignLocation returns the time zone of a campaign's send windows
+func campaignLocation(campaign *db.CampaignModel) *time.Location {
+	if timezone, ok := campaign.Timezone(); ok {
+		if loc, err := loadTimezone(timezone); err == nil {
+			return loc
 		}
-	}()
+	}
+
+	return time.UTC
+}
+
+// campaignPacer creates the pacer of a campaign run. Emails of earlier runs
+// in the last hour count against the hourly limit.
+func (s *EmailService) campaignPacer(ctx context.Context, campaign *db.CampaignModel) *Pacer {
+	recentLogs, err := s.client.EmailLog.FindMany(
+		db.EmailLog.CampaignID.Equals(campaign.ID),
+		db.EmailLog.CreatedAt.Gte(s.clock.Now().Add(-time.Hour)),
+	).OrderBy(
+		db.EmailLog.CreatedAt.Order(db.SortOrderAsc),
+	).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to fetch recent emails of campaign %s: %v\n", campaign.ID, err)
+	}
+	recent := make([]time.Time, 0, len(recentLogs))
+	for _, log := range recentLogs {
+		recent = append(recent, log.CreatedAt)
+	}
+
+	return NewPacer(campaignPacing(campaign), s.clock, newRandom(), recent)
+}
+
+// personalize fills in the placeholders of a template body
+func personalize(body string, contact *db.ContactModel, user *db.UserModel) string {
+	body = strings.ReplaceAll(body, "{name}", contact.Name)
+	body = strings.ReplaceAll(body, "{company}", contact.CompanyName)
+	body = strings.ReplaceAll(body, "{hr_name}", user.Name)
+
+	return body
+}
+
+// recipientLocation returns the contact's time zone when the campaign sends
+// in recipients' time zones and it is known, otherwise the campaign's
+func recipientLocation(campaign *db.CampaignModel, contact *db.ContactModel, fallback *time.Location) *time.Location {
+	if !campaign.UseRecipientTimezone {
+		return fallback
+	}
+	timezone, ok := contact.Timezone()
+	if !ok {
+		return fallback
+	}
+	loc, err := loadTimezone(timezone)
+	if err != nil {
+		return fallback
+	}
+
+	return loc
+}
+
+// updateCampaignCounts applies a counter update to a running campaign
+func (s *EmailService) updateCampaignCounts(ctx context.Context, campaignId string, params ...db.CampaignSetParam) {
+	_, err := s.client.Campaign.FindUnique(
+		db.Campaign.ID.Equals(campaignId),
+	).Update(
+		params...,
+	).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to update campaign %s: %v\n", campaignId, err)
+	}
+}
+
+// pauseCampaign moves a running campaign back to paused, due right away
+func (s *EmailService) pauseCampaign(ctx context.Context, campaignId string) {
+	s.pauseCampaignUntil(ctx, campaignId, s.clock.Now())
+}
+
+// pauseCampaignUntil moves a running campaign back to paused, due at the given time
+func (s *EmailService) pauseCampaignUntil(ctx context.Context, campaignId string, at time.Time) {
+	_, err := s.client.Campaign.FindMany(
+		db.Campaign.ID.Equals(campaignId),
+		db.Campaign.Status.Equals(db.CampaignStatusRunning),
+	).Update(
+		db.Campaign.Status.Set(db.CampaignStatusPaused),
+		db.Campaign.NextRunAt.Set(at),
+	).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to pause campaign %s: %v\n", campaignId, err)
+	}
+}
+
+// campaignRunning reports whether a campaign still has status running. When
+// it cannot be checked the runner carries on.
+func (s *EmailService) campaignRunning(ctx context.Context, campaignId string) bool {
+	campaign, err := s.client.Campaign.FindUnique(
+		db.Campaign.ID.Equals(campaignId),
+	).Exec(ctx)
+	if err != nil {
+		return !errors.Is(err, db.ErrNotFound)
+	}
+
+	return campaign.Status == db.CampaignStatusRunning
+}
+
+// sendingBlocked returns why a user's campaigns and follow-ups may not send,
+// or nil when they may
+func sendingBlocked(user *db.UserModel) error {
+	if isSuspended(user) {
+		return ErrAccountSuspended
+	}
+	if !isEmailVerified(user) {
+		return ErrEmailNotVerified
+	}
 
 	return nil
 }
 
+// companyLimitReached reports whether the contact's company already received
+// the maximum number of emails today (UTC). Companies belong to an
+// organization, so emails from every member count.
+func (s *EmailService) companyLimitReached(ctx context.Context, contact *db.ContactModel, limit int) bool {
+	companyId, ok := contact.CompanyID()
+	if !ok {
+		return false
+	}
+
+	logs, err := s.client.EmailLog.FindMany(
+		db.EmailLog.Status.Equals(db.EmailStatusSent),
+		db.EmailLog.CreatedAt.Gte(startOfUTCDay(s.clock.Now())),
+		db.EmailLog.Contact.Where(
+			db.Contact.CompanyID.Equals(companyId),
+		),
+	).Take(limit).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to check company limit for %s: %v\n", contact.Email, err)
+		return false
+	}
+
+	return len(logs) >= limit
+}
+
+// dailyLimitReached reports whether the user already sent their daily limit
+// of emails today (UTC), counting every mailbox they send from
+func (s *EmailService) dailyLimitReached(ctx context.Context, user *db.UserModel) bool {
+	logs, err := s.client.EmailLog.FindMany(
+		db.EmailLog.UserID.Equals(user.ID),
+		db.EmailLog.Status.Equals(db.EmailStatusSent),
+		db.EmailLog.CreatedAt.Gte(startOfUTCDay(s.clock.Now())),
+	).Take(user.DailyLimit).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to check daily limit of user %s: %v\n", user.ID, err)
+		return false
+	}
+
+	return len(logs) >= user.DailyLimit
+}
+
+// startOfUTCDay returns midnight (UTC) of the day t falls on
+func startOfUTCDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// nextUTCDay returns midnight (UTC) after t
+func nextUTCDay(t time.Time) time.Time {
+	return startOfUTCDay(t).Add(24 * time.Hour)
+}
+
+// finishCampaign marks a campaign as completed and logs the outcome
+func (s *EmailService) finishCampaign(ctx context.Context, userId string, campaignId string) {
+	campaign, err := s.client.Campaign.FindUnique(
+		db.Campaign.ID.Equals(campaignId),
+	).Update(
+		db.Campaign.Status.Set(db.CampaignStatusCompleted),
+		db.Campaign.FinishedAt.Set(s.clock.Now()),
+	).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to update campaign %s: %v\n", campaignId, err)
+		return
+	}
+
+	s.activities.Record(ctx, userId, ActivityEvent{
+		Type:        db.ActivityTypeCampaignFinished,
+		Description: fmt.Sprintf("Campaign finished: %d sent, %d failed, %d skipped", campaign.SentCount, campaign.FailedCount, campaign.SkippedCount),
+		TargetType:  models.ActivityTargetCampaign,
+		TargetID:    campaignId,
+		Metadata: map[string]interface{}{
+			"sent":    campaign.SentCount,
+			"failed":  campaign.FailedCount,
+			"skipped": campaign.SkippedCount,
+		},
+	})
+}
+
 // SendEmail sends an email using Gmail SMTP with custom dialer to avoid timeouts
 func (s *EmailService) SendEmail(req models.SendEmailRequest) error {
 	// Create a new message using gomail for proper MIME/Attachment handling
@@ -128,6 +747,19 @@ func (s *EmailService) SendEmail(req models.SendEmailRequest) error {
 	m.SetHeader("To", req.RecipientEmail)
 	m.SetHeader("Subject", req.Subject)
 
+	// Replies and follow-ups refer to the Message-ID, so callers store it
+	messageID := req.MessageID
+	if messageID == "" {
+		messageID = newMessageID(req.SenderEmail)
+	}
+	m.SetHeader("Message-ID", "<"+messageID+">")
+	if req.InReplyTo != "" {
+		m.SetHeader("In-Reply-To", "<"+req.InReplyTo+">")
+	}
+	if len(req.References) > 0 {
+		m.SetHeader("References", formatMessageIDs(req.References))
+	}
+
 	// Convert newlines to HTML breaks
 	htmlBody := strings.ReplaceAll(req.Body, "\n", "<br>")
 	m.SetBody("text/html", htmlBody)
@@ -136,41 +768,72 @@ func (s *EmailService) SendEmail(req models.SendEmailRequest) error {
 		m.Attach(filePath)
 	}
 
-	// Configure SMTP Settings
-	smtpHost := "smtp.gmail.com"
-	smtpPort := "465" // SMTPS (Implicit SSL)
-
-	// Custom Dialer with Timeout (Critical for Render)
-	d := net.Dialer{
-		Timeout: 30 * time.Second,
+	// Configure SMTP Settings; Gmail unless the sender account has its own server
+	smtpHost := req.SMTPHost
+	if smtpHost == "" {
+		smtpHost = defaultSMTPHost
+	}
+	smtpPort := req.SMTPPort
+	if smtpPort == 0 {
+		smtpPort = defaultSMTPPort // SMTPS (Implicit SSL)
 	}
+	addr := net.JoinHostPort(smtpHost, strconv.Itoa(smtpPort))
+
+	// Custom Dialer with Timeout (Critical for Render) that refuses internal addresses
+	d := mailDialer()
 
-	// 1. Connect to SMTP Server using TCP4 (IPv4 Only) and Implicit TLS
-	// Use tls.DialWithDialer to combine custom dialer (timeout) with SSL
 	tlsConfig := &tls.Config{
 		ServerName: smtpHost,
 	}
 
-	conn, err := tls.DialWithDialer(&d, "tcp4", smtpHost+":"+smtpPort, tlsConfig)
+	// 1. Connect to SMTP Server using TCP4 (IPv4 Only). Port 465 uses implicit
+	// TLS; other ports must upgrade the connection with STARTTLS.
+	var conn net.Conn
+	var err error
+	if smtpPort == 465 {
+		// Use tls.DialWithDialer to combine custom dialer (timeout) with SSL
+		conn, err = tls.DialWithDialer(d, "tcp4", addr, tlsConfig)
+	} else {
+		conn, err = d.Dial("tcp4", addr)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to dial smtp (SMTPS/465): %w", err)
+		return fmt.Errorf("failed to dial smtp (%s): %w", addr, err)
 	}
 	defer conn.Close()
 
-	// 2. Initialize SMTP Client (already secure)
+	// 2. Initialize SMTP Client
 	c, err := smtp.NewClient(conn, smtpHost)
 	if err != nil {
 		return fmt.Errorf("failed to create smtp client: %w", err)
 	}
 	defer c.Quit()
 
-	// 3. Authenticate
-	auth := smtp.PlainAuth(
-		"",
-		req.SenderEmail,
-		req.SenderPassword,
-		smtpHost,
-	)
+	if smtpPort != 465 {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("smtp server %s does not support STARTTLS", addr)
+		}
+		if err = c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	// 3. Authenticate with the Gmail OAuth access token when the account is
+	// connected, otherwise with the app password
+	var auth smtp.Auth
+	username := req.SenderUsername
+	if username == "" {
+		username = req.SenderEmail
+	}
+	if req.SenderAccessToken != "" {
+		auth = &xoauth2Auth{username: username, accessToken: req.SenderAccessToken}
+	} else {
+		auth = smtp.PlainAuth(
+			"",
+			username,
+			req.SenderPassword,
+			smtpHost,
+		)
+	}
 	if err = c.Auth(auth); err != nil {
 		return fmt.Errorf("failed to authenticate: %w", err)
 	}
@@ -180,6 +843,11 @@ func (s *EmailService) SendEmail(req models.SendEmailRequest) error {
 		return fmt.Errorf("failed to set sender: %w", err)
 	}
 	if err = c.Rcpt(req.RecipientEmail); err != nil {
+		// A permanent rejection means the address bounces
+		var smtpErr *textproto.Error
+		if errors.As(err, &smtpErr) && smtpErr.Code >= 500 {
+			return fmt.Errorf("%w: %w", ErrRecipientRejected, err)
+		}
 		return fmt.Errorf("failed to set recipient: %w", err)
 	}
 
@@ -201,10 +869,9 @@ func (s *EmailService) SendEmail(req models.SendEmailRequest) error {
 	return nil
 }
 
-// SendEmailForUser sends an email synchronously, fetching credentials from DB
-func (s *EmailService) SendEmailForUser(userId string, req models.SendEmailRequest) error {
-	ctx := context.Background()
-
+// SendEmailForUser sends an email synchronously, fetching credentials from DB.
+// Send-to-all and contact lookups use the contacts of the selected organization.
+func (s *EmailService) SendEmailForUser(ctx context.Context, userId string, organizationId string, req models.SendEmailRequest) error {
 	// 1. Fetch User Credentials
 	user, err := s.client.User.FindUnique(
 		db.User.ID.Equals(userId),
@@ -212,22 +879,35 @@ func (s *EmailService) SendEmailForUser(userId string, req models.SendEmailReque
 	if err != nil {
 		return fmt.Errorf("failed to fetch user: %w", err)
 	}
+	if !isEmailVerified(user) {
+		return ErrEmailNotVerified
+	}
+	if s.dailyLimitReached(ctx, user) {
+		return ErrDailyLimitReached
+	}
 
-	professionalEmail, ok1 := user.ProfessionalEmail()
-	mailAppPassword, ok2 := user.MailAppPassword()
-	if !ok1 || !ok2 || professionalEmail == "" || mailAppPassword == "" {
-		return fmt.Errorf("user email credentials not configured. Please configure them in settings")
+	sender, err := s.senderCredentials(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	membership, err := resolveMembership(ctx, s.client, userId, organizationId)
+	if err != nil {
+		return err
 	}
 
 	// 2. Set Credentials in Request
-	req.SenderEmail = professionalEmail
-	req.SenderPassword = mailAppPassword
+	req.SenderEmail = sender.SenderEmail
+	req.SenderPassword = sender.SenderPassword
+	req.SenderAccessToken = sender.SenderAccessToken
 
 	// 3. Send Logic
 	if req.SendToAll {
 		// Fetch all contacts (reuse existing logic from StartEmailCampaign or just basic fetch)
 		contacts, err := s.client.Contact.FindMany(
-			db.Contact.UserID.Equals(userId),
+			db.Contact.OrganizationID.Equals(membership.OrganizationID),
+			db.Contact.DeletedAt.IsNull(),
+			db.Contact.UnsubscribedAt.IsNull(),
 		).Exec(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to fetch contacts: %w", err)
@@ -235,14 +915,24 @@ func (s *EmailService) SendEmailForUser(userId string, req models.SendEmailReque
 
 		// fmt.Printf("Starting synchronous bulk email to %d contacts\n", len(contacts))
 
-		for i, contact := range contacts {
+		// Small delay to be nice to Gmail
+		pacer := NewPacer(pacing{minDelay: bulkSendDelay, maxDelay: bulkSendDelay}, s.clock, newRandom(), nil)
+		for _, contact := range contacts {
 			emailReq := req
 			emailReq.RecipientEmail = contact.Email
+			emailReq.MessageID = newMessageID(emailReq.SenderEmail)
 
 			// Add basic personalization if simple body
 			// (StartEmailCampaign does this better with templates, but respecting existing structure)
 
-			if err := s.SendEmail(emailReq); err != nil {
+			pacer.Wait()
+			if s.dailyLimitReached(ctx, user) {
+				return fmt.Errorf("%w: stopped before %s", ErrDailyLimitReached, contact.Email)
+			}
+			err := s.SendEmail(emailReq)
+			pacer.Sent()
+			s.logEmail(ctx, userId, "", "", contact.ID, emailReq, err)
+			if err != nil {
 				fmt.Printf("Error sending email to %s: %v\n", contact.Email, err)
 				// Don't abort entire batch on single failure? Or return error?
 				// For synchronous HTTP response, maybe we should return error if critical,
@@ -252,18 +942,198 @@ func (s *EmailService) SendEmailForUser(userId string, req models.SendEmailReque
 				// User said: "return error" in "Fix #1".
 				return fmt.Errorf("failed to send to %s: %w", contact.Email, err)
 			}
-
-			// Small delay to be nice to Gmail
-			if i < len(contacts)-1 {
-				time.Sleep(2 * time.Second) // 2 seconds delay
-			}
 		}
 	} else {
 		// Single Email
-		if err := s.SendEmail(req); err != nil {
+		req.MessageID = newMessageID(req.SenderEmail)
+		err := s.SendEmail(req)
+
+		// Link the attempt to the contact's timeline when the recipient is a known contact
+		contactId := ""
+		if contact, findErr := s.client.Contact.FindFirst(
+			db.Contact.OrganizationID.Equals(membership.OrganizationID),
+			db.Contact.Email.Equals(req.RecipientEmail),
+			db.Contact.DeletedAt.IsNull(),
+		).Exec(ctx); findErr == nil {
+			contactId = contact.ID
+		}
+		s.logEmail(ctx, userId, "", "", contactId, req, err)
+
+		if err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+// senderCredentials returns the user's SMTP login as the sender fields of a
+// request. A connected Gmail account is preferred and sends with an OAuth
+// access token; otherwise the app password is used. It is stored encrypted
+// and is only decrypted here, right before sending.
+func (s *EmailService) senderCredentials(ctx context.Context, user *db.UserModel) (models.SendEmailRequest, error) {
+	oauthEmail, accessToken, connected, err := s.mailOAuth.AccessToken(ctx, user)
+	if err != nil {
+		return models.SendEmailRequest{}, err
+	}
+	if connected {
+		return models.SendEmailRequest{
+			SenderEmail:       oauthEmail,
+			SenderAccessToken: accessToken,
+		}, nil
+	}
+
+	professionalEmail, ok1 := user.ProfessionalEmail()
+	encryptedPassword, ok2 := user.MailAppPassword()
+	if !ok1 || !ok2 || professionalEmail == "" || encryptedPassword == "" {
+		return models.SendEmailRequest{}, ErrCredentialsNotConfigured
+	}
+
+	mailAppPassword, err := utils.DecryptSecret(encryptedPassword)
+	if err != nil {
+		return models.SendEmailRequest{}, fmt.Errorf("failed to decrypt mail app password: %w", err)
+	}
+
+	return models.SendEmailRequest{
+		SenderEmail:    professionalEmail,
+		SenderPassword: mailAppPassword,
+	}, nil
+}
+
+// senderCredentialsFor fetches the user and returns their current SMTP login
+func (s *EmailService) senderCredentialsFor(ctx context.Context, userId string) (models.SendEmailRequest, error) {
+	user, err := s.client.User.FindUnique(
+		db.User.ID.Equals(userId),
+	).Exec(ctx)
+	if err != nil {
+		return models.SendEmailRequest{}, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	return s.senderCredentials(ctx, user)
+}
+
+// logEmail records the outcome of a send attempt and the Message-ID of sent
+// emails; failures to log are not fatal
+func (s *EmailService) logEmail(ctx context.Context, userId string, campaignId string, senderAccountId string, contactId string, req models.SendEmailRequest, sendErr error) {
+	recipient, subject := req.RecipientEmail, req.Subject
+	params := []db.EmailLogSetParam{}
+	if contactId != "" {
+		params = append(params, db.EmailLog.Contact.Link(db.Contact.ID.Equals(contactId)))
+	}
+	if campaignId != "" {
+		params = append(params, db.EmailLog.Campaign.Link(db.Campaign.ID.Equals(campaignId)))
+	}
+	if senderAccountId != "" {
+		params = append(params, db.EmailLog.SenderAccount.Link(db.SenderAccount.ID.Equals(senderAccountId)))
+	}
+
+	status := db.EmailStatusSent
+	if sendErr != nil {
+		status = db.EmailStatusFailed
+		params = append(params, db.EmailLog.Error.Set(sendErr.Error()))
+	} else if req.MessageID != "" {
+		params = append(params, db.EmailLog.MessageID.Set(req.MessageID))
+	}
+
+	_, err := s.client.EmailLog.CreateOne(
+		db.EmailLog.Recipient.Set(recipient),
+		db.EmailLog.Subject.Set(subject),
+		db.EmailLog.Status.Set(status),
+		db.EmailLog.User.Link(db.User.ID.Equals(userId)),
+		params...,
+	).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to log email to %s: %v\n", recipient, err)
+	}
+
+	if sendErr != nil {
+		event := ActivityEvent{
+			Type:        db.ActivityTypeEmailSendFailed,
+			Description: "Failed to send email to " + recipient,
+			Metadata: map[string]interface{}{
+				"recipient": recipient,
+				"subject":   subject,
+				"error":     sendErr.Error(),
+			},
+		}
+		if contactId != "" {
+			event.TargetType = models.ActivityTargetContact
+			event.TargetID = contactId
+		}
+		if campaignId != "" {
+			event.Metadata["campaign_id"] = campaignId
+		}
+		s.activities.Record(ctx, userId, event)
+	}
+}
+
+// markContacted flags a contact as sent and moves new contacts to the contacted status
+func (s *EmailService) markContacted(ctx context.Context, contact *db.ContactModel) {
+	params := []db.ContactSetParam{db.Contact.IsSent.Set(true)}
+	if contact.Status == db.ContactStatusNew {
+		params = append(params, db.Contact.Status.Set(db.ContactStatusContacted))
+	}
+
+	_, err := s.client.Contact.FindUnique(
+		db.Contact.ID.Equals(contact.ID),
+	).Update(
+		params...,
+	).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to update status for %s: %v\n", contact.Email, err)
+		return
+	}
+
+	if contact.Status == db.ContactStatusNew {
+		if err := recordStatusChange(ctx, s.client, contact.ID, contact.Status, db.ContactStatusContacted); err != nil {
+			fmt.Printf("Failed to record status change for %s: %v\n", contact.Email, err)
+		}
+	}
+}
+
+func toCampaignResponse(c *db.CampaignModel) *models.CampaignResponse {
+	response := &models.CampaignResponse{
+		ID:                   c.ID,
+		Status:               strings.ToLower(string(c.Status)),
+		TotalContacts:        c.TotalContacts,
+		SentCount:            c.SentCount,
+		FailedCount:          c.FailedCount,
+		SkippedCount:         c.SkippedCount,
+		SenderRotation:       strings.ToLower(string(c.SenderRotation)),
+		UseRecipientTimezone: c.UseRecipientTimezone,
+		StartedAt:            c.StartedAt,
+	}
+	minDelay, maxDelay := c.MinDelaySeconds, c.MaxDelaySeconds
+	response.Pacing = models.Pacing{MinDelaySeconds: &minDelay, MaxDelaySeconds: &maxDelay}
+	if v, ok := c.MaxPerHour(); ok {
+		response.Pacing.MaxPerHour = &v
+	}
+	if v, ok := c.WarmUpDays(); ok {
+		response.Pacing.WarmUpDays = &v
+	}
+	if v, ok := c.MaxPerCompanyPerDay(); ok {
+		response.MaxPerCompanyPerDay = &v
+	}
+	if v, ok := c.FinishedAt(); ok {
+		response.FinishedAt = &v
+	}
+	if v, ok := c.SendWindows(); ok {
+		if err := json.Unmarshal(v, &response.SendWindows); err != nil {
+			fmt.Printf("Failed to decode send windows of campaign %s: %v\n", c.ID, err)
+		}
+	}
+	if v, ok := c.Timezone(); ok {
+		response.Timezone = v
+	}
+	if v, ok := c.ScheduledAt(); ok {
+		response.ScheduledAt = &v
+	}
+	if v, ok := c.NextRunAt(); ok {
+		response.NextRunAt = &v
+	}
+	if v, ok := c.SequenceID(); ok {
+		response.SequenceID = v
+	}
+
+	return response
+}
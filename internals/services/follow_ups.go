@@ -0,0 +1,407 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+)
+
+const (
+	// followUpLease is how long a claimed follow-up is held; when sending is
+	// interrupted the scheduler picks it up again afterwards
+	followUpLease = 30 * time.Minute
+	// followUpRetryDelay is the wait before a failed follow-up is tried again
+	followUpRetryDelay = time.Hour
+	// maxFollowUpAttempts stops a contact's follow-ups after this many failures in a row
+	maxFollowUpAttempts = 3
+)
+
+// followUpRun is what one worker needs to send the follow-ups of a campaign
+type followUpRun struct {
+	campaign          *db.CampaignModel
+	user              *db.UserModel
+	windows           []sendWindow
+	location          *time.Location
+	pacer             *Pacer
+	useSenderAccounts bool
+}
+
+// enrollContact enrolls a contact the campaign just emailed in the campaign's
+// sequence. The first follow-up is due after the first step's delay.
+func (s *EmailService) enrollContact(ctx context.Context, campaign *db.CampaignModel, contactId string, senderAccountId string) {
+	sequenceId, ok := campaign.SequenceID()
+	if !ok {
+		return
+	}
+
+	first, err := s.client.SequenceStep.FindFirst(
+		db.SequenceStep.SequenceID.Equals(sequenceId),
+	).OrderBy(
+		db.SequenceStep.Position.Order(db.SortOrderAsc),
+	).Exec(ctx)
+	if err != nil {
+		if !errors.Is(err, db.ErrNotFound) {
+			fmt.Printf("Failed to fetch first step of sequence %s: %v\n", sequenceId, err)
+		}
+		return
+	}
+
+	params := []db.SequenceEnrollmentSetParam{
+		db.SequenceEnrollment.NextSendAt.Set(s.clock.Now().Add(followUpDelay(first.DelayDays))),
+	}
+	if senderAccountId != "" {
+		params = append(params, db.SequenceEnrollment.SenderAccount.Link(db.SenderAccount.ID.Equals(senderAccountId)))
+	}
+
+	_, err = s.client.SequenceEnrollment.CreateOne(
+		db.SequenceEnrollment.Sequence.Link(db.Sequence.ID.Equals(sequenceId)),
+		db.SequenceEnrollment.Campaign.Link(db.Campaign.ID.Equals(campaign.ID)),
+		db.SequenceEnrollment.Contact.Link(db.Contact.ID.Equals(contactId)),
+		params...,
+	).Exec(ctx)
+	if err != nil {
+		// A contact is enrolled once per campaign
+		if _, ok := db.IsErrUniqueConstraint(err); ok {
+			return
+		}
+		fmt.Printf("Failed to enroll contact %s in sequence %s: %v\n", contactId, sequenceId, err)
+	}
+}
+
+// SendDueFollowUps starts sending the follow-ups that are due, with one
+// worker per campaign so each campaign keeps its pacing
+func (s *EmailService) SendDueFollowUps(ctx context.Context) error {
+	due, err := s.client.SequenceEnrollment.FindMany(
+		db.SequenceEnrollment.Status.Equals(db.EnrollmentStatusActive),
+		db.SequenceEnrollment.NextSendAt.Lte(s.clock.Now()),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch due follow-ups: %w", err)
+	}
+
+	for _, enrollment := range due {
+		if s.beginFollowUps(enrollment.CampaignID) {
+			go s.runFollowUps(enrollment.CampaignID)
+		}
+	}
+
+	return nil
+}
+
+// beginFollowUps reports whether the caller may start the follow-up worker of a campaign
+func (s *EmailService) beginFollowUps(campaignId string) bool {
+	s.followUpMu.Lock()
+	defer s.followUpMu.Unlock()
+
+	if s.followUpRuns[campaignId] {
+		return false
+	}
+	s.followUpRuns[campaignId] = true
+	return true
+}
+
+func (s *EmailService) endFollowUps(campaignId string) {
+	s.followUpMu.Lock()
+	defer s.followUpMu.Unlock()
+
+	delete(s.followUpRuns, campaignId)
+}
+
+// runFollowUps sends the due follow-ups of one campaign, oldest first, until
+// none are left. They use the campaign's pacing and send windows. Nothing is
+// sent while the owner is suspended or unverified.
+func (s *EmailService) runFollowUps(campaignId string) {
+	defer s.endFollowUps(campaignId)
+
+	// Create a new context for the background job
+	ctx := context.Background()
+
+	campaign, err := s.client.Campaign.FindUnique(
+		db.Campaign.ID.Equals(campaignId),
+	).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to fetch campaign %s: %v\n", campaignId, err)
+		return
+	}
+
+	user, err := s.client.User.FindUnique(
+		db.User.ID.Equals(campaign.UserID),
+	).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to fetch user of campaign %s: %v\n", campaignId, err)
+		return
+	}
+
+	// The same checks as for campaigns; the follow-ups stay due until the
+	// owner may send again
+	if err := sendingBlocked(user); err != nil {
+		fmt.Printf("Not sending follow-ups of campaign %s: %v\n", campaignId, err)
+		return
+	}
+
+	windows, err := campaignSendWindows(campaign)
+	if err != nil {
+		fmt.Printf("Failed to read send windows of campaign %s: %v\n", campaignId, err)
+		return
+	}
+
+	senderAccounts, err := s.client.CampaignSender.FindMany(
+		db.CampaignSender.CampaignID.Equals(campaignId),
+	).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to fetch sender accounts of campaign %s: %v\n", campaignId, err)
+		return
+	}
+
+	run := &followUpRun{
+		campaign:          campaign,
+		user:              user,
+		windows:           windows,
+		location:          campaignLocation(campaign),
+		pacer:             s.campaignPacer(ctx, campaign),
+		useSenderAccounts: len(senderAccounts) > 0,
+	}
+
+	// Every follow-up handled moves out of the due ones, so the loop ends
+	for {
+		enrollment, err := s.client.SequenceEnrollment.FindFirst(
+			db.SequenceEnrollment.CampaignID.Equals(campaignId),
+			db.SequenceEnrollment.Status.Equals(db.EnrollmentStatusActive),
+			db.SequenceEnrollment.NextSendAt.Lte(s.clock.Now()),
+		).OrderBy(
+			db.SequenceEnrollment.NextSendAt.Order(db.SortOrderAsc),
+		).Exec(ctx)
+		if errors.Is(err, db.ErrNotFound) {
+			return
+		}
+		if err != nil {
+			fmt.Printf("Failed to fetch follow-ups of campaign %s: %v\n", campaignId, err)
+			return
+		}
+
+		if err := s.sendFollowUp(ctx, run, enrollment); err != nil {
+			fmt.Printf("Failed to send follow-up to contact %s: %v\n", enrollment.ContactID, err)
+			return
+		}
+	}
+}
+
+// sendFollowUp sends the next step to an enrolled contact, or stops or
+// postpones the enrollment. Failed sends are retried a few times; only
+// database errors are returned.
+func (s *EmailService) sendFollowUp(ctx context.Context, run *followUpRun, enrollment *db.SequenceEnrollmentModel) error {
+	contact, err := s.client.Contact.FindUnique(
+		db.Contact.ID.Equals(enrollment.ContactID),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch contact: %w", err)
+	}
+
+	// Stop when the contact answered, unsubscribed or was moved to the trash
+	var reason db.EnrollmentStopReason
+	if _, ok := contact.UnsubscribedAt(); ok {
+		reason = db.EnrollmentStopReasonUnsubscribed
+	} else if hasReplied(contact.Status) {
+		reason = db.EnrollmentStopReasonReplied
+	} else if _, ok := contact.DeletedAt(); ok {
+		reason = db.EnrollmentStopReasonManual
+	}
+	if reason != "" {
+		_, err := stopEnrollments(ctx, s.client, reason, s.clock.Now(), db.SequenceEnrollment.ID.Equals(enrollment.ID))
+		return err
+	}
+
+	steps, err := s.client.SequenceStep.FindMany(
+		db.SequenceStep.SequenceID.Equals(enrollment.SequenceID),
+	).OrderBy(
+		db.SequenceStep.Position.Order(db.SortOrderAsc),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch sequence steps: %w", err)
+	}
+	// Steps may have been removed since the last follow-up
+	if enrollment.StepsSent >= len(steps) {
+		return s.updateEnrollment(ctx, enrollment.ID,
+			db.SequenceEnrollment.Status.Set(db.EnrollmentStatusCompleted),
+			db.SequenceEnrollment.NextSendAt.SetOptional(nil),
+		)
+	}
+	step := steps[enrollment.StepsSent]
+
+	// Outside the send window the follow-up waits until it opens
+	contactLocation := recipientLocation(run.campaign, contact, run.location)
+	if opens := nextWindowOpen(run.windows, contactLocation, s.clock.Now()); opens.After(s.clock.Now()) {
+		return s.updateEnrollment(ctx, enrollment.ID, db.SequenceEnrollment.NextSendAt.Set(opens))
+	}
+
+	account, err := s.followUpSender(ctx, run, enrollment)
+	if errors.Is(err, ErrNoSenderAvailable) {
+		// The mailbox reached its daily limit (UTC); try again tomorrow
+		return s.updateEnrollment(ctx, enrollment.ID, db.SequenceEnrollment.NextSendAt.Set(nextUTCDay(s.clock.Now())))
+	}
+	if err != nil {
+		return err
+	}
+
+	// Space out emails; the window may close while waiting
+	run.pacer.Wait()
+	if opens := nextWindowOpen(run.windows, contactLocation, s.clock.Now()); opens.After(s.clock.Now()) {
+		return s.updateEnrollment(ctx, enrollment.ID, db.SequenceEnrollment.NextSendAt.Set(opens))
+	}
+	// The owner reached their daily limit (UTC); try again tomorrow
+	if s.dailyLimitReached(ctx, run.user) {
+		return s.updateEnrollment(ctx, enrollment.ID, db.SequenceEnrollment.NextSendAt.Set(nextUTCDay(s.clock.Now())))
+	}
+
+	// Only the worker that claims the follow-up sends it
+	claimed, err := s.claimFollowUp(ctx, enrollment)
+	if err != nil || !claimed {
+		return err
+	}
+
+	// Prepare the email; problems with it count as a failed attempt
+	var req models.SendEmailRequest
+	senderAccountId := ""
+	body := personalize(step.Body, contact, run.user)
+	if account != nil {
+		senderAccountId = account.ID
+		req, err = senderAccountCredentials(account)
+		if signature, ok := account.Signature(); ok {
+			body += "\n\n" + signature
+		}
+	} else {
+		req, err = s.senderCredentialsFor(ctx, run.campaign.UserID)
+	}
+
+	// Follow-ups reply to the last email so mail clients show them in its thread
+	thread := s.campaignThread(ctx, run.campaign, contact.ID)
+	subject, ok := step.Subject()
+	if !ok {
+		subject = replySubject(thread.subject)
+	}
+	if len(thread.messageIDs) > 0 {
+		req.InReplyTo = thread.messageIDs[len(thread.messageIDs)-1]
+		req.References = thread.messageIDs
+	}
+
+	req.RecipientEmail = contact.Email
+	req.Subject = subject
+	req.Body = body
+	req.MessageID = newMessageID(req.SenderEmail)
+
+	if err == nil {
+		err = s.SendEmail(req)
+		run.pacer.Sent()
+	}
+	s.logEmail(ctx, run.campaign.UserID, run.campaign.ID, senderAccountId, contact.ID, req, err)
+
+	switch {
+	case err == nil:
+		params := []db.SequenceEnrollmentSetParam{
+			db.SequenceEnrollment.StepsSent.Increment(1),
+			db.SequenceEnrollment.FailedAttempts.Set(0),
+		}
+		if next := enrollment.StepsSent + 1; next < len(steps) {
+			params = append(params, db.SequenceEnrollment.NextSendAt.Set(s.clock.Now().Add(followUpDelay(steps[next].DelayDays))))
+		} else {
+			params = append(params,
+				db.SequenceEnrollment.Status.Set(db.EnrollmentStatusCompleted),
+				db.SequenceEnrollment.NextSendAt.SetOptional(nil),
+			)
+		}
+		return s.updateEnrollment(ctx, enrollment.ID, params...)
+
+	case errors.Is(err, ErrRecipientRejected):
+		// The address bounces, so every sequence of the contact stops
+		_, err := stopEnrollments(ctx, s.client, db.EnrollmentStopReasonBounced, s.clock.Now(), db.SequenceEnrollment.ContactID.Equals(contact.ID))
+		return err
+
+	case enrollment.FailedAttempts+1 >= maxFollowUpAttempts:
+		_, err := stopEnrollments(ctx, s.client, db.EnrollmentStopReasonFailed, s.clock.Now(), db.SequenceEnrollment.ID.Equals(enrollment.ID))
+		return err
+	}
+
+	return s.updateEnrollment(ctx, enrollment.ID,
+		db.SequenceEnrollment.FailedAttempts.Increment(1),
+		db.SequenceEnrollment.NextSendAt.Set(s.clock.Now().Add(followUpRetryDelay)),
+	)
+}
+
+// followUpSender returns the sender account a follow-up comes from: the one
+// that sent the first email, or the campaign's rotation when it was deleted.
+// It returns nil when the campaign sends from the user's mailbox.
+func (s *EmailService) followUpSender(ctx context.Context, run *followUpRun, enrollment *db.SequenceEnrollmentModel) (*db.SenderAccountModel, error) {
+	now := s.clock.Now()
+	warmUpDays := campaignPacing(run.campaign).warmUpDays
+
+	if accountId, ok := enrollment.SenderAccountID(); ok {
+		account, err := s.client.SenderAccount.FindUnique(
+			db.SenderAccount.ID.Equals(accountId),
+		).Exec(ctx)
+		if err != nil && !errors.Is(err, db.ErrNotFound) {
+			return nil, fmt.Errorf("failed to fetch sender account: %w", err)
+		}
+		if err == nil {
+			sentToday, err := s.senders.sentToday(ctx, []db.SenderAccountModel{*account}, now)
+			if err != nil {
+				return nil, err
+			}
+			if sentToday[account.ID] >= warmUpLimit(account, warmUpDays, now) {
+				return nil, ErrNoSenderAvailable
+			}
+			return account, nil
+		}
+	}
+
+	if !run.useSenderAccounts {
+		return nil, nil
+	}
+
+	return s.senders.nextSender(ctx, run.campaign.ID, run.campaign.SenderRotation, "", warmUpDays, now)
+}
+
+// claimFollowUp moves a due follow-up out of the due ones for the lease and
+// reports whether this caller did so
+func (s *EmailService) claimFollowUp(ctx context.Context, enrollment *db.SequenceEnrollmentModel) (bool, error) {
+	due, ok := enrollment.NextSendAt()
+	if !ok {
+		return false, nil
+	}
+
+	result, err := s.client.SequenceEnrollment.FindMany(
+		db.SequenceEnrollment.ID.Equals(enrollment.ID),
+		db.SequenceEnrollment.Status.Equals(db.EnrollmentStatusActive),
+		db.SequenceEnrollment.NextSendAt.Equals(due),
+	).Update(
+		db.SequenceEnrollment.NextSendAt.Set(s.clock.Now().Add(followUpLease)),
+	).Exec(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim follow-up: %w", err)
+	}
+
+	return result.Count > 0, nil
+}
+
+// updateEnrollment updates an enrollment unless it was stopped meanwhile
+func (s *EmailService) updateEnrollment(ctx context.Context, enrollmentId string, params ...db.SequenceEnrollmentSetParam) error {
+	_, err := s.client.SequenceEnrollment.FindMany(
+		db.SequenceEnrollment.ID.Equals(enrollmentId),
+		db.SequenceEnrollment.Status.Equals(db.EnrollmentStatusActive),
+	).Update(
+		params...,
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update enrollment: %w", err)
+	}
+
+	return nil
+}
+
+// followUpDelay converts a step's delay in days into a duration
+func followUpDelay(days int) time.Duration {
+	return time.Duration(days) * 24 * time.Hour
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// imapCommandTimeout bounds every command, including reading its response
+	imapCommandTimeout = time.Minute
+	// imapFetchBytes is how much of a message is fetched; enough for the
+	// headers and the start of the reply
+	imapFetchBytes = 64 * 1024
+)
+
+// errIMAPResponse is returned when the server sends something the client cannot parse
+var errIMAPResponse = errors.New("unexpected imap response")
+
+// imapClient speaks the part of IMAP4rev1 (RFC 3501) reply detection needs:
+// LOGIN, SELECT, UID SEARCH and UID FETCH of the start of a message
+type imapClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+	tag  int
+}
+
+// imapResponse is an untagged response. Literals (e.g. a fetched message)
+// are cut out of the line and kept in order.
+type imapResponse struct {
+	line     string
+	literals [][]byte
+}
+
+// dialIMAP connects to a server and reads its greeting. Port 993 uses
+// implicit TLS; other ports must upgrade the connection with STARTTLS.
+func dialIMAP(host string, port int) (*imapClient, error) {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	d := mailDialer()
+	tlsConfig := &tls.Config{
+		ServerName: host,
+	}
+
+	var conn net.Conn
+	var err error
+	if port == 993 {
+		conn, err = tls.DialWithDialer(d, "tcp4", addr, tlsConfig)
+	} else {
+		conn, err = d.Dial("tcp4", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial imap (%s): %w", addr, err)
+	}
+
+	c, err := newIMAPClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if port != 993 {
+		if _, err := c.command("STARTTLS"); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to start TLS: %w", err)
+		}
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to start TLS: %w", err)
+		}
+		c.conn = tlsConn
+		c.r = bufio.NewReader(tlsConn)
+	}
+
+	return c, nil
+}
+
+// newIMAPClient starts a session on an open connection by reading the greeting
+func newIMAPClient(conn net.Conn) (*imapClient, error) {
+	c := &imapClient{
+		conn: conn,
+		r:    bufio.NewReader(conn),
+	}
+
+	conn.SetDeadline(time.Now().Add(imapCommandTimeout))
+	greeting, err := c.readResponse()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read imap greeting: %w", err)
+	}
+	if !strings.HasPrefix(greeting.line, "* OK") && !strings.HasPrefix(greeting.line, "* PREAUTH") {
+		return nil, fmt.Errorf("%w: %s", errIMAPResponse, greeting.line)
+	}
+
+	return c, nil
+}
+
+// Login authenticates with a username and password
+func (c *imapClient) Login(username string, password string) error {
+	user, err := imapQuote(username)
+	if err != nil {
+		return err
+	}
+	pass, err := imapQuote(password)
+	if err != nil {
+		return err
+	}
+	if _, err := c.command("LOGIN " + user + " " + pass); err != nil {
+		return fmt.Errorf("failed to authenticate: %w", err)
+	}
+
+	return nil
+}
+
+// Select opens a mailbox and returns its UIDVALIDITY
+func (c *imapClient) Select(mailbox string) (uint32, error) {
+	name, err := imapQuote(mailbox)
+	if err != nil {
+		return 0, err
+	}
+	responses, err := c.command("SELECT " + name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to select %s: %w", mailbox, err)
+	}
+
+	for _, r := range responses {
+		_, rest, ok := strings.Cut(r.line, "[UIDVALIDITY ")
+		if !ok {
+			continue
+		}
+		value, _, _ := strings.Cut(rest, "]")
+		uidValidity, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s", errIMAPResponse, r.line)
+		}
+		return uint32(uidValidity), nil
+	}
+
+	return 0, fmt.Errorf("%w: no UIDVALIDITY for %s", errIMAPResponse, mailbox)
+}
+
+// SearchUIDs returns the UIDs of the messages matching the criteria, ascending
+func (c *imapClient) SearchUIDs(criteria string) ([]uint32, error) {
+	responses, err := c.command("UID SEARCH " + criteria)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search mailbox: %w", err)
+	}
+
+	uids := []uint32{}
+	for _, r := range responses {
+		rest, ok := strings.CutPrefix(r.line, "* SEARCH")
+		if !ok {
+			continue
+		}
+		for _, field := range strings.Fields(rest) {
+			uid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", errIMAPResponse, r.line)
+			}
+			uids = append(uids, uint32(uid))
+		}
+	}
+	sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+
+	return uids, nil
+}
+
+// FetchMessage returns the first imapFetchBytes of a message without marking it as read
+func (c *imapClient) FetchMessage(uid uint32) ([]byte, error) {
+	responses, err := c.command(fmt.Sprintf("UID FETCH %d (UID BODY.PEEK[]<0.%d>)", uid, imapFetchBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch message %d: %w", uid, err)
+	}
+
+	// Servers may add unrelated FETCH responses, e.g. flag changes
+	for _, r := range responses {
+		if strings.Contains(r.line, " FETCH ") && len(r.literals) > 0 {
+			return r.literals[0], nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: message %d was not returned", errIMAPResponse, uid)
+}
+
+// Logout ends the session and closes the connection
+func (c *imapClient) Logout() {
+	c.command("LOGOUT")
+	c.conn.Close()
+}
+
+// command sends a command and returns its untagged responses. A NO or BAD
+// completion is returned as an error with the server's text.
+func (c *imapClient) command(cmd string) ([]imapResponse, error) {
+	c.tag++
+	tag := "A" + strconv.Itoa(c.tag)
+
+	c.conn.SetDeadline(time.Now().Add(imapCommandTimeout))
+	if _, err := io.WriteString(c.conn, tag+" "+cmd+"\r\n"); err != nil {
+		return nil, err
+	}
+
+	responses := []imapResponse{}
+	for {
+		r, err := c.readResponse()
+		if err != nil {
+			return nil, err
+		}
+
+		rest, ok := strings.CutPrefix(r.line, tag+" ")
+		if !ok {
+			responses = append(responses, r)
+			continue
+		}
+		status, text, _ := strings.Cut(rest, " ")
+		if !strings.EqualFold(status, "OK") {
+			return nil, fmt.Errorf("imap %s: %s", strings.ToUpper(status), text)
+		}
+		return responses, nil
+	}
+}
+
+// readResponse reads one response line, including any literals it contains
+func (c *imapClient) readResponse() (imapResponse, error) {
+	var r imapResponse
+	var line strings.Builder
+	for {
+		part, err := c.r.ReadString('\n')
+		if err != nil {
+			return r, err
+		}
+		part = strings.TrimRight(part, "\r\n")
+
+		size, ok := literalSize(part)
+		if !ok {
+			line.WriteString(part)
+			r.line = line.String()
+			return r, nil
+		}
+		if size > imapFetchBytes {
+			return r, fmt.Errorf("%w: literal of %d bytes", errIMAPResponse, size)
+		}
+
+		literal := make([]byte, size)
+		if _, err := io.ReadFull(c.r, literal); err != nil {
+			return r, err
+		}
+		line.WriteString(part[:strings.LastIndexByte(part, '{')])
+		r.literals = append(r.literals, literal)
+	}
+}
+
+// literalSize returns n when a line ends with a literal announcement {n}
+func literalSize(line string) (int, bool) {
+	if !strings.HasSuffix(line, "}") {
+		return 0, false
+	}
+	start := strings.LastIndexByte(line, '{')
+	if start < 0 {
+		return 0, false
+	}
+	size, err := strconv.Atoi(line[start+1 : len(line)-1])
+	if err != nil || size < 0 {
+		return 0, false
+	}
+
+	return size, true
+}
+
+// imapQuote formats a string argument. Line breaks cannot be quoted.
+func imapQuote(value string) (string, error) {
+	if strings.ContainsAny(value, "\r\n") {
+		return "", errors.New("imap arguments cannot contain line breaks")
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+
+	return `"` + value + `"`, nil
+}
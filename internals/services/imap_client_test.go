@@ -0,0 +1,153 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLiteralSize(t *testing.T) {
+	tests := []struct {
+		line   string
+		size   int
+		isSize bool
+	}{
+		{"* 1 FETCH (UID 7 BODY[]<0> {42}", 42, true},
+		{"* 1 FETCH (BODY[] {0}", 0, true},
+		{"{12}", 12, true},
+		{"* OK [UIDVALIDITY 3] UIDs valid", 0, false},
+		{"* 1 FETCH (FLAGS (\\Seen))", 0, false},
+		{"* LIST () \"/\" {x}", 0, false},
+		{"* LIST () \"/\" {-1}", 0, false},
+		{"* LIST () \"/\" }", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		size, ok := literalSize(tt.line)
+		if size != tt.size || ok != tt.isSize {
+			t.Errorf("literalSize(%q) = %d, %v; want %d, %v", tt.line, size, ok, tt.size, tt.isSize)
+		}
+	}
+}
+
+func newTestIMAPReader(data string) *imapClient {
+	return &imapClient{r: bufio.NewReader(strings.NewReader(data))}
+}
+
+func TestReadResponseLine(t *testing.T) {
+	c := newTestIMAPReader("* OK [UIDVALIDITY 3] UIDs valid\r\nA1 OK done\r\n")
+
+	r, err := c.readResponse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.line != "* OK [UIDVALIDITY 3] UIDs valid" || len(r.literals) != 0 {
+		t.Fatalf("got %q with %d literals", r.line, len(r.literals))
+	}
+
+	r, err = c.readResponse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.line != "A1 OK done" {
+		t.Fatalf("got %q", r.line)
+	}
+}
+
+func TestReadResponseLiterals(t *testing.T) {
+	message := "Subject: Hi\r\n\r\nLine one\r\n{5}\r\n"
+	header := "X-Test: 1\r\n"
+	data := fmt.Sprintf("* 1 FETCH (UID 7 BODY[]<0> {%d}\r\n%s BODY[HEADER] {%d}\r\n%s)\r\n", len(message), message, len(header), header)
+	c := newTestIMAPReader(data)
+
+	r, err := c.readResponse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.line != "* 1 FETCH (UID 7 BODY[]<0>  BODY[HEADER] )" {
+		t.Fatalf("got line %q", r.line)
+	}
+	if len(r.literals) != 2 || string(r.literals[0]) != message || string(r.literals[1]) != header {
+		t.Fatalf("got literals %q", r.literals)
+	}
+}
+
+func TestReadResponseLiteralTooLarge(t *testing.T) {
+	c := newTestIMAPReader(fmt.Sprintf("* 1 FETCH (BODY[] {%d}\r\n", imapFetchBytes+1))
+
+	if _, err := c.readResponse(); !errors.Is(err, errIMAPResponse) {
+		t.Fatalf("got error %v, want %v", err, errIMAPResponse)
+	}
+}
+
+func TestReadResponseLiteralCutOff(t *testing.T) {
+	c := newTestIMAPReader("* 1 FETCH (BODY[] {20}\r\nshort")
+
+	if _, err := c.readResponse(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("got error %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestIMAPClientSession(t *testing.T) {
+	server := newFakeIMAPServer(42)
+	server.add(3, "From: a@example.com\r\nSubject: One\r\n\r\nHello\r\n")
+	server.add(8, "From: b@example.com\r\nSubject: Two\r\n\r\nWorld\r\n")
+
+	c, err := server.connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Logout()
+
+	if err := c.Login("user@example.com", `pa"ss`); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Login("bad", "wrong"); err == nil {
+		t.Fatal("login with a wrong password succeeded")
+	}
+
+	uidValidity, err := c.Select("INBOX")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uidValidity != 42 {
+		t.Fatalf("got UIDVALIDITY %d, want 42", uidValidity)
+	}
+
+	uids, err := c.SearchUIDs("UID 4:*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uids) != 1 || uids[0] != 8 {
+		t.Fatalf("got UIDs %v, want [8]", uids)
+	}
+
+	raw, err := c.FetchMessage(8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != server.messages[8] {
+		t.Fatalf("got message %q", raw)
+	}
+
+	if _, err := c.FetchMessage(5); !errors.Is(err, errIMAPResponse) {
+		t.Fatalf("got error %v for a missing message, want %v", err, errIMAPResponse)
+	}
+}
+
+func TestIMAPQuote(t *testing.T) {
+	quoted, err := imapQuote(`a"b\c`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quoted != `"a\"b\\c"` {
+		t.Fatalf("got %s", quoted)
+	}
+
+	if _, err := imapQuote("a\r\nA2 LOGOUT"); err == nil {
+		t.Fatal("quoted a line break")
+	}
+}
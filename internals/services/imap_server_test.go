@@ -0,0 +1,158 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// fakeIMAPServer is a minimal IMAP server for tests. It answers the commands
+// imapClient sends for one mailbox and records the search criteria it gets.
+type fakeIMAPServer struct {
+	uidValidity uint32
+	messages    map[uint32]string
+
+	mu       sync.Mutex
+	searches []string
+}
+
+func newFakeIMAPServer(uidValidity uint32) *fakeIMAPServer {
+	return &fakeIMAPServer{uidValidity: uidValidity, messages: map[uint32]string{}}
+}
+
+// add puts a message into the mailbox
+func (s *fakeIMAPServer) add(uid uint32, message string) {
+	s.messages[uid] = message
+}
+
+// searched returns the criteria of the searches so far
+func (s *fakeIMAPServer) searched() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.searches...)
+}
+
+// connect starts a session over an in-memory connection
+func (s *fakeIMAPServer) connect() (*imapClient, error) {
+	clientConn, serverConn := net.Pipe()
+	go s.serve(serverConn)
+
+	c, err := newIMAPClient(clientConn)
+	if err != nil {
+		clientConn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *fakeIMAPServer) uids() []uint32 {
+	uids := make([]uint32, 0, len(s.messages))
+	for uid := range s.messages {
+		uids = append(uids, uid)
+	}
+	sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+
+	return uids
+}
+
+func (s *fakeIMAPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	w := bufio.NewWriter(conn)
+	r := bufio.NewReader(conn)
+
+	reply := func(lines ...string) bool {
+		for _, line := range lines {
+			w.WriteString(line + "\r\n")
+		}
+		return w.Flush() == nil
+	}
+
+	if !reply("* OK fake IMAP server ready") {
+		return
+	}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		tag, cmd, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+		name, args, _ := strings.Cut(cmd, " ")
+
+		var lines []string
+		switch strings.ToUpper(name) {
+		case "LOGIN":
+			if strings.HasPrefix(args, `"bad"`) {
+				lines = append(lines, tag+" NO [AUTHENTICATIONFAILED] Invalid credentials")
+			} else {
+				lines = append(lines, tag+" OK LOGIN completed")
+			}
+		case "SELECT":
+			lines = append(lines,
+				fmt.Sprintf("* %d EXISTS", len(s.messages)),
+				fmt.Sprintf("* OK [UIDVALIDITY %d] UIDs valid", s.uidValidity),
+				tag+" OK [READ-WRITE] SELECT completed",
+			)
+		case "UID":
+			lines = s.uidCommand(tag, args)
+		case "LOGOUT":
+			reply("* BYE logging out", tag+" OK LOGOUT completed")
+			return
+		default:
+			lines = append(lines, tag+" BAD unknown command")
+		}
+		if !reply(lines...) {
+			return
+		}
+	}
+}
+
+// uidCommand answers UID SEARCH and UID FETCH
+func (s *fakeIMAPServer) uidCommand(tag string, args string) []string {
+	name, rest, _ := strings.Cut(args, " ")
+	uids := s.uids()
+
+	switch strings.ToUpper(name) {
+	case "SEARCH":
+		s.mu.Lock()
+		s.searches = append(s.searches, rest)
+		s.mu.Unlock()
+
+		found := []string{}
+		if from, ok := strings.CutPrefix(rest, "UID "); ok {
+			first, _ := strconv.ParseUint(strings.TrimSuffix(from, ":*"), 10, 32)
+			for _, uid := range uids {
+				if uint64(uid) >= first {
+					found = append(found, strconv.Itoa(int(uid)))
+				}
+			}
+			// Like real servers, n:* matches the newest message when nothing is newer
+			if len(found) == 0 && len(uids) > 0 {
+				found = append(found, strconv.Itoa(int(uids[len(uids)-1])))
+			}
+		} else {
+			for _, uid := range uids {
+				found = append(found, strconv.Itoa(int(uid)))
+			}
+		}
+		return []string{"* SEARCH " + strings.Join(found, " "), tag + " OK SEARCH completed"}
+
+	case "FETCH":
+		value, _, _ := strings.Cut(rest, " ")
+		uid, _ := strconv.ParseUint(value, 10, 32)
+		lines := []string{"* 1 FETCH (FLAGS (\\Seen))"}
+		for i, u := range uids {
+			if uint64(u) == uid {
+				message := s.messages[u]
+				lines = append(lines, fmt.Sprintf("* %d FETCH (UID %d BODY[]<0> {%d}\r\n%s)", i+1, u, len(message), message))
+			}
+		}
+		return append(lines, tag+" OK FETCH completed")
+	}
+
+	return []string{tag + " BAD unknown UID command"}
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// maxReplyBodyLength is how many characters of a reply are stored
+	maxReplyBodyLength = 10000
+	// maxMIMEDepth limits how deeply nested multipart bodies are searched for text
+	maxMIMEDepth = 5
+)
+
+var (
+	messageIDPattern = regexp.MustCompile(`<([^<>\s]+)>`)
+	htmlTagPattern   = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// inboundEmail is the part of a received message reply detection uses
+type inboundEmail struct {
+	// messageID is the Message-ID header without angle brackets
+	messageID string
+	// inReplyTo and references are the Message-IDs the message answers
+	inReplyTo  []string
+	references []string
+	fromEmail  string
+	fromName   string
+	subject    string
+	// body is the plain text of the message, or its HTML without tags
+	body       string
+	receivedAt time.Time
+	// automated is set for auto-replies and delivery reports, which are not replies
+	automated bool
+}
+
+// parseInboundEmail reads a message fetched over IMAP. The body may be cut
+// off; whatever text was fetched is kept.
+func parseInboundEmail(raw []byte) (*inboundEmail, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := mail.ParseAddress(msg.Header.Get("From"))
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := new(mime.WordDecoder)
+	subject, err := decoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+
+	email := &inboundEmail{
+		inReplyTo:  parseMessageIDs(msg.Header.Get("In-Reply-To")),
+		references: parseMessageIDs(msg.Header.Get("References")),
+		fromEmail:  strings.ToLower(from.Address),
+		fromName:   from.Name,
+		subject:    strings.TrimSpace(subject),
+		receivedAt: time.Now(),
+		automated:  isAutomated(msg.Header),
+	}
+	if ids := parseMessageIDs(msg.Header.Get("Message-Id")); len(ids) > 0 {
+		email.messageID = ids[0]
+	}
+	if date, err := msg.Header.Date(); err == nil {
+		email.receivedAt = date
+	}
+
+	body := messageText(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body, 0)
+	email.body = truncateRunes(strings.TrimSpace(strings.ToValidUTF8(body, "")), maxReplyBodyLength)
+
+	return email, nil
+}
+
+// parseMessageIDs extracts the Message-IDs of a header, without angle brackets
+func parseMessageIDs(value string) []string {
+	ids := []string{}
+	for _, match := range messageIDPattern.FindAllStringSubmatch(value, -1) {
+		ids = append(ids, match[1])
+	}
+
+	return ids
+}
+
+// isAutomated reports whether a message is an auto-reply (RFC 3834) or a
+// delivery status report
+func isAutomated(header mail.Header) bool {
+	if v := strings.TrimSpace(header.Get("Auto-Submitted")); v != "" && !strings.EqualFold(v, "no") {
+		return true
+	}
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err == nil && mediaType == "multipart/report" && params["report-type"] == "delivery-status" {
+		return true
+	}
+
+	return false
+}
+
+// messageText returns the text of a MIME entity, preferring text/plain over
+// text/html in multipart messages
+func messageText(contentType string, transferEncoding string, body io.Reader, depth int) string {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxMIMEDepth || params["boundary"] == "" {
+			return ""
+		}
+		plain, fallback := "", ""
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+			text := messageText(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part, depth+1)
+			if text == "" {
+				continue
+			}
+			if partType == "" || partType == "text/plain" || strings.HasPrefix(partType, "multipart/") {
+				if plain == "" {
+					plain = text
+				}
+			} else if fallback == "" {
+				fallback = text
+			}
+		}
+		if plain != "" {
+			return plain
+		}
+		return fallback
+	}
+
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		return ""
+	}
+
+	switch strings.ToLower(strings.TrimSpace(transferEncoding)) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	// A cut off body ends with an error; the text read so far is still used
+	content, _ := io.ReadAll(body)
+
+	text := string(content)
+	if mediaType == "text/html" {
+		text = html.UnescapeString(htmlTagPattern.ReplaceAllString(text, " "))
+	}
+
+	return text
+}
+
+// truncateRunes cuts a string to at most max characters
+func truncateRunes(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return value
+	}
+
+	return string(runes[:max])
+}
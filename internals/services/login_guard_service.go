@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+)
+
+const (
+	// loginAttemptWindow is how far back failed attempts from one IP are counted
+	loginAttemptWindow = 15 * time.Minute
+	// maxFailedLoginsPerIP blocks an IP once it fails this often within the window
+	maxFailedLoginsPerIP = 20
+	// ipDelayThreshold and accountDelayThreshold are the failures after which each retry is delayed
+	ipDelayThreshold      = 5
+	accountDelayThreshold = 3
+	// maxLoginDelay caps the progressive delay between attempts
+	maxLoginDelay = time.Minute
+	// accountLockThreshold consecutive failures lock the account for accountLockDuration
+	accountLockThreshold = 10
+	accountLockDuration  = 15 * time.Minute
+	// failedLoginReset forgets an account's failures after this long without a new one
+	failedLoginReset = 24 * time.Hour
+	// accountUnlockExpiry is how long an emailed unlock link stays valid
+	accountUnlockExpiry = 24 * time.Hour
+	// loginAttemptRetention is how long attempt records are kept
+	loginAttemptRetention = 24 * time.Hour
+)
+
+// ErrTooManyLoginAttempts is returned while a retry delay or an IP block is in effect
+var ErrTooManyLoginAttempts = errors.New("too many failed login attempts, please try again later")
+
+// ErrAccountLocked is returned while an account is locked after repeated failures
+var ErrAccountLocked = errors.New("account is temporarily locked after too many failed login attempts")
+
+// LoginBlockedError is returned when a login is refused before the credentials
+// are checked. It wraps ErrTooManyLoginAttempts or ErrAccountLocked.
+type LoginBlockedError struct {
+	Reason     error
+	RetryAfter time.Duration
+}
+
+func (e *LoginBlockedError) Error() string {
+	return e.Reason.Error()
+}
+
+func (e *LoginBlockedError) Unwrap() error {
+	return e.Reason
+}
+
+// LoginGuardService tracks failed logins per account and per IP in the
+// database and applies progressive delays and temporary lockouts
+type LoginGuardService struct {
+	client     *db.PrismaClient
+	activities *ActivityService
+	tokens     *AuthTokenService
+	mailer     *SystemMailer
+}
+
+// NewLoginGuardService creates a new login guard service
+func NewLoginGuardService(client *db.PrismaClient) *LoginGuardService {
+	return &LoginGuardService{
+		client:     client,
+		activities: NewActivityService(client),
+		tokens:     NewAuthTokenService(client),
+		mailer:     NewSystemMailer(),
+	}
+}
+
+// Check returns a *LoginBlockedError when the IP or the account must wait
+// before trying again. user is nil when the email has no account.
+func (s *LoginGuardService) Check(ctx context.Context, user *db.UserModel, ipAddress string) error {
+	now := time.Now()
+
+	if ipAddress != "" {
+		failures, err := s.client.LoginAttempt.FindMany(
+			db.LoginAttempt.IPAddress.Equals(ipAddress),
+			db.LoginAttempt.Success.Equals(false),
+			db.LoginAttempt.CreatedAt.After(now.Add(-loginAttemptWindow)),
+		).OrderBy(
+			db.LoginAttempt.CreatedAt.Order(db.SortOrderDesc),
+		).Take(maxFailedLoginsPerIP).Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch login attempts: %w", err)
+		}
+
+		if len(failures) >= maxFailedLoginsPerIP {
+			// Blocked until the oldest counted failure leaves the window
+			oldest := failures[len(failures)-1].CreatedAt
+			return &LoginBlockedError{Reason: ErrTooManyLoginAttempts, RetryAfter: oldest.Add(loginAttemptWindow).Sub(now)}
+		}
+		if len(failures) > 0 {
+			if wait := failures[0].CreatedAt.Add(loginDelay(len(failures), ipDelayThreshold)).Sub(now); wait > 0 {
+				return &LoginBlockedError{Reason: ErrTooManyLoginAttempts, RetryAfter: wait}
+			}
+		}
+	}
+
+	if user == nil {
+		return nil
+	}
+
+	if lockedUntil, ok := user.LockedUntil(); ok && lockedUntil.After(now) {
+		return &LoginBlockedError{Reason: ErrAccountLocked, RetryAfter: lockedUntil.Sub(now)}
+	}
+	if lastFailed, ok := user.LastFailedLoginAt(); ok {
+		if wait := lastFailed.Add(loginDelay(user.FailedLoginCount, accountDelayThreshold)).Sub(now); wait > 0 {
+			return &LoginBlockedError{Reason: ErrTooManyLoginAttempts, RetryAfter: wait}
+		}
+	}
+
+	return nil
+}
+
+// RecordFailure stores a failed attempt and, for an existing account, counts
+// it towards the account's lockout
+func (s *LoginGuardService) RecordFailure(ctx context.Context, email string, user *db.UserModel, ipAddress string) {
+	s.recordAttempt(ctx, email, ipAddress, false)
+
+	if user == nil {
+		return
+	}
+
+	now := time.Now()
+
+	// Failures from long ago no longer count towards a lockout
+	_, err := s.client.User.FindMany(
+		db.User.ID.Equals(user.ID),
+		db.User.LastFailedLoginAt.Before(now.Add(-failedLoginReset)),
+	).Update(
+		db.User.FailedLoginCount.Set(0),
+	).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to reset failed login count: %v\n", err)
+	}
+
+	updated, err := s.client.User.FindUnique(
+		db.User.ID.Equals(user.ID),
+	).Update(
+		db.User.FailedLoginCount.Increment(1),
+		db.User.LastFailedLoginAt.Set(now),
+	).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to record failed login: %v\n", err)
+		return
+	}
+
+	if updated.FailedLoginCount >= accountLockThreshold {
+		s.lock(ctx, updated, ipAddress)
+	}
+}
+
+// RecordSuccess stores a successful attempt and clears the account's failures
+func (s *LoginGuardService) RecordSuccess(ctx context.Context, user *db.UserModel, ipAddress string) {
+	s.recordAttempt(ctx, user.Email, ipAddress, true)
+
+	_, locked := user.LockedUntil()
+	if user.FailedLoginCount == 0 && !locked {
+		return
+	}
+
+	if err := s.clearFailures(ctx, user.ID); err != nil {
+		fmt.Printf("Failed to clear failed logins: %v\n", err)
+	}
+}
+
+// Unlock lifts a lockout using the token from the lockout email
+func (s *LoginGuardService) Unlock(ctx context.Context, token string) error {
+	authToken, err := s.tokens.Redeem(ctx, token, db.AuthTokenPurposeAccountUnlock)
+	if err != nil {
+		return err
+	}
+
+	if err := s.clearFailures(ctx, authToken.UserID); err != nil {
+		return err
+	}
+
+	s.activities.Record(ctx, authToken.UserID, ActivityEvent{
+		Type:        db.ActivityTypeAccountUnlocked,
+		Description: "Unlocked account from email link",
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    authToken.UserID,
+	})
+
+	return nil
+}
+
+// PurgeAttempts deletes attempt records older than the retention period
+func (s *LoginGuardService) PurgeAttempts(ctx context.Context) error {
+	_, err := s.client.LoginAttempt.FindMany(
+		db.LoginAttempt.CreatedAt.Before(time.Now().Add(-loginAttemptRetention)),
+	).Delete().Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to purge login attempts: %w", err)
+	}
+
+	return nil
+}
+
+// RunCleanupLoop calls PurgeAttempts immediately and then on every interval until ctx is cancelled
+func (s *LoginGuardService) RunCleanupLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.PurgeAttempts(ctx); err != nil {
+			fmt.Printf("Failed to purge login attempts: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lock locks the account, records it and emails the owner an unlock link.
+// The counter restarts so the account gets a fresh set of attempts afterwards.
+func (s *LoginGuardService) lock(ctx context.Context, user *db.UserModel, ipAddress string) {
+	lockedUntil := time.Now().Add(accountLockDuration)
+
+	// Only the request that crosses the threshold locks, even across instances
+	result, err := s.client.User.FindMany(
+		db.User.ID.Equals(user.ID),
+		db.User.FailedLoginCount.Gte(accountLockThreshold),
+	).Update(
+		db.User.LockedUntil.Set(lockedUntil),
+		db.User.FailedLoginCount.Set(0),
+	).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to lock account: %v\n", err)
+		return
+	}
+	if result.Count == 0 {
+		return
+	}
+
+	s.activities.Record(ctx, user.ID, ActivityEvent{
+		Type:        db.ActivityTypeAccountLocked,
+		Description: "Account locked after too many failed login attempts",
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    user.ID,
+		Metadata: map[string]interface{}{
+			"ip_address":   ipAddress,
+			"locked_until": lockedUntil,
+		},
+	})
+
+	token, err := s.tokens.Issue(ctx, user.ID, db.AuthTokenPurposeAccountUnlock, accountUnlockExpiry)
+	if err != nil {
+		fmt.Printf("Failed to issue unlock token: %v\n", err)
+		return
+	}
+
+	body := fmt.Sprintf(`Hi %s,
+
+Your account was locked for %d minutes after %d failed login attempts. If this was you, open the link below to unlock it now:
+
+%s
+
+If it was not you, someone may be trying to guess your password. Consider resetting it.`,
+		user.Name, int(accountLockDuration.Minutes()), accountLockThreshold, appURL("/unlock-account?token="+token))
+
+	if err := s.mailer.Send(user.Email, "Your account was locked", body); err != nil {
+		fmt.Printf("Failed to send unlock email to %s: %v\n", user.Email, err)
+	}
+}
+
+// clearFailures resets the failure counter and lifts any lock
+func (s *LoginGuardService) clearFailures(ctx context.Context, userID string) error {
+	_, err := s.client.User.FindUnique(
+		db.User.ID.Equals(userID),
+	).Update(
+		db.User.FailedLoginCount.Set(0),
+		db.User.LastFailedLoginAt.SetOptional(nil),
+		db.User.LockedUntil.SetOptional(nil),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to clear failed logins: %w", err)
+	}
+
+	return nil
+}
+
+// recordAttempt stores one login attempt; failures to store are only logged
+func (s *LoginGuardService) recordAttempt(ctx context.Context, email string, ipAddress string, success bool) {
+	_, err := s.client.LoginAttempt.CreateOne(
+		db.LoginAttempt.Email.Set(strings.ToLower(strings.TrimSpace(email))),
+		db.LoginAttempt.IPAddress.Set(ipAddress),
+		db.LoginAttempt.Success.Set(success),
+	).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to record login attempt: %v\n", err)
+	}
+}
+
+// loginDelay is the wait required after the given number of failures: none
+// below the threshold, then one second doubling up to maxLoginDelay
+func loginDelay(failures int, threshold int) time.Duration {
+	if failures < threshold {
+		return 0
+	}
+
+	delay := time.Second
+	for i := threshold; i < failures && delay < maxLoginDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxLoginDelay {
+		return maxLoginDelay
+	}
+
+	return delay
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+var (
+	// smtpPorts and imapPorts are the ports a sender account can use
+	smtpPorts = map[int]bool{25: true, 465: true, 587: true, 2525: true}
+	imapPorts = map[int]bool{143: true, 993: true}
+
+	// nonPublicPrefixes are ranges the address checks of net/netip miss:
+	// "this network", carrier-grade NAT, benchmarking and reserved space
+	nonPublicPrefixes = []netip.Prefix{
+		netip.MustParsePrefix("0.0.0.0/8"),
+		netip.MustParsePrefix("100.64.0.0/10"),
+		netip.MustParsePrefix("192.0.0.0/24"),
+		netip.MustParsePrefix("198.18.0.0/15"),
+		netip.MustParsePrefix("240.0.0.0/4"),
+		netip.MustParsePrefix("64:ff9b::/96"),
+	}
+)
+
+// validateMailHost checks that a mail server host resolves only to public
+// addresses, so users cannot make the backend connect to internal services
+func validateMailHost(ctx context.Context, field string, host string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("%w: %s %s cannot be resolved", ErrInvalidSenderAccount, field, host)
+	}
+	for _, addr := range addrs {
+		if !isPublicAddr(addr) {
+			return fmt.Errorf("%w: %s %s is not a public address", ErrInvalidSenderAccount, field, host)
+		}
+	}
+
+	return nil
+}
+
+// isPublicAddr reports whether an address is routable on the internet
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// mailDialer dials mail servers. It refuses non-public addresses when the
+// connection is made, since a host can resolve differently than when it was saved.
+func mailDialer() *net.Dialer {
+	return &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !isPublicAddr(addrPort.Addr()) {
+				return fmt.Errorf("refusing to connect to non-public address %s", addrPort.Addr())
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,443 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/internals/utils"
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+	"golang.org/x/oauth2"
+)
+
+// mailConnectExpiry is how long a user has to approve access at Google
+const mailConnectExpiry = 10 * time.Minute
+
+// accessTokenMargin renews cached access tokens this long before they expire
+const accessTokenMargin = time.Minute
+
+// gmailScope allows sending mail over SMTP with XOAUTH2
+const gmailScope = "https://mail.google.com/"
+
+// googleRevokeURL revokes a refresh token at Google
+const googleRevokeURL = "https://oauth2.googleapis.com/revoke"
+
+// googleEndpoint is Google's OAuth 2.0 authorization server
+var googleEndpoint = oauth2.Endpoint{
+	AuthURL:   "https://accounts.google.com/o/oauth2/v2/auth",
+	TokenURL:  "https://oauth2.googleapis.com/token",
+	AuthStyle: oauth2.AuthStyleInParams,
+}
+
+// ErrMailOAuthNotConfigured is returned when no Google OAuth client is configured
+var ErrMailOAuthNotConfigured = errors.New("connecting Gmail is not configured")
+
+// ErrInvalidMailOAuthState is returned when the callback's state is unknown, used, expired or for another user
+var ErrInvalidMailOAuthState = errors.New("invalid or expired connection attempt, please start again")
+
+// ErrMailOAuthFailed is returned when Google does not grant offline access to the mailbox
+var ErrMailOAuthFailed = errors.New("connecting Gmail failed")
+
+// ErrMailAccountNotConnected is returned when disconnecting without a connected account
+var ErrMailAccountNotConnected = errors.New("no Gmail account is connected")
+
+// cachedAccessToken is an access token and the stored refresh token it was obtained with
+type cachedAccessToken struct {
+	refreshToken string
+	token        *oauth2.Token
+}
+
+// MailOAuthService connects users' Gmail accounts with OAuth and provides
+// access tokens for sending mail with XOAUTH2
+type MailOAuthService struct {
+	client     *db.PrismaClient
+	activities *ActivityService
+	httpClient *http.Client
+
+	mu           sync.Mutex
+	accessTokens map[string]cachedAccessToken
+}
+
+// NewMailOAuthService creates a new mail OAuth service
+func NewMailOAuthService(client *db.PrismaClient) *MailOAuthService {
+	return &MailOAuthService{
+		client:       client,
+		activities:   NewActivityService(client),
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+		accessTokens: map[string]cachedAccessToken{},
+	}
+}
+
+// mailOAuthConfig reads GOOGLE_OAUTH_CLIENT_ID, GOOGLE_OAUTH_CLIENT_SECRET
+// and GOOGLE_OAUTH_REDIRECT_URL
+func mailOAuthConfig() (*oauth2.Config, error) {
+	clientID := strings.TrimSpace(os.Getenv("GOOGLE_OAUTH_CLIENT_ID"))
+	clientSecret := os.Getenv("GOOGLE_OAUTH_CLIENT_SECRET")
+	if clientID == "" || clientSecret == "" {
+		return nil, ErrMailOAuthNotConfigured
+	}
+
+	redirectURL := strings.TrimSpace(os.Getenv("GOOGLE_OAUTH_REDIRECT_URL"))
+	if redirectURL == "" {
+		redirectURL = appURL("/settings/email/callback")
+	}
+
+	return &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"openid", "email", gmailScope},
+		Endpoint:     googleEndpoint,
+	}, nil
+}
+
+// Connect starts connecting a Gmail account. The browser is sent to the
+// returned URL and comes back to the redirect URL with a code and the state.
+func (s *MailOAuthService) Connect(ctx context.Context, userID string) (*models.MailConnectResponse, error) {
+	cfg, err := mailOAuthConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	state, stateHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate state: %w", err)
+	}
+	verifier := oauth2.GenerateVerifier()
+	expiresAt := time.Now().Add(mailConnectExpiry)
+
+	_, err = s.client.MailOAuthState.CreateOne(
+		db.MailOAuthState.StateHash.Set(stateHash),
+		db.MailOAuthState.CodeVerifier.Set(verifier),
+		db.MailOAuthState.ExpiresAt.Set(expiresAt),
+		db.MailOAuthState.User.Link(db.User.ID.Equals(userID)),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store connection state: %w", err)
+	}
+
+	// Abandoned connection attempts are cleaned up as new ones start
+	_, err = s.client.MailOAuthState.FindMany(
+		db.MailOAuthState.ExpiresAt.Before(time.Now()),
+	).Delete().Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to delete expired mail connection states: %v\n", err)
+	}
+
+	// Offline access with forced consent makes Google return a refresh token every time
+	authorizationURL := cfg.AuthCodeURL(
+		state,
+		oauth2.AccessTypeOffline,
+		oauth2.ApprovalForce,
+		oauth2.S256ChallengeOption(verifier),
+	)
+
+	return &models.MailConnectResponse{
+		AuthorizationURL: authorizationURL,
+		State:            state,
+		ExpiresAt:        expiresAt,
+	}, nil
+}
+
+// Callback finishes connecting a Gmail account and stores its refresh token encrypted
+func (s *MailOAuthService) Callback(ctx context.Context, userID string, req models.MailConnectCallbackRequest) (*models.MailConnectionResponse, error) {
+	cfg, err := mailOAuthConfig()
+	if err != nil {
+		return nil, err
+	}
+	if req.Code == "" || req.State == "" {
+		return nil, ErrInvalidMailOAuthState
+	}
+
+	// Consume the state so the same callback cannot be replayed
+	connectState, err := s.client.MailOAuthState.FindUnique(
+		db.MailOAuthState.StateHash.Equals(utils.HashOpaqueToken(req.State)),
+	).Delete().Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrInvalidMailOAuthState
+		}
+		return nil, fmt.Errorf("failed to fetch connection state: %w", err)
+	}
+	if connectState.UserID != userID || connectState.ExpiresAt.Before(time.Now()) {
+		return nil, ErrInvalidMailOAuthState
+	}
+
+	token, err := cfg.Exchange(
+		context.WithValue(ctx, oauth2.HTTPClient, s.httpClient),
+		req.Code,
+		oauth2.VerifierOption(connectState.CodeVerifier),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: code exchange: %v", ErrMailOAuthFailed, err)
+	}
+	if token.RefreshToken == "" {
+		return nil, fmt.Errorf("%w: Google did not grant offline access", ErrMailOAuthFailed)
+	}
+	if !strings.Contains(" "+fmt.Sprint(token.Extra("scope"))+" ", " "+gmailScope+" ") {
+		return nil, fmt.Errorf("%w: access to Gmail was not granted", ErrMailOAuthFailed)
+	}
+
+	email, err := idTokenEmail(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMailOAuthFailed, err)
+	}
+
+	encryptedToken, err := utils.EncryptSecret(token.RefreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt refresh token: %w", err)
+	}
+
+	connectedAt := time.Now()
+	_, err = s.client.User.FindUnique(
+		db.User.ID.Equals(userID),
+	).Update(
+		db.User.MailOAuthEmail.Set(email),
+		db.User.MailOAuthRefreshToken.Set(encryptedToken),
+		db.User.MailOAuthConnectedAt.Set(connectedAt),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save Gmail connection: %w", err)
+	}
+
+	s.cacheAccessToken(userID, encryptedToken, token)
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeMailAccountConnected,
+		Description: "Connected Gmail account " + email,
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    userID,
+		Metadata: map[string]interface{}{
+			"email": email,
+		},
+	})
+
+	return &models.MailConnectionResponse{
+		Provider:    "google",
+		Email:       email,
+		ConnectedAt: connectedAt,
+	}, nil
+}
+
+// Status returns the user's connected Gmail account
+func (s *MailOAuthService) Status(ctx context.Context, userID string) (*models.MailConnectionResponse, error) {
+	user, err := s.client.User.FindUnique(
+		db.User.ID.Equals(userID),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	email, ok := user.MailOAuthEmail()
+	connectedAt, _ := user.MailOAuthConnectedAt()
+	if !ok || email == "" {
+		return nil, ErrMailAccountNotConnected
+	}
+
+	return &models.MailConnectionResponse{
+		Provider:    "google",
+		Email:       email,
+		ConnectedAt: connectedAt,
+	}, nil
+}
+
+// Disconnect removes the user's Gmail connection and revokes the refresh token at Google
+func (s *MailOAuthService) Disconnect(ctx context.Context, userID string) error {
+	user, err := s.client.User.FindUnique(
+		db.User.ID.Equals(userID),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	encryptedToken, ok := user.MailOAuthRefreshToken()
+	if !ok || encryptedToken == "" {
+		return ErrMailAccountNotConnected
+	}
+
+	if refreshToken, err := utils.DecryptSecret(encryptedToken); err != nil {
+		fmt.Printf("Failed to decrypt refresh token of user %s: %v\n", userID, err)
+	} else if err := s.revoke(ctx, refreshToken); err != nil {
+		fmt.Printf("Failed to revoke Gmail access of user %s: %v\n", userID, err)
+	}
+
+	if err := s.clearConnection(ctx, userID); err != nil {
+		return err
+	}
+
+	email, _ := user.MailOAuthEmail()
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeMailAccountDisconnected,
+		Description: "Disconnected Gmail account " + email,
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    userID,
+		Metadata: map[string]interface{}{
+			"email": email,
+		},
+	})
+
+	return nil
+}
+
+// AccessToken returns the connected Gmail address and a valid access token,
+// refreshing it when needed. ok is false when no account is connected, or
+// when Google revoked access, in which case the connection is removed.
+func (s *MailOAuthService) AccessToken(ctx context.Context, user *db.UserModel) (email string, accessToken string, ok bool, err error) {
+	encryptedToken, connected := user.MailOAuthRefreshToken()
+	email, _ = user.MailOAuthEmail()
+	if !connected || encryptedToken == "" || email == "" {
+		return "", "", false, nil
+	}
+
+	s.mu.Lock()
+	cached, found := s.accessTokens[user.ID]
+	s.mu.Unlock()
+	if found && cached.refreshToken == encryptedToken && cached.token.Expiry.After(time.Now().Add(accessTokenMargin)) {
+		return email, cached.token.AccessToken, true, nil
+	}
+
+	cfg, err := mailOAuthConfig()
+	if err != nil {
+		return "", "", false, err
+	}
+	refreshToken, err := utils.DecryptSecret(encryptedToken)
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to decrypt refresh token: %w", err)
+	}
+
+	token, err := cfg.TokenSource(
+		context.WithValue(ctx, oauth2.HTTPClient, s.httpClient),
+		&oauth2.Token{RefreshToken: refreshToken},
+	).Token()
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant" {
+			fmt.Printf("Gmail access of user %s was revoked, removing the connection\n", user.ID)
+			if err := s.clearConnection(ctx, user.ID); err != nil {
+				fmt.Printf("Failed to remove Gmail connection of user %s: %v\n", user.ID, err)
+			}
+			return "", "", false, nil
+		}
+		return "", "", false, fmt.Errorf("failed to refresh Gmail access token: %w", err)
+	}
+
+	// Store a rotated refresh token so the connection keeps working
+	if token.RefreshToken != "" && token.RefreshToken != refreshToken {
+		if rotated, err := utils.EncryptSecret(token.RefreshToken); err != nil {
+			fmt.Printf("Failed to encrypt refresh token of user %s: %v\n", user.ID, err)
+		} else if _, err := s.client.User.FindUnique(
+			db.User.ID.Equals(user.ID),
+		).Update(
+			db.User.MailOAuthRefreshToken.Set(rotated),
+		).Exec(ctx); err != nil {
+			fmt.Printf("Failed to store refresh token of user %s: %v\n", user.ID, err)
+		} else {
+			encryptedToken = rotated
+		}
+	}
+
+	s.cacheAccessToken(user.ID, encryptedToken, token)
+
+	return email, token.AccessToken, true, nil
+}
+
+func (s *MailOAuthService) cacheAccessToken(userID string, encryptedRefreshToken string, token *oauth2.Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.accessTokens[userID] = cachedAccessToken{refreshToken: encryptedRefreshToken, token: token}
+}
+
+// clearConnection removes the stored Gmail connection
+func (s *MailOAuthService) clearConnection(ctx context.Context, userID string) error {
+	_, err := s.client.User.FindUnique(
+		db.User.ID.Equals(userID),
+	).Update(
+		db.User.MailOAuthEmail.SetOptional(nil),
+		db.User.MailOAuthRefreshToken.SetOptional(nil),
+		db.User.MailOAuthConnectedAt.SetOptional(nil),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to remove Gmail connection: %w", err)
+	}
+
+	s.mu.Lock()
+	delete(s.accessTokens, userID)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// revoke tells Google to invalidate a refresh token
+func (s *MailOAuthService) revoke(ctx context.Context, refreshToken string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, googleRevokeURL, strings.NewReader(url.Values{"token": {refreshToken}}.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// idTokenEmail reads the email claim of the ID token returned with the
+// access token. It came directly from Google's token endpoint over TLS, so
+// its signature does not need to be checked.
+func idTokenEmail(token *oauth2.Token) (string, error) {
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return "", errors.New("no ID token in response")
+	}
+
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(rawIDToken, claims); err != nil {
+		return "", fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	email, _ := claims["email"].(string)
+	if email == "" {
+		return "", errors.New("ID token has no email")
+	}
+
+	return email, nil
+}
+
+// xoauth2Auth implements the XOAUTH2 SASL mechanism used by Gmail's SMTP server
+type xoauth2Auth struct {
+	username    string
+	accessToken string
+}
+
+func (a *xoauth2Auth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS {
+		return "", nil, errors.New("XOAUTH2 requires an encrypted connection")
+	}
+
+	return "XOAUTH2", []byte("user=" + a.username + "\x01auth=Bearer " + a.accessToken + "\x01\x01"), nil
+}
+
+func (a *xoauth2Auth) Next(fromServer []byte, more bool) ([]byte, error) {
+	// On failure the server sends a JSON error as a challenge and expects an
+	// empty response before it rejects the login
+	if more {
+		return []byte{}, nil
+	}
+	return nil, nil
+}
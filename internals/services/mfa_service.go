@@ -0,0 +1,379 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/internals/utils"
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+)
+
+const (
+	// recoveryCodeCount is how many recovery codes are generated at a time
+	recoveryCodeCount = 10
+	// defaultTOTPIssuer names the account in authenticator apps when TOTP_ISSUER is unset
+	defaultTOTPIssuer = "HR Backend"
+)
+
+// MFA factors reported by VerifyCode
+const (
+	MFAFactorTOTP         = "totp"
+	MFAFactorRecoveryCode = "recovery_code"
+)
+
+// ErrMFAAlreadyEnabled is returned when enrolling an account that already uses TOTP
+var ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+
+// ErrMFANotEnabled is returned when changing MFA settings of an account without TOTP
+var ErrMFANotEnabled = errors.New("two-factor authentication is not enabled")
+
+// ErrMFASetupRequired is returned when confirming before an enrollment was started
+var ErrMFASetupRequired = errors.New("start two-factor setup before confirming it")
+
+// ErrInvalidMFACode is returned when a TOTP or recovery code does not match
+var ErrInvalidMFACode = errors.New("invalid authentication code")
+
+// MFAService handles TOTP enrollment, recovery codes and second factor checks
+type MFAService struct {
+	client     *db.PrismaClient
+	activities *ActivityService
+}
+
+// NewMFAService creates a new MFA service
+func NewMFAService(client *db.PrismaClient) *MFAService {
+	return &MFAService{
+		client:     client,
+		activities: NewActivityService(client),
+	}
+}
+
+// GetStatus reports whether the user has TOTP enabled and how many recovery codes are left
+func (s *MFAService) GetStatus(ctx context.Context, userID string) (*models.MFAStatusResponse, error) {
+	user, err := s.client.User.FindUnique(
+		db.User.ID.Equals(userID),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	response := &models.MFAStatusResponse{}
+	enabledAt, enabled := user.TotpEnabledAt()
+	if !enabled {
+		return response, nil
+	}
+
+	codes, err := s.client.RecoveryCode.FindMany(
+		db.RecoveryCode.UserID.Equals(userID),
+		db.RecoveryCode.UsedAt.IsNull(),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recovery codes: %w", err)
+	}
+
+	response.Enabled = true
+	response.EnabledAt = &enabledAt
+	response.RecoveryCodesRemaining = len(codes)
+
+	return response, nil
+}
+
+// Setup starts TOTP enrollment by generating a new secret. The secret stays
+// inactive until Confirm proves the authenticator app produces valid codes.
+func (s *MFAService) Setup(ctx context.Context, userID string) (*models.TOTPSetupResponse, error) {
+	user, err := s.client.User.FindUnique(
+		db.User.ID.Equals(userID),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	if isMFAEnabled(user) {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+
+	encryptedSecret, err := utils.EncryptSecret(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt TOTP secret: %w", err)
+	}
+
+	_, err = s.client.User.FindUnique(
+		db.User.ID.Equals(userID),
+	).Update(
+		db.User.TotpSecret.Set(encryptedSecret),
+		db.User.TotpLastUsedStep.SetOptional(nil),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store TOTP secret: %w", err)
+	}
+
+	return &models.TOTPSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPURI(totpIssuer(), user.Email, secret),
+	}, nil
+}
+
+// Confirm enables TOTP once the user enters a valid code from the app and
+// returns the first set of recovery codes
+func (s *MFAService) Confirm(ctx context.Context, userID string, code string) (*models.RecoveryCodesResponse, error) {
+	user, err := s.client.User.FindUnique(
+		db.User.ID.Equals(userID),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	if isMFAEnabled(user) {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if _, ok := user.TotpSecret(); !ok {
+		return nil, ErrMFASetupRequired
+	}
+
+	if err := s.verifyTOTP(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	_, err = s.client.User.FindUnique(
+		db.User.ID.Equals(userID),
+	).Update(
+		db.User.TotpEnabledAt.Set(time.Now()),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeMfaEnabled,
+		Description: "Enabled two-factor authentication",
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    userID,
+	})
+
+	return codes, nil
+}
+
+// Disable turns off TOTP and deletes the recovery codes. It requires the
+// password and a current code so a stolen session alone cannot remove MFA.
+func (s *MFAService) Disable(ctx context.Context, userID string, req models.MFAVerificationRequest) error {
+	user, err := s.verifyBothFactors(ctx, userID, req)
+	if err != nil {
+		return err
+	}
+
+	clearTOTP := s.client.User.FindUnique(
+		db.User.ID.Equals(user.ID),
+	).Update(
+		db.User.TotpSecret.SetOptional(nil),
+		db.User.TotpEnabledAt.SetOptional(nil),
+		db.User.TotpLastUsedStep.SetOptional(nil),
+	).Tx()
+
+	deleteCodes := s.client.RecoveryCode.FindMany(
+		db.RecoveryCode.UserID.Equals(user.ID),
+	).Delete().Tx()
+
+	if err := s.client.Prisma.Transaction(clearTOTP, deleteCodes).Exec(ctx); err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+
+	s.activities.Record(ctx, user.ID, ActivityEvent{
+		Type:        db.ActivityTypeMfaDisabled,
+		Description: "Disabled two-factor authentication",
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    user.ID,
+	})
+
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of the user with a new set
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID string, req models.MFAVerificationRequest) (*models.RecoveryCodesResponse, error) {
+	user, err := s.verifyBothFactors(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.activities.Record(ctx, user.ID, ActivityEvent{
+		Type:        db.ActivityTypeMfaRecoveryCodesRegenerated,
+		Description: "Regenerated recovery codes",
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    user.ID,
+	})
+
+	return codes, nil
+}
+
+// VerifyCode checks a second factor for a user with TOTP enabled. The code may
+// be a TOTP code or an unused recovery code; it returns the factor that matched.
+func (s *MFAService) VerifyCode(ctx context.Context, user *db.UserModel, code string) (string, error) {
+	if !isMFAEnabled(user) {
+		return "", ErrMFANotEnabled
+	}
+
+	err := s.verifyTOTP(ctx, user, code)
+	if err == nil {
+		return MFAFactorTOTP, nil
+	}
+	if !errors.Is(err, ErrInvalidMFACode) {
+		return "", err
+	}
+
+	if err := s.redeemRecoveryCode(ctx, user.ID, code); err != nil {
+		return "", err
+	}
+
+	return MFAFactorRecoveryCode, nil
+}
+
+// verifyBothFactors loads a user with TOTP enabled and checks their password and code
+func (s *MFAService) verifyBothFactors(ctx context.Context, userID string, req models.MFAVerificationRequest) (*db.UserModel, error) {
+	user, err := s.client.User.FindUnique(
+		db.User.ID.Equals(userID),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	if !isMFAEnabled(user) {
+		return nil, ErrMFANotEnabled
+	}
+
+	if err := utils.ComparePassword(user.Password, req.Password); err != nil {
+		return nil, ErrIncorrectPassword
+	}
+
+	if _, err := s.VerifyCode(ctx, user, req.Code); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// verifyTOTP checks a TOTP code against the user's secret. Each time step is
+// accepted once, so an intercepted code cannot be replayed.
+func (s *MFAService) verifyTOTP(ctx context.Context, user *db.UserModel, code string) error {
+	encryptedSecret, ok := user.TotpSecret()
+	if !ok {
+		return ErrInvalidMFACode
+	}
+
+	secret, err := utils.DecryptSecret(encryptedSecret)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt TOTP secret: %w", err)
+	}
+
+	step, ok := utils.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return ErrInvalidMFACode
+	}
+
+	// Claim the step atomically so the same code cannot be used twice
+	result, err := s.client.User.FindMany(
+		db.User.ID.Equals(user.ID),
+		db.User.Or(
+			db.User.TotpLastUsedStep.IsNull(),
+			db.User.TotpLastUsedStep.Lt(int(step)),
+		),
+	).Update(
+		db.User.TotpLastUsedStep.Set(int(step)),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to record TOTP code: %w", err)
+	}
+	if result.Count == 0 {
+		return ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+// redeemRecoveryCode marks a matching unused recovery code as used
+func (s *MFAService) redeemRecoveryCode(ctx context.Context, userID string, code string) error {
+	normalized := utils.NormalizeRecoveryCode(code)
+	if normalized == "" {
+		return ErrInvalidMFACode
+	}
+
+	result, err := s.client.RecoveryCode.FindMany(
+		db.RecoveryCode.UserID.Equals(userID),
+		db.RecoveryCode.CodeHash.Equals(utils.HashOpaqueToken(normalized)),
+		db.RecoveryCode.UsedAt.IsNull(),
+	).Update(
+		db.RecoveryCode.UsedAt.Set(time.Now()),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to redeem recovery code: %w", err)
+	}
+	if result.Count == 0 {
+		return ErrInvalidMFACode
+	}
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeMfaRecoveryCodeUsed,
+		Description: "Used a recovery code",
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    userID,
+	})
+
+	return nil
+}
+
+// replaceRecoveryCodes deletes the user's recovery codes and stores a new set.
+// Only hashes are stored; the raw codes are returned to be shown once.
+func (s *MFAService) replaceRecoveryCodes(ctx context.Context, userID string) (*models.RecoveryCodesResponse, error) {
+	txs := []db.PrismaTransaction{
+		s.client.RecoveryCode.FindMany(
+			db.RecoveryCode.UserID.Equals(userID),
+		).Delete().Tx(),
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		codes = append(codes, code)
+
+		txs = append(txs, s.client.RecoveryCode.CreateOne(
+			db.RecoveryCode.CodeHash.Set(utils.HashOpaqueToken(utils.NormalizeRecoveryCode(code))),
+			db.RecoveryCode.User.Link(db.User.ID.Equals(userID)),
+		).Tx())
+	}
+
+	if err := s.client.Prisma.Transaction(txs...).Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// totpIssuer is the account issuer shown in authenticator apps
+func totpIssuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return defaultTOTPIssuer
+}
+
+// isMFAEnabled reports whether the user finished TOTP enrollment
+func isMFAEnabled(user *db.UserModel) bool {
+	_, ok := user.TotpEnabledAt()
+	return ok
+}
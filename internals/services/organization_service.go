@@ -0,0 +1,711 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/internals/utils"
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+)
+
+// invitationExpiry is how long an emailed invitation link stays valid
+const invitationExpiry = 7 * 24 * time.Hour
+
+// ErrOrganizationNotFound is returned when an organization does not exist or the user is not a member
+var ErrOrganizationNotFound = errors.New("organization not found")
+
+// ErrInsufficientRole is returned when the user's role in an organization does not allow an action
+var ErrInsufficientRole = errors.New("your role in this organization does not allow this")
+
+// ErrInvalidOrganizationRequest is returned when a name, role or email is missing or invalid
+var ErrInvalidOrganizationRequest = errors.New("invalid organization request")
+
+// ErrPersonalOrganization is returned when inviting members to or removing the owner of a personal organization
+var ErrPersonalOrganization = errors.New("personal organizations cannot be shared")
+
+// ErrMemberNotFound is returned when the user is not a member of the organization
+var ErrMemberNotFound = errors.New("member not found")
+
+// ErrLastOwner is returned when a change would leave an organization without an owner
+var ErrLastOwner = errors.New("an organization must keep at least one owner")
+
+// ErrAlreadyMember is returned when the invited user already belongs to the organization
+var ErrAlreadyMember = errors.New("already a member of this organization")
+
+// ErrInvitationNotFound is returned when an invitation is unknown, revoked, accepted or expired
+var ErrInvitationNotFound = errors.New("invitation not found or expired")
+
+// ErrInvitationEmailMismatch is returned when an invitation is accepted by an account with another email
+var ErrInvitationEmailMismatch = errors.New("this invitation was sent to a different email address")
+
+// OrganizationService handles organizations, memberships and invitations
+type OrganizationService struct {
+	client     *db.PrismaClient
+	activities *ActivityService
+	mailer     *SystemMailer
+}
+
+// NewOrganizationService creates a new organization service
+func NewOrganizationService(client *db.PrismaClient) *OrganizationService {
+	return &OrganizationService{
+		client:     client,
+		activities: NewActivityService(client),
+		mailer:     NewSystemMailer(),
+	}
+}
+
+// ListOrganizations returns every organization the user belongs to, personal first
+func (s *OrganizationService) ListOrganizations(ctx context.Context, userID string) ([]models.OrganizationResponse, error) {
+	if _, err := personalMembership(ctx, s.client, userID); err != nil {
+		return nil, err
+	}
+
+	memberships, err := s.client.Membership.FindMany(
+		db.Membership.UserID.Equals(userID),
+	).With(
+		db.Membership.Organization.Fetch(),
+	).OrderBy(
+		db.Membership.CreatedAt.Order(db.SortOrderAsc),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch organizations: %w", err)
+	}
+
+	response := []models.OrganizationResponse{}
+	for i := range memberships {
+		organization := toOrganizationResponse(&memberships[i])
+		if organization.Personal {
+			response = append([]models.OrganizationResponse{organization}, response...)
+			continue
+		}
+		response = append(response, organization)
+	}
+
+	return response, nil
+}
+
+// CreateOrganization creates a team organization owned by the user
+func (s *OrganizationService) CreateOrganization(ctx context.Context, userID string, req models.CreateOrganizationRequest) (*models.OrganizationResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidOrganizationRequest)
+	}
+
+	membership, err := createOrganization(ctx, s.client, userID, name, false)
+	if err != nil {
+		return nil, err
+	}
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeOrganizationCreated,
+		Description: "Created organization " + name,
+		TargetType:  models.ActivityTargetOrganization,
+		TargetID:    membership.OrganizationID,
+	})
+
+	response := toOrganizationResponse(membership)
+	return &response, nil
+}
+
+// GetOrganization returns an organization the user belongs to and its members
+func (s *OrganizationService) GetOrganization(ctx context.Context, userID string, organizationID string) (*models.OrganizationDetailResponse, error) {
+	membership, err := findMembership(ctx, s.client, organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.client.Membership.FindMany(
+		db.Membership.OrganizationID.Equals(organizationID),
+	).With(
+		db.Membership.User.Fetch(),
+	).OrderBy(
+		db.Membership.CreatedAt.Order(db.SortOrderAsc),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch members: %w", err)
+	}
+
+	response := &models.OrganizationDetailResponse{
+		OrganizationResponse: toOrganizationResponse(membership),
+		Members:              []models.MemberResponse{},
+	}
+	for i := range members {
+		user := members[i].User()
+		response.Members = append(response.Members, models.MemberResponse{
+			UserID:   user.ID,
+			Name:     user.Name,
+			Email:    user.Email,
+			Role:     formatOrganizationRole(members[i].Role),
+			JoinedAt: members[i].CreatedAt,
+		})
+	}
+
+	return response, nil
+}
+
+// UpdateOrganization renames an organization. Owners and admins only.
+func (s *OrganizationService) UpdateOrganization(ctx context.Context, userID string, organizationID string, req models.UpdateOrganizationRequest) (*models.OrganizationResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidOrganizationRequest)
+	}
+
+	membership, err := findMembership(ctx, s.client, organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManageMembers(membership.Role) {
+		return nil, ErrInsufficientRole
+	}
+
+	organization, err := s.client.Organization.FindUnique(
+		db.Organization.ID.Equals(organizationID),
+	).Update(
+		db.Organization.Name.Set(name),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update organization: %w", err)
+	}
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeOrganizationUpdated,
+		Description: "Renamed organization to " + name,
+		TargetType:  models.ActivityTargetOrganization,
+		TargetID:    organizationID,
+	})
+
+	return &models.OrganizationResponse{
+		ID:        organization.ID,
+		Name:      organization.Name,
+		Personal:  organization.Personal,
+		Role:      formatOrganizationRole(membership.Role),
+		CreatedAt: organization.CreatedAt,
+	}, nil
+}
+
+// UpdateMember changes a member's role. Only owners can change roles, and the
+// last owner cannot be demoted.
+func (s *OrganizationService) UpdateMember(ctx context.Context, userID string, organizationID string, memberID string, req models.UpdateMemberRequest) error {
+	role, err := parseOrganizationRole(req.Role)
+	if err != nil {
+		return err
+	}
+
+	membership, err := findMembership(ctx, s.client, organizationID, userID)
+	if err != nil {
+		return err
+	}
+	if membership.Role != db.OrganizationRoleOwner {
+		return ErrInsufficientRole
+	}
+
+	target, err := findMembership(ctx, s.client, organizationID, memberID)
+	if err != nil {
+		if errors.Is(err, ErrOrganizationNotFound) {
+			return ErrMemberNotFound
+		}
+		return err
+	}
+	if target.Role == role {
+		return nil
+	}
+	if target.Role == db.OrganizationRoleOwner {
+		if err := s.ensureAnotherOwner(ctx, organizationID, memberID); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.client.Membership.FindUnique(
+		db.Membership.ID.Equals(target.ID),
+	).Update(
+		db.Membership.Role.Set(role),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update member: %w", err)
+	}
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeMemberRoleChanged,
+		Description: "Changed a member's role to " + formatOrganizationRole(role),
+		TargetType:  models.ActivityTargetOrganization,
+		TargetID:    organizationID,
+		Metadata: map[string]interface{}{
+			"user_id": memberID,
+			"from":    formatOrganizationRole(target.Role),
+			"to":      formatOrganizationRole(role),
+		},
+	})
+
+	return nil
+}
+
+// RemoveMember removes a member from an organization. Any member can leave;
+// admins can remove members, and only owners can remove admins or owners.
+// Contacts and templates the member created stay with the organization.
+func (s *OrganizationService) RemoveMember(ctx context.Context, userID string, organizationID string, memberID string) error {
+	membership, err := findMembership(ctx, s.client, organizationID, userID)
+	if err != nil {
+		return err
+	}
+
+	target := membership
+	if memberID != userID {
+		target, err = findMembership(ctx, s.client, organizationID, memberID)
+		if err != nil {
+			if errors.Is(err, ErrOrganizationNotFound) {
+				return ErrMemberNotFound
+			}
+			return err
+		}
+
+		switch {
+		case membership.Role == db.OrganizationRoleOwner:
+		case membership.Role == db.OrganizationRoleAdmin && target.Role == db.OrganizationRoleMember:
+		default:
+			return ErrInsufficientRole
+		}
+	}
+
+	if target.Role == db.OrganizationRoleOwner {
+		organization, err := s.client.Organization.FindUnique(
+			db.Organization.ID.Equals(organizationID),
+		).Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch organization: %w", err)
+		}
+		if organization.Personal {
+			return ErrPersonalOrganization
+		}
+		if err := s.ensureAnotherOwner(ctx, organizationID, memberID); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.client.Membership.FindUnique(
+		db.Membership.ID.Equals(target.ID),
+	).Delete().Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+
+	description := "Removed a member from the organization"
+	if memberID == userID {
+		description = "Left the organization"
+	}
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeMemberRemoved,
+		Description: description,
+		TargetType:  models.ActivityTargetOrganization,
+		TargetID:    organizationID,
+		Metadata: map[string]interface{}{
+			"user_id": memberID,
+		},
+	})
+
+	return nil
+}
+
+// InviteMember emails an invitation link to join a team organization.
+// Owners and admins can invite; nobody can be invited as owner.
+func (s *OrganizationService) InviteMember(ctx context.Context, userID string, organizationID string, req models.InviteMemberRequest) (*models.InvitationResponse, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if email == "" || !strings.Contains(email, "@") {
+		return nil, fmt.Errorf("%w: a valid email is required", ErrInvalidOrganizationRequest)
+	}
+
+	role := db.OrganizationRoleMember
+	if req.Role != "" {
+		parsed, err := parseOrganizationRole(req.Role)
+		if err != nil {
+			return nil, err
+		}
+		if parsed == db.OrganizationRoleOwner {
+			return nil, fmt.Errorf("%w: invitations cannot grant the owner role", ErrInvalidOrganizationRequest)
+		}
+		role = parsed
+	}
+
+	membership, err := findMembership(ctx, s.client, organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManageMembers(membership.Role) {
+		return nil, ErrInsufficientRole
+	}
+
+	organization, err := s.client.Organization.FindUnique(
+		db.Organization.ID.Equals(organizationID),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch organization: %w", err)
+	}
+	if organization.Personal {
+		return nil, ErrPersonalOrganization
+	}
+
+	_, err = s.client.Membership.FindFirst(
+		db.Membership.OrganizationID.Equals(organizationID),
+		db.Membership.User.Where(
+			db.User.Email.Equals(email),
+			db.User.Email.Mode(db.QueryModeInsensitive),
+		),
+	).Exec(ctx)
+	if err == nil {
+		return nil, ErrAlreadyMember
+	}
+	if !errors.Is(err, db.ErrNotFound) {
+		return nil, fmt.Errorf("failed to check membership: %w", err)
+	}
+
+	token, tokenHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+
+	invitation, err := s.client.OrganizationInvitation.CreateOne(
+		db.OrganizationInvitation.Email.Set(email),
+		db.OrganizationInvitation.TokenHash.Set(tokenHash),
+		db.OrganizationInvitation.ExpiresAt.Set(time.Now().Add(invitationExpiry)),
+		db.OrganizationInvitation.Organization.Link(db.Organization.ID.Equals(organizationID)),
+		db.OrganizationInvitation.InvitedBy.Link(db.User.ID.Equals(userID)),
+		db.OrganizationInvitation.Role.Set(role),
+	).With(
+		db.OrganizationInvitation.InvitedBy.Fetch(),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	body := fmt.Sprintf(`Hi,
+
+%s invited you to join %s as %s. Open the link below to accept:
+
+%s
+
+The link expires in 7 days. If you don't have an account yet, sign up with this email address first.`,
+		invitation.InvitedBy().Name, organization.Name, formatOrganizationRole(role), appURL("/invitations/accept?token="+token))
+
+	if err := s.mailer.Send(email, "You're invited to join "+organization.Name, body); err != nil {
+		fmt.Printf("Failed to send invitation to %s: %v\n", email, err)
+	}
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeMemberInvited,
+		Description: "Invited " + email + " to " + organization.Name,
+		TargetType:  models.ActivityTargetOrganization,
+		TargetID:    organizationID,
+		Metadata: map[string]interface{}{
+			"email": email,
+			"role":  formatOrganizationRole(role),
+		},
+	})
+
+	response := toInvitationResponse(invitation)
+	return &response, nil
+}
+
+// ListInvitations returns the pending invitations of an organization. Owners and admins only.
+func (s *OrganizationService) ListInvitations(ctx context.Context, userID string, organizationID string) ([]models.InvitationResponse, error) {
+	membership, err := findMembership(ctx, s.client, organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManageMembers(membership.Role) {
+		return nil, ErrInsufficientRole
+	}
+
+	invitations, err := s.client.OrganizationInvitation.FindMany(
+		db.OrganizationInvitation.OrganizationID.Equals(organizationID),
+		db.OrganizationInvitation.AcceptedAt.IsNull(),
+		db.OrganizationInvitation.RevokedAt.IsNull(),
+		db.OrganizationInvitation.ExpiresAt.After(time.Now()),
+	).With(
+		db.OrganizationInvitation.InvitedBy.Fetch(),
+	).OrderBy(
+		db.OrganizationInvitation.CreatedAt.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch invitations: %w", err)
+	}
+
+	response := []models.InvitationResponse{}
+	for i := range invitations {
+		response = append(response, toInvitationResponse(&invitations[i]))
+	}
+
+	return response, nil
+}
+
+// RevokeInvitation cancels a pending invitation. Owners and admins only.
+func (s *OrganizationService) RevokeInvitation(ctx context.Context, userID string, organizationID string, invitationID string) error {
+	membership, err := findMembership(ctx, s.client, organizationID, userID)
+	if err != nil {
+		return err
+	}
+	if !canManageMembers(membership.Role) {
+		return ErrInsufficientRole
+	}
+
+	result, err := s.client.OrganizationInvitation.FindMany(
+		db.OrganizationInvitation.ID.Equals(invitationID),
+		db.OrganizationInvitation.OrganizationID.Equals(organizationID),
+		db.OrganizationInvitation.AcceptedAt.IsNull(),
+		db.OrganizationInvitation.RevokedAt.IsNull(),
+	).Update(
+		db.OrganizationInvitation.RevokedAt.Set(time.Now()),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to revoke invitation: %w", err)
+	}
+	if result.Count == 0 {
+		return ErrInvitationNotFound
+	}
+
+	return nil
+}
+
+// AcceptInvitation adds the user to the organization of an invitation sent to their email
+func (s *OrganizationService) AcceptInvitation(ctx context.Context, userID string, token string) (*models.OrganizationResponse, error) {
+	invitation, err := s.client.OrganizationInvitation.FindUnique(
+		db.OrganizationInvitation.TokenHash.Equals(utils.HashOpaqueToken(token)),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch invitation: %w", err)
+	}
+	if _, accepted := invitation.AcceptedAt(); accepted {
+		return nil, ErrInvitationNotFound
+	}
+	if _, revoked := invitation.RevokedAt(); revoked {
+		return nil, ErrInvitationNotFound
+	}
+	if invitation.ExpiresAt.Before(time.Now()) {
+		return nil, ErrInvitationNotFound
+	}
+
+	user, err := s.client.User.FindUnique(
+		db.User.ID.Equals(userID),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, ErrInvitationEmailMismatch
+	}
+
+	if _, err := findMembership(ctx, s.client, invitation.OrganizationID, userID); err == nil {
+		return nil, ErrAlreadyMember
+	} else if !errors.Is(err, ErrOrganizationNotFound) {
+		return nil, err
+	}
+
+	// Claim the invitation first so it cannot be accepted twice
+	claimed, err := s.client.OrganizationInvitation.FindMany(
+		db.OrganizationInvitation.ID.Equals(invitation.ID),
+		db.OrganizationInvitation.AcceptedAt.IsNull(),
+	).Update(
+		db.OrganizationInvitation.AcceptedAt.Set(time.Now()),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+	if claimed.Count == 0 {
+		return nil, ErrInvitationNotFound
+	}
+
+	membership, err := s.client.Membership.CreateOne(
+		db.Membership.User.Link(db.User.ID.Equals(userID)),
+		db.Membership.Organization.Link(db.Organization.ID.Equals(invitation.OrganizationID)),
+		db.Membership.Role.Set(invitation.Role),
+	).With(
+		db.Membership.Organization.Fetch(),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create membership: %w", err)
+	}
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeMemberJoined,
+		Description: "Joined organization " + membership.Organization().Name,
+		TargetType:  models.ActivityTargetOrganization,
+		TargetID:    membership.OrganizationID,
+		Metadata: map[string]interface{}{
+			"role": formatOrganizationRole(membership.Role),
+		},
+	})
+
+	response := toOrganizationResponse(membership)
+	return &response, nil
+}
+
+// ResolveOrganization returns the ID of the organization a request works in:
+// the requested one if the user is a member, otherwise their personal organization
+func (s *OrganizationService) ResolveOrganization(ctx context.Context, userID string, organizationID string) (string, error) {
+	membership, err := resolveMembership(ctx, s.client, userID, organizationID)
+	if err != nil {
+		return "", err
+	}
+
+	return membership.OrganizationID, nil
+}
+
+// ensureAnotherOwner returns ErrLastOwner unless someone other than userID owns the organization
+func (s *OrganizationService) ensureAnotherOwner(ctx context.Context, organizationID string, userID string) error {
+	owners, err := s.client.Membership.FindMany(
+		db.Membership.OrganizationID.Equals(organizationID),
+		db.Membership.Role.Equals(db.OrganizationRoleOwner),
+		db.Membership.Not(db.Membership.UserID.Equals(userID)),
+	).Take(1).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch owners: %w", err)
+	}
+	if len(owners) == 0 {
+		return ErrLastOwner
+	}
+
+	return nil
+}
+
+// resolveMembership returns the user's membership in the requested
+// organization, or in their personal organization when none is requested
+func resolveMembership(ctx context.Context, client *db.PrismaClient, userID string, organizationID string) (*db.MembershipModel, error) {
+	if organizationID == "" {
+		return personalMembership(ctx, client, userID)
+	}
+
+	return findMembership(ctx, client, organizationID, userID)
+}
+
+// findMembership returns the user's membership in an organization
+func findMembership(ctx context.Context, client *db.PrismaClient, organizationID string, userID string) (*db.MembershipModel, error) {
+	membership, err := client.Membership.FindUnique(
+		db.Membership.OrganizationIDUserID(
+			db.Membership.OrganizationID.Equals(organizationID),
+			db.Membership.UserID.Equals(userID),
+		),
+	).With(
+		db.Membership.Organization.Fetch(),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch membership: %w", err)
+	}
+
+	return membership, nil
+}
+
+// personalMembership returns the user's membership in their personal
+// organization, creating the organization if it is missing
+func personalMembership(ctx context.Context, client *db.PrismaClient, userID string) (*db.MembershipModel, error) {
+	membership, err := client.Membership.FindFirst(
+		db.Membership.UserID.Equals(userID),
+		db.Membership.Organization.Where(
+			db.Organization.Personal.Equals(true),
+		),
+	).With(
+		db.Membership.Organization.Fetch(),
+	).Exec(ctx)
+	if err == nil {
+		return membership, nil
+	}
+	if !errors.Is(err, db.ErrNotFound) {
+		return nil, fmt.Errorf("failed to fetch personal organization: %w", err)
+	}
+
+	user, err := client.User.FindUnique(
+		db.User.ID.Equals(userID),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	return createOrganization(ctx, client, userID, user.Name+"'s workspace", true)
+}
+
+// createOrganization creates an organization with the user as its owner
+func createOrganization(ctx context.Context, client *db.PrismaClient, userID string, name string, personal bool) (*db.MembershipModel, error) {
+	organization, err := client.Organization.CreateOne(
+		db.Organization.Name.Set(name),
+		db.Organization.Personal.Set(personal),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	membership, err := client.Membership.CreateOne(
+		db.Membership.User.Link(db.User.ID.Equals(userID)),
+		db.Membership.Organization.Link(db.Organization.ID.Equals(organization.ID)),
+		db.Membership.Role.Set(db.OrganizationRoleOwner),
+	).With(
+		db.Membership.Organization.Fetch(),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create membership: %w", err)
+	}
+
+	return membership, nil
+}
+
+// memberOf matches organizations the user belongs to
+func memberOf(userID string) db.OrganizationWhereParam {
+	return db.Organization.Memberships.Some(
+		db.Membership.UserID.Equals(userID),
+	)
+}
+
+// canManageMembers reports whether a role may invite, remove and rename
+func canManageMembers(role db.OrganizationRole) bool {
+	return role == db.OrganizationRoleOwner || role == db.OrganizationRoleAdmin
+}
+
+// canModerate reports whether a member may delete or restore an item created by creatorID.
+// Owners and admins can moderate everything; members only what they created.
+func canModerate(membership *db.MembershipModel, creatorID string) bool {
+	return canManageMembers(membership.Role) || membership.UserID == creatorID
+}
+
+// parseOrganizationRole converts a client supplied role (e.g. "admin") into an OrganizationRole
+func parseOrganizationRole(value string) (db.OrganizationRole, error) {
+	role := db.OrganizationRole(strings.ToUpper(strings.TrimSpace(value)))
+	switch role {
+	case db.OrganizationRoleOwner, db.OrganizationRoleAdmin, db.OrganizationRoleMember:
+		return role, nil
+	}
+
+	return "", fmt.Errorf("%w: unknown role %q", ErrInvalidOrganizationRequest, value)
+}
+
+// formatOrganizationRole returns the lowercase role name used in API responses
+func formatOrganizationRole(role db.OrganizationRole) string {
+	return strings.ToLower(string(role))
+}
+
+func toOrganizationResponse(m *db.MembershipModel) models.OrganizationResponse {
+	organization := m.Organization()
+	return models.OrganizationResponse{
+		ID:        organization.ID,
+		Name:      organization.Name,
+		Personal:  organization.Personal,
+		Role:      formatOrganizationRole(m.Role),
+		CreatedAt: organization.CreatedAt,
+	}
+}
+
+func toInvitationResponse(i *db.OrganizationInvitationModel) models.InvitationResponse {
+	return models.InvitationResponse{
+		ID:        i.ID,
+		Email:     i.Email,
+		Role:      formatOrganizationRole(i.Role),
+		InvitedBy: i.InvitedBy().Name,
+		ExpiresAt: i.ExpiresAt,
+		CreatedAt: i.CreatedAt,
+	}
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+)
+
+const (
+	// defaultCampaignDelay is the pause between campaign emails when no pacing is given
+	defaultCampaignDelay = 2 * time.Minute
+	// bulkSendDelay is the pause between the emails of a synchronous send to all contacts
+	bulkSendDelay = 2 * time.Second
+	// maxSendDelay caps the configurable delay between two emails
+	maxSendDelay = time.Hour
+	// maxWarmUpDays caps how long a new sender account ramps up
+	maxWarmUpDays = 90
+)
+
+// ErrInvalidPacing is returned when a campaign's pacing settings are out of range
+var ErrInvalidPacing = errors.New("invalid pacing")
+
+// Clock tells the time and waits. The pacer takes it as a dependency so it
+// can be driven by a fake clock without sleeping.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+// RealClock is the wall clock
+type RealClock struct{}
+
+func (RealClock) Now() time.Time        { return time.Now() }
+func (RealClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// pacing is how fast one campaign sends
+type pacing struct {
+	minDelay   time.Duration
+	maxDelay   time.Duration
+	maxPerHour int // 0 means no hourly limit
+	warmUpDays int // 0 means no warm-up
+}
+
+// normalizePacing validates client supplied pacing; nil keeps the fixed two
+// minute delay campaigns always had
+func normalizePacing(req *models.Pacing) (pacing, error) {
+	p := pacing{minDelay: defaultCampaignDelay, maxDelay: defaultCampaignDelay}
+	if req == nil {
+		return p, nil
+	}
+
+	if req.MinDelaySeconds != nil {
+		p.minDelay = time.Duration(*req.MinDelaySeconds) * time.Second
+	}
+	if req.MaxDelaySeconds != nil {
+		p.maxDelay = time.Duration(*req.MaxDelaySeconds) * time.Second
+	} else if req.MinDelaySeconds != nil {
+		p.maxDelay = p.minDelay
+	}
+	if p.minDelay < 0 || p.maxDelay > maxSendDelay {
+		return p, fmt.Errorf("%w: delays must be between 0 and %d seconds", ErrInvalidPacing, int(maxSendDelay.Seconds()))
+	}
+	if p.minDelay > p.maxDelay {
+		return p, fmt.Errorf("%w: min_delay_seconds must not be greater than max_delay_seconds", ErrInvalidPacing)
+	}
+
+	if req.MaxPerHour != nil {
+		if *req.MaxPerHour < 1 {
+			return p, fmt.Errorf("%w: max_per_hour must be at least 1", ErrInvalidPacing)
+		}
+		p.maxPerHour = *req.MaxPerHour
+	}
+	if req.WarmUpDays != nil {
+		if *req.WarmUpDays < 1 || *req.WarmUpDays > maxWarmUpDays {
+			return p, fmt.Errorf("%w: warm_up_days must be between 1 and %d", ErrInvalidPacing, maxWarmUpDays)
+		}
+		p.warmUpDays = *req.WarmUpDays
+	}
+
+	return p, nil
+}
+
+// campaignPacing reads the pacing stored on a campaign
+func campaignPacing(c *db.CampaignModel) pacing {
+	p := pacing{
+		minDelay: time.Duration(c.MinDelaySeconds) * time.Second,
+		maxDelay: time.Duration(c.MaxDelaySeconds) * time.Second,
+	}
+	if v, ok := c.MaxPerHour(); ok {
+		p.maxPerHour = v
+	}
+	if v, ok := c.WarmUpDays(); ok {
+		p.warmUpDays = v
+	}
+
+	return p
+}
+
+// Pacer spaces out the emails of one campaign run. After every email it
+// waits a random delay between the minimum and maximum, and longer when the
+// hourly limit is reached.
+type Pacer struct {
+	pacing pacing
+	clock  Clock
+	random *rand.Rand
+
+	// nextAllowed is the earliest time of the next email by the delay alone
+	nextAllowed time.Time
+	// recent holds the send times of the last hour, oldest first
+	recent []time.Time
+}
+
+// NewPacer creates a pacer. recent are send times of earlier runs of the
+// campaign, so the hourly limit holds across pauses.
+func NewPacer(p pacing, clock Clock, random *rand.Rand, recent []time.Time) *Pacer {
+	pacer := &Pacer{pacing: p, clock: clock, random: random}
+	for _, sentAt := range recent {
+		pacer.record(sentAt)
+	}
+
+	return pacer
+}
+
+// Delay returns how long to wait before the next email may be sent
+func (p *Pacer) Delay() time.Duration {
+	now := p.clock.Now()
+	next := p.nextAllowed
+
+	if p.pacing.maxPerHour > 0 {
+		p.forget(now)
+		if len(p.recent) >= p.pacing.maxPerHour {
+			// The oldest send that counts against the limit must leave the hour
+			if freed := p.recent[len(p.recent)-p.pacing.maxPerHour].Add(time.Hour); freed.After(next) {
+				next = freed
+			}
+		}
+	}
+
+	if delay := next.Sub(now); delay > 0 {
+		return delay
+	}
+	return 0
+}
+
+// Wait blocks until the next email may be sent
+func (p *Pacer) Wait() {
+	if delay := p.Delay(); delay > 0 {
+		p.clock.Sleep(delay)
+	}
+}
+
+// Sent records an email sent now and draws the delay before the next one
+func (p *Pacer) Sent() {
+	p.record(p.clock.Now())
+}
+
+func (p *Pacer) record(sentAt time.Time) {
+	delay := p.pacing.minDelay
+	if spread := p.pacing.maxDelay - p.pacing.minDelay; spread > 0 {
+		delay += time.Duration(p.random.Int64N(int64(spread) + 1))
+	}
+	if next := sentAt.Add(delay); next.After(p.nextAllowed) {
+		p.nextAllowed = next
+	}
+
+	if p.pacing.maxPerHour > 0 {
+		p.recent = append(p.recent, sentAt)
+	}
+}
+
+// forget drops send times older than an hour
+func (p *Pacer) forget(now time.Time) {
+	cutoff := now.Add(-time.Hour)
+	i := 0
+	for i < len(p.recent) && !p.recent[i].After(cutoff) {
+		i++
+	}
+	p.recent = p.recent[i:]
+}
+
+// newRandom returns a randomly seeded source for the delay jitter
+func newRandom() *rand.Rand {
+	return rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+}
+
+// warmUpLimit returns the daily limit of a sender account during warm-up. A
+// new account may send a growing share of its limit on each of its first
+// warmUpDays days, at least one email.
+func warmUpLimit(account *db.SenderAccountModel, warmUpDays int, now time.Time) int {
+	if warmUpDays <= 0 {
+		return account.DailyLimit
+	}
+
+	day := int(now.Sub(account.CreatedAt)/(24*time.Hour)) + 1
+	if day >= warmUpDays {
+		return account.DailyLimit
+	}
+
+	limit := account.DailyLimit * day / warmUpDays
+	if limit < 1 {
+		limit = 1
+	}
+	return limit
+}
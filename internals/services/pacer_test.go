@@ -0,0 +1,131 @@
+package services
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+)
+
+// fakeClock is a Clock whose Sleep moves the time forward instantly
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time        { return c.now }
+func (c *fakeClock) Sleep(d time.Duration) { c.now = c.now.Add(d) }
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)}
+}
+
+func TestPacerDelayWithinBounds(t *testing.T) {
+	clock := newFakeClock()
+	p := pacing{minDelay: 30 * time.Second, maxDelay: 90 * time.Second}
+	pacer := NewPacer(p, clock, rand.New(rand.NewPCG(1, 2)), nil)
+
+	if delay := pacer.Delay(); delay != 0 {
+		t.Fatalf("first email: got delay %v, want 0", delay)
+	}
+
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 200; i++ {
+		pacer.Sent()
+		delay := pacer.Delay()
+		if delay < p.minDelay || delay > p.maxDelay {
+			t.Fatalf("email %d: delay %v outside [%v, %v]", i, delay, p.minDelay, p.maxDelay)
+		}
+		seen[delay] = true
+		pacer.Wait()
+		if delay := pacer.Delay(); delay != 0 {
+			t.Fatalf("email %d: got delay %v after waiting, want 0", i, delay)
+		}
+	}
+	if len(seen) < 2 {
+		t.Fatalf("delays are not jittered: %v", seen)
+	}
+}
+
+func TestPacerFixedDelay(t *testing.T) {
+	clock := newFakeClock()
+	pacer := NewPacer(pacing{minDelay: time.Minute, maxDelay: time.Minute}, clock, rand.New(rand.NewPCG(1, 2)), nil)
+
+	pacer.Sent()
+	clock.Sleep(20 * time.Second)
+	if delay := pacer.Delay(); delay != 40*time.Second {
+		t.Fatalf("got delay %v, want 40s", delay)
+	}
+}
+
+func TestPacerHourlyLimit(t *testing.T) {
+	clock := newFakeClock()
+	start := clock.Now()
+	pacer := NewPacer(pacing{maxPerHour: 3}, clock, rand.New(rand.NewPCG(1, 2)), nil)
+
+	for i := 0; i < 3; i++ {
+		if delay := pacer.Delay(); delay != 0 {
+			t.Fatalf("email %d: got delay %v, want 0", i, delay)
+		}
+		pacer.Sent()
+		clock.Sleep(time.Minute)
+	}
+
+	// The fourth email waits until the first leaves the hour
+	if delay, want := pacer.Delay(), start.Add(time.Hour).Sub(clock.Now()); delay != want {
+		t.Fatalf("got delay %v, want %v", delay, want)
+	}
+	pacer.Wait()
+	if !clock.Now().Equal(start.Add(time.Hour)) {
+		t.Fatalf("waited until %v, want %v", clock.Now(), start.Add(time.Hour))
+	}
+	if delay := pacer.Delay(); delay != 0 {
+		t.Fatalf("got delay %v after waiting, want 0", delay)
+	}
+}
+
+func TestPacerHourlyLimitCountsEarlierRuns(t *testing.T) {
+	clock := newFakeClock()
+	recent := []time.Time{
+		clock.Now().Add(-90 * time.Minute), // outside the hour
+		clock.Now().Add(-50 * time.Minute),
+		clock.Now().Add(-20 * time.Minute),
+	}
+	pacer := NewPacer(pacing{maxPerHour: 2}, clock, rand.New(rand.NewPCG(1, 2)), recent)
+
+	if delay := pacer.Delay(); delay != 10*time.Minute {
+		t.Fatalf("got delay %v, want 10m", delay)
+	}
+}
+
+func TestWarmUpLimit(t *testing.T) {
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	account := func(dailyLimit int) *db.SenderAccountModel {
+		return &db.SenderAccountModel{
+			InnerSenderAccount: db.InnerSenderAccount{DailyLimit: dailyLimit, CreatedAt: createdAt},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		dailyLimit int
+		warmUpDays int
+		age        time.Duration
+		want       int
+	}{
+		{"no warm-up", 50, 0, 0, 50},
+		{"first day", 50, 10, time.Hour, 5},
+		{"fifth day", 50, 10, 4*24*time.Hour + time.Hour, 25},
+		{"last warm-up day", 50, 10, 9 * 24 * time.Hour, 50},
+		{"after warm-up", 50, 10, 30 * 24 * time.Hour, 50},
+		{"at least one email", 5, 10, time.Hour, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := warmUpLimit(account(tt.dailyLimit), tt.warmUpDays, createdAt.Add(tt.age))
+			if got != tt.want {
+				t.Fatalf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,407 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/internals/utils"
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+)
+
+const (
+	// maxMessagesPerSync limits how many messages one sync fetches; the rest
+	// are fetched by the next one
+	maxMessagesPerSync = 200
+	// maxSyncErrorLength is how much of an IMAP error is kept on the account
+	maxSyncErrorLength = 500
+)
+
+// ErrReplySyncDisabled is returned when reply detection is off for a sender account
+var ErrReplySyncDisabled = errors.New("reply detection is not enabled for this sender account")
+
+// ErrReplySyncInProgress is returned when the inbox of a sender account is already being checked
+var ErrReplySyncInProgress = errors.New("the inbox of this sender account is already being checked")
+
+// ErrIMAPSyncFailed is returned when the IMAP server cannot be reached or rejects a command
+var ErrIMAPSyncFailed = errors.New("failed to check inbox")
+
+// ReplySyncService detects replies from contacts by polling the inboxes of
+// sender accounts over IMAP
+type ReplySyncService struct {
+	client     *db.PrismaClient
+	activities *ActivityService
+	clock      Clock
+	// dial opens an authenticated IMAP session for an account; replaced to
+	// sync against a local server
+	dial func(account *db.SenderAccountModel) (*imapClient, error)
+
+	mu      sync.Mutex
+	syncing map[string]bool
+}
+
+// NewReplySyncService creates a new reply sync service. Sync times and
+// stopped follow-ups read the time from clock.
+func NewReplySyncService(client *db.PrismaClient, clock Clock) *ReplySyncService {
+	return &ReplySyncService{
+		client:     client,
+		activities: NewActivityService(client),
+		clock:      clock,
+		dial:       openIMAPSession,
+		syncing:    map[string]bool{},
+	}
+}
+
+// RunSyncLoop checks the inboxes of sender accounts with reply detection
+// enabled every interval until ctx is cancelled
+func (s *ReplySyncService) RunSyncLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.SyncAll(ctx); err != nil {
+			fmt.Printf("Failed to check inboxes for replies: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncAll starts a worker for every account with reply detection enabled
+// whose inbox is not already being checked
+func (s *ReplySyncService) SyncAll(ctx context.Context) error {
+	accounts, err := s.client.SenderAccount.FindMany(
+		db.SenderAccount.ImapEnabled.Equals(true),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch sender accounts: %w", err)
+	}
+
+	for i := range accounts {
+		account := accounts[i]
+		if !s.beginSync(account.ID) {
+			continue
+		}
+		go func() {
+			defer s.endSync(account.ID)
+			if _, err := s.syncAccount(ctx, &account); err != nil {
+				fmt.Printf("Failed to check inbox of %s: %v\n", account.Email, err)
+			}
+		}()
+	}
+
+	return nil
+}
+
+// SyncSenderAccount checks the inbox of one of the user's accounts right away
+func (s *ReplySyncService) SyncSenderAccount(ctx context.Context, userID string, accountID string) (*models.ReplySyncResponse, error) {
+	account, err := s.client.SenderAccount.FindFirst(
+		db.SenderAccount.ID.Equals(accountID),
+		db.SenderAccount.UserID.Equals(userID),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrSenderAccountNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch sender account: %w", err)
+	}
+	if !account.ImapEnabled {
+		return nil, ErrReplySyncDisabled
+	}
+
+	if !s.beginSync(account.ID) {
+		return nil, ErrReplySyncInProgress
+	}
+	defer s.endSync(account.ID)
+
+	return s.syncAccount(ctx, account)
+}
+
+func (s *ReplySyncService) beginSync(accountID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.syncing[accountID] {
+		return false
+	}
+	s.syncing[accountID] = true
+	return true
+}
+
+func (s *ReplySyncService) endSync(accountID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.syncing, accountID)
+}
+
+// syncAccount fetches the new messages of an account's inbox and records the
+// replies among them. The outcome is stored on the account.
+func (s *ReplySyncService) syncAccount(ctx context.Context, account *db.SenderAccountModel) (*models.ReplySyncResponse, error) {
+	result := &models.ReplySyncResponse{}
+
+	err := s.syncInbox(ctx, account, result)
+	result.SyncedAt = s.clock.Now()
+
+	syncError := db.SenderAccount.ImapError.SetOptional(nil)
+	if err != nil {
+		syncError = db.SenderAccount.ImapError.Set(truncateRunes(err.Error(), maxSyncErrorLength))
+	}
+	_, updateErr := s.client.SenderAccount.FindUnique(
+		db.SenderAccount.ID.Equals(account.ID),
+	).Update(
+		db.SenderAccount.ImapSyncedAt.Set(result.SyncedAt),
+		syncError,
+	).Exec(ctx)
+	if updateErr != nil && !errors.Is(updateErr, db.ErrNotFound) {
+		fmt.Printf("Failed to save sync status of %s: %v\n", account.Email, updateErr)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// syncInbox fetches the messages that arrived since the last sync, oldest
+// first. The first sync, and any sync after the mailbox was recreated, looks
+// back to the day the account was added.
+func (s *ReplySyncService) syncInbox(ctx context.Context, account *db.SenderAccountModel, result *models.ReplySyncResponse) error {
+	session, err := s.dial(account)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrIMAPSyncFailed, err)
+	}
+	defer session.Logout()
+
+	uidValidity, err := session.Select("INBOX")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrIMAPSyncFailed, err)
+	}
+
+	var lastUID uint32
+	criteria := "SINCE " + account.CreatedAt.UTC().Format("2-Jan-2006")
+	storedValidity, hasValidity := account.ImapUIDValidity()
+	storedUID, hasUID := account.ImapLastUID()
+	if hasValidity && hasUID && uint32(storedValidity) == uidValidity {
+		lastUID = uint32(storedUID)
+		criteria = fmt.Sprintf("UID %d:*", lastUID+1)
+	}
+
+	uids, err := session.SearchUIDs(criteria)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrIMAPSyncFailed, err)
+	}
+
+	for _, uid := range uids {
+		// UID n:* also returns the newest message when nothing is newer
+		if uid <= lastUID {
+			continue
+		}
+		if result.Checked >= maxMessagesPerSync {
+			break
+		}
+
+		raw, err := session.FetchMessage(uid)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrIMAPSyncFailed, err)
+		}
+		result.Checked++
+
+		email, err := parseInboundEmail(raw)
+		if err != nil {
+			fmt.Printf("Failed to parse message %d of %s: %v\n", uid, account.Email, err)
+		} else {
+			recorded, err := s.recordReply(ctx, account, email, uidValidity, uid)
+			if err != nil {
+				return err
+			}
+			if recorded {
+				result.Replies++
+			}
+		}
+
+		// Saved after every message so a failed sync resumes where it stopped
+		_, err = s.client.SenderAccount.FindUnique(
+			db.SenderAccount.ID.Equals(account.ID),
+		).Update(
+			db.SenderAccount.ImapUIDValidity.Set(db.BigInt(uidValidity)),
+			db.SenderAccount.ImapLastUID.Set(db.BigInt(uid)),
+		).Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to save sync position: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// recordReply stores a message that answers an email sent to a contact,
+// marks the contact as replied and stops their follow-ups. It reports whether
+// the message was a reply seen for the first time.
+func (s *ReplySyncService) recordReply(ctx context.Context, account *db.SenderAccountModel, email *inboundEmail, uidValidity uint32, uid uint32) (bool, error) {
+	if email.automated || email.fromEmail == "" || strings.EqualFold(email.fromEmail, account.Email) {
+		return false, nil
+	}
+
+	log, byHeader, err := s.matchSentEmail(ctx, account, email)
+	if err != nil || log == nil {
+		return false, err
+	}
+	contactID, _ := log.ContactID()
+
+	contact, err := s.client.Contact.FindFirst(
+		db.Contact.ID.Equals(contactID),
+		db.Contact.DeletedAt.IsNull(),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to fetch contact: %w", err)
+	}
+
+	messageID := email.messageID
+	if messageID == "" {
+		// Without a Message-ID the message is told apart by its place in the mailbox
+		messageID = fmt.Sprintf("%d.%d@imap", uidValidity, uid)
+	}
+
+	params := []db.InboundMessageSetParam{
+		db.InboundMessage.SenderAccount.Link(db.SenderAccount.ID.Equals(account.ID)),
+	}
+	if len(email.inReplyTo) > 0 {
+		params = append(params, db.InboundMessage.InReplyTo.Set(email.inReplyTo[0]))
+	}
+	if email.fromName != "" {
+		params = append(params, db.InboundMessage.FromName.Set(email.fromName))
+	}
+	if byHeader {
+		params = append(params, db.InboundMessage.EmailLog.Link(db.EmailLog.ID.Equals(log.ID)))
+	}
+
+	_, err = s.client.InboundMessage.CreateOne(
+		db.InboundMessage.MessageID.Set(messageID),
+		db.InboundMessage.FromEmail.Set(email.fromEmail),
+		db.InboundMessage.Subject.Set(email.subject),
+		db.InboundMessage.Body.Set(email.body),
+		db.InboundMessage.ReceivedAt.Set(email.receivedAt),
+		db.InboundMessage.Contact.Link(db.Contact.ID.Equals(contact.ID)),
+		params...,
+	).Exec(ctx)
+	if err != nil {
+		// Stored by an earlier sync, e.g. after the mailbox was recreated
+		if _, ok := db.IsErrUniqueConstraint(err); ok {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to store reply: %w", err)
+	}
+
+	// Later stages (e.g. interviewing) are kept
+	marked, err := s.client.Contact.FindMany(
+		db.Contact.ID.Equals(contact.ID),
+		db.Contact.Status.In([]db.ContactStatus{db.ContactStatusNew, db.ContactStatusContacted}),
+	).Update(
+		db.Contact.Status.Set(db.ContactStatusReplied),
+	).Exec(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark contact as replied: %w", err)
+	}
+	if marked.Count > 0 {
+		if err := recordStatusChange(ctx, s.client, contact.ID, contact.Status, db.ContactStatusReplied); err != nil {
+			fmt.Printf("Failed to record status change: %v\n", err)
+		}
+	}
+
+	stopped, err := stopEnrollments(ctx, s.client, db.EnrollmentStopReasonReplied, s.clock.Now(),
+		db.SequenceEnrollment.ContactID.Equals(contact.ID),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	s.activities.Record(ctx, account.UserID, ActivityEvent{
+		Type:        db.ActivityTypeReplyReceived,
+		Description: "Reply received from " + contact.Email,
+		TargetType:  models.ActivityTargetContact,
+		TargetID:    contact.ID,
+		Metadata: map[string]interface{}{
+			"sender_account":     account.Email,
+			"subject":            email.subject,
+			"follow_ups_stopped": stopped,
+		},
+	})
+
+	return true, nil
+}
+
+// matchSentEmail finds the email to a contact a message answers: first by
+// the Message-IDs in its In-Reply-To and References headers, then by the
+// latest email this account sent to its sender. It reports whether the match
+// was by header.
+func (s *ReplySyncService) matchSentEmail(ctx context.Context, account *db.SenderAccountModel, email *inboundEmail) (*db.EmailLogModel, bool, error) {
+	ids := append(append([]string{}, email.inReplyTo...), email.references...)
+	if len(ids) > 0 {
+		logs, err := s.client.EmailLog.FindMany(
+			db.EmailLog.UserID.Equals(account.UserID),
+			db.EmailLog.MessageID.In(ids),
+		).OrderBy(
+			db.EmailLog.CreatedAt.Order(db.SortOrderDesc),
+		).Exec(ctx)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to match reply: %w", err)
+		}
+		for i := range logs {
+			if _, ok := logs[i].ContactID(); ok {
+				return &logs[i], true, nil
+			}
+		}
+	}
+
+	logs, err := s.client.EmailLog.FindMany(
+		db.EmailLog.SenderAccountID.Equals(account.ID),
+		db.EmailLog.Status.Equals(db.EmailStatusSent),
+		db.EmailLog.Recipient.Equals(email.fromEmail),
+		db.EmailLog.Recipient.Mode(db.QueryModeInsensitive),
+	).OrderBy(
+		db.EmailLog.CreatedAt.Order(db.SortOrderDesc),
+	).Take(10).Exec(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to match reply: %w", err)
+	}
+	for i := range logs {
+		if _, ok := logs[i].ContactID(); ok {
+			return &logs[i], false, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+// openIMAPSession connects to an account's IMAP server and logs in with its
+// SMTP username and password
+func openIMAPSession(account *db.SenderAccountModel) (*imapClient, error) {
+	password, err := utils.DecryptSecret(account.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt password of sender account %s: %w", account.Email, err)
+	}
+
+	session, err := dialIMAP(account.ImapHost, account.ImapPort)
+	if err != nil {
+		return nil, err
+	}
+	if err := session.Login(account.Username, password); err != nil {
+		session.Logout()
+		return nil, err
+	}
+
+	return session, nil
+}
@@ -0,0 +1,356 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+	"github.com/steebchen/prisma-client-go/engine/mock"
+	"github.com/steebchen/prisma-client-go/runtime/builder"
+)
+
+func newTestReplySyncService(client *db.PrismaClient, server *fakeIMAPServer) *ReplySyncService {
+	s := NewReplySyncService(client, newFakeClock())
+	if server != nil {
+		s.dial = func(*db.SenderAccountModel) (*imapClient, error) {
+			return server.connect()
+		}
+	}
+	return s
+}
+
+func testSenderAccount() *db.SenderAccountModel {
+	return &db.SenderAccountModel{
+		InnerSenderAccount: db.InnerSenderAccount{
+			ID:          "account-1",
+			Email:       "recruiter@example.com",
+			UserID:      "user-1",
+			ImapEnabled: true,
+			CreatedAt:   time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC),
+		},
+	}
+}
+
+// expectCount expects a FindMany().Update() or DeleteMany query that changes count rows
+func expectCount(m *db.Mock, query interface{ ExtractQuery() builder.Query }, count int) {
+	*m.Expectations = append(*m.Expectations, mock.Expectation{
+		Query: query.ExtractQuery(),
+		Want:  &db.BatchResult{Count: count},
+	})
+}
+
+// autoReply is a message reply detection ignores without touching the database
+func autoReply(n int) string {
+	return fmt.Sprintf("From: contact%d@example.com\r\nAuto-Submitted: auto-replied\r\nSubject: Out of office\r\n\r\nAway\r\n", n)
+}
+
+func expectSyncPosition(client *db.PrismaClient, m *db.Mock, uidValidity uint32, uid uint32) {
+	m.SenderAccount.Expect(
+		client.SenderAccount.FindUnique(
+			db.SenderAccount.ID.Equals("account-1"),
+		).Update(
+			db.SenderAccount.ImapUIDValidity.Set(db.BigInt(uidValidity)),
+			db.SenderAccount.ImapLastUID.Set(db.BigInt(uid)),
+		),
+	).Returns(db.SenderAccountModel{})
+}
+
+func TestSyncInboxContinuesAfterLastUID(t *testing.T) {
+	client, m, ensure := db.NewMock()
+	defer ensure(t)
+
+	server := newFakeIMAPServer(7)
+	for uid := uint32(3); uid <= 6; uid++ {
+		server.add(uid, autoReply(int(uid)))
+	}
+	account := testSenderAccount()
+	validity, lastUID := db.BigInt(7), db.BigInt(4)
+	account.InnerSenderAccount.ImapUIDValidity = &validity
+	account.InnerSenderAccount.ImapLastUID = &lastUID
+
+	expectSyncPosition(client, m, 7, 5)
+	expectSyncPosition(client, m, 7, 6)
+
+	result := &models.ReplySyncResponse{}
+	if err := newTestReplySyncService(client, server).syncInbox(context.Background(), account, result); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := server.searched(); !reflect.DeepEqual(got, []string{"UID 5:*"}) {
+		t.Fatalf("got searches %q, want UID 5:*", got)
+	}
+	if result.Checked != 2 || result.Replies != 0 {
+		t.Fatalf("got %d checked and %d replies, want 2 and 0", result.Checked, result.Replies)
+	}
+}
+
+func TestSyncInboxNothingNew(t *testing.T) {
+	client, _, _ := db.NewMock()
+
+	server := newFakeIMAPServer(7)
+	server.add(3, autoReply(3))
+	account := testSenderAccount()
+	validity, lastUID := db.BigInt(7), db.BigInt(3)
+	account.InnerSenderAccount.ImapUIDValidity = &validity
+	account.InnerSenderAccount.ImapLastUID = &lastUID
+
+	// The server answers UID 4:* with message 3, which is skipped
+	result := &models.ReplySyncResponse{}
+	if err := newTestReplySyncService(client, server).syncInbox(context.Background(), account, result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Checked != 0 {
+		t.Fatalf("got %d checked, want 0", result.Checked)
+	}
+}
+
+func TestSyncInboxUIDValidityReset(t *testing.T) {
+	tests := []struct {
+		name     string
+		validity *db.BigInt
+		lastUID  *db.BigInt
+	}{
+		{"first sync", nil, nil},
+		{"mailbox recreated", bigInt(5), bigInt(40)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, m, ensure := db.NewMock()
+			defer ensure(t)
+
+			// UIDs of the new mailbox are lower than the stored last UID
+			server := newFakeIMAPServer(9)
+			server.add(2, autoReply(2))
+			server.add(3, autoReply(3))
+			account := testSenderAccount()
+			account.InnerSenderAccount.ImapUIDValidity = tt.validity
+			account.InnerSenderAccount.ImapLastUID = tt.lastUID
+
+			expectSyncPosition(client, m, 9, 2)
+			expectSyncPosition(client, m, 9, 3)
+
+			result := &models.ReplySyncResponse{}
+			if err := newTestReplySyncService(client, server).syncInbox(context.Background(), account, result); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := server.searched(); !reflect.DeepEqual(got, []string{"SINCE 1-Mar-2026"}) {
+				t.Fatalf("got searches %q, want SINCE 1-Mar-2026", got)
+			}
+			if result.Checked != 2 {
+				t.Fatalf("got %d checked, want 2", result.Checked)
+			}
+		})
+	}
+}
+
+func bigInt(v int64) *db.BigInt {
+	b := db.BigInt(v)
+	return &b
+}
+
+func strPtr(v string) *string {
+	return &v
+}
+
+func emailLog(id string, contactID string) db.EmailLogModel {
+	log := db.EmailLogModel{
+		InnerEmailLog: db.InnerEmailLog{ID: id, Status: db.EmailStatusSent, UserID: "user-1"},
+	}
+	if contactID != "" {
+		log.InnerEmailLog.ContactID = strPtr(contactID)
+	}
+	return log
+}
+
+func expectHeaderMatch(client *db.PrismaClient, m *db.Mock, ids []string, logs []db.EmailLogModel) {
+	m.EmailLog.Expect(
+		client.EmailLog.FindMany(
+			db.EmailLog.UserID.Equals("user-1"),
+			db.EmailLog.MessageID.In(ids),
+		).OrderBy(
+			db.EmailLog.CreatedAt.Order(db.SortOrderDesc),
+		),
+	).ReturnsMany(logs)
+}
+
+func expectSenderMatch(client *db.PrismaClient, m *db.Mock, from string, logs []db.EmailLogModel) {
+	m.EmailLog.Expect(
+		client.EmailLog.FindMany(
+			db.EmailLog.SenderAccountID.Equals("account-1"),
+			db.EmailLog.Status.Equals(db.EmailStatusSent),
+			db.EmailLog.Recipient.Equals(from),
+			db.EmailLog.Recipient.Mode(db.QueryModeInsensitive),
+		).OrderBy(
+			db.EmailLog.CreatedAt.Order(db.SortOrderDesc),
+		).Take(10),
+	).ReturnsMany(logs)
+}
+
+func TestMatchSentEmailByHeader(t *testing.T) {
+	client, m, ensure := db.NewMock()
+	defer ensure(t)
+
+	email := &inboundEmail{
+		fromEmail:  "jane@example.com",
+		inReplyTo:  []string{"b@mail"},
+		references: []string{"a@mail", "b@mail"},
+	}
+	// A log without a contact, e.g. of a deleted contact, is passed over
+	expectHeaderMatch(client, m, []string{"b@mail", "a@mail", "b@mail"}, []db.EmailLogModel{
+		emailLog("log-2", ""),
+		emailLog("log-1", "contact-1"),
+	})
+
+	log, byHeader, err := newTestReplySyncService(client, nil).matchSentEmail(context.Background(), testSenderAccount(), email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if log == nil || log.ID != "log-1" || !byHeader {
+		t.Fatalf("got %v by header %v, want log-1 by header", log, byHeader)
+	}
+}
+
+func TestMatchSentEmailBySender(t *testing.T) {
+	client, m, ensure := db.NewMock()
+	defer ensure(t)
+
+	email := &inboundEmail{
+		fromEmail: "jane@example.com",
+		inReplyTo: []string{"unknown@mail"},
+	}
+	expectHeaderMatch(client, m, []string{"unknown@mail"}, []db.EmailLogModel{})
+	expectSenderMatch(client, m, "jane@example.com", []db.EmailLogModel{emailLog("log-3", "contact-1")})
+
+	log, byHeader, err := newTestReplySyncService(client, nil).matchSentEmail(context.Background(), testSenderAccount(), email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if log == nil || log.ID != "log-3" || byHeader {
+		t.Fatalf("got %v by header %v, want log-3 by sender", log, byHeader)
+	}
+}
+
+func TestMatchSentEmailNoMatch(t *testing.T) {
+	client, m, ensure := db.NewMock()
+	defer ensure(t)
+
+	expectSenderMatch(client, m, "stranger@example.com", []db.EmailLogModel{emailLog("log-4", "")})
+
+	log, _, err := newTestReplySyncService(client, nil).matchSentEmail(context.Background(), testSenderAccount(), &inboundEmail{fromEmail: "stranger@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if log != nil {
+		t.Fatalf("got %v, want no match", log.ID)
+	}
+}
+
+func TestRecordReplyStopsFollowUps(t *testing.T) {
+	client, m, ensure := db.NewMock()
+	defer ensure(t)
+
+	s := newTestReplySyncService(client, nil)
+	account := testSenderAccount()
+	receivedAt := time.Date(2026, 3, 3, 10, 0, 0, 0, time.UTC)
+	email := &inboundEmail{
+		messageID:  "reply-1@example.com",
+		inReplyTo:  []string{"sent-1@mail"},
+		fromEmail:  "jane@example.com",
+		fromName:   "Jane",
+		subject:    "Re: Hello",
+		body:       "Sounds good",
+		receivedAt: receivedAt,
+	}
+
+	expectHeaderMatch(client, m, []string{"sent-1@mail"}, []db.EmailLogModel{emailLog("log-1", "contact-1")})
+	m.Contact.Expect(
+		client.Contact.FindFirst(
+			db.Contact.ID.Equals("contact-1"),
+			db.Contact.DeletedAt.IsNull(),
+		),
+	).Returns(db.ContactModel{
+		InnerContact: db.InnerContact{ID: "contact-1", Email: "jane@example.com", Status: db.ContactStatusContacted},
+	})
+	m.InboundMessage.Expect(
+		client.InboundMessage.CreateOne(
+			db.InboundMessage.MessageID.Set("reply-1@example.com"),
+			db.InboundMessage.FromEmail.Set("jane@example.com"),
+			db.InboundMessage.Subject.Set("Re: Hello"),
+			db.InboundMessage.Body.Set("Sounds good"),
+			db.InboundMessage.ReceivedAt.Set(receivedAt),
+			db.InboundMessage.Contact.Link(db.Contact.ID.Equals("contact-1")),
+			db.InboundMessage.SenderAccount.Link(db.SenderAccount.ID.Equals("account-1")),
+			db.InboundMessage.InReplyTo.Set("sent-1@mail"),
+			db.InboundMessage.FromName.Set("Jane"),
+			db.InboundMessage.EmailLog.Link(db.EmailLog.ID.Equals("log-1")),
+		),
+	).Returns(db.InboundMessageModel{})
+	expectCount(m, client.Contact.FindMany(
+		db.Contact.ID.Equals("contact-1"),
+		db.Contact.Status.In([]db.ContactStatus{db.ContactStatusNew, db.ContactStatusContacted}),
+	).Update(
+		db.Contact.Status.Set(db.ContactStatusReplied),
+	), 1)
+	m.ContactStatusChange.Expect(
+		client.ContactStatusChange.CreateOne(
+			db.ContactStatusChange.FromStatus.Set(db.ContactStatusContacted),
+			db.ContactStatusChange.ToStatus.Set(db.ContactStatusReplied),
+			db.ContactStatusChange.Contact.Link(db.Contact.ID.Equals("contact-1")),
+		),
+	).Returns(db.ContactStatusChangeModel{})
+	expectCount(m, client.SequenceEnrollment.FindMany(
+		db.SequenceEnrollment.ContactID.Equals("contact-1"),
+		db.SequenceEnrollment.Status.Equals(db.EnrollmentStatusActive),
+	).Update(
+		db.SequenceEnrollment.Status.Set(db.EnrollmentStatusStopped),
+		db.SequenceEnrollment.StopReason.Set(db.EnrollmentStopReasonReplied),
+		db.SequenceEnrollment.StoppedAt.Set(s.clock.Now()),
+		db.SequenceEnrollment.NextSendAt.SetOptional(nil),
+	), 2)
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"sender_account":     "recruiter@example.com",
+		"subject":            "Re: Hello",
+		"follow_ups_stopped": 2,
+	})
+	m.Activity.Expect(
+		client.Activity.CreateOne(
+			db.Activity.Type.Set(db.ActivityTypeReplyReceived),
+			db.Activity.Description.Set("Reply received from jane@example.com"),
+			db.Activity.User.Link(db.User.ID.Equals("user-1")),
+			db.Activity.TargetType.Set(models.ActivityTargetContact),
+			db.Activity.TargetID.Set("contact-1"),
+			db.Activity.Metadata.Set(metadata),
+		),
+	).Returns(db.ActivityModel{})
+
+	recorded, err := s.recordReply(context.Background(), account, email, 9, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !recorded {
+		t.Fatal("reply was not recorded")
+	}
+}
+
+func TestRecordReplyIgnoresAutomatedAndOwnMessages(t *testing.T) {
+	client, _, _ := db.NewMock()
+	s := newTestReplySyncService(client, nil)
+
+	for _, email := range []*inboundEmail{
+		{fromEmail: "jane@example.com", inReplyTo: []string{"sent-1@mail"}, automated: true},
+		{fromEmail: "Recruiter@Example.com", inReplyTo: []string{"sent-1@mail"}},
+		{fromEmail: ""},
+	} {
+		// Any database query would fail, since the mock expects none
+		recorded, err := s.recordReply(context.Background(), testSenderAccount(), email, 9, 3)
+		if err != nil || recorded {
+			t.Fatalf("%+v: got %v, %v; want not recorded", email, recorded, err)
+		}
+	}
+}
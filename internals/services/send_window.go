@@ -0,0 +1,179 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+)
+
+// ErrInvalidSendWindow is returned when a send window has unknown days or invalid times
+var ErrInvalidSendWindow = errors.New("invalid send window")
+
+// ErrInvalidTimezone is returned when a time zone is not a known IANA name
+var ErrInvalidTimezone = errors.New("invalid time zone, use an IANA name such as Europe/Berlin")
+
+// weekdays maps the day names accepted in send windows to weekdays
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// sendWindow is a parsed models.SendWindow; start and end are minutes after midnight
+type sendWindow struct {
+	days  [7]bool
+	start int
+	end   int
+}
+
+// normalizeSendWindows validates windows and returns them with short
+// lowercase day names, in the form they are stored in
+func normalizeSendWindows(windows []models.SendWindow) ([]models.SendWindow, error) {
+	normalized := []models.SendWindow{}
+	for _, window := range windows {
+		parsed, err := parseSendWindow(window)
+		if err != nil {
+			return nil, err
+		}
+
+		days := []string{}
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if parsed.days[day] {
+				days = append(days, strings.ToLower(day.String()[:3]))
+			}
+		}
+		normalized = append(normalized, models.SendWindow{
+			Days:  days,
+			Start: formatClock(parsed.start),
+			End:   formatClock(parsed.end),
+		})
+	}
+
+	return normalized, nil
+}
+
+func parseSendWindow(window models.SendWindow) (sendWindow, error) {
+	parsed := sendWindow{}
+	if len(window.Days) == 0 {
+		return parsed, fmt.Errorf("%w: days are required", ErrInvalidSendWindow)
+	}
+	for _, name := range window.Days {
+		day, ok := weekdays[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return parsed, fmt.Errorf("%w: unknown day %q", ErrInvalidSendWindow, name)
+		}
+		parsed.days[day] = true
+	}
+
+	var err error
+	if parsed.start, err = parseClock(window.Start); err != nil {
+		return parsed, err
+	}
+	if parsed.end, err = parseClock(window.End); err != nil {
+		return parsed, err
+	}
+	if parsed.start >= parsed.end {
+		return parsed, fmt.Errorf("%w: start must be before end", ErrInvalidSendWindow)
+	}
+
+	return parsed, nil
+}
+
+// parseClock parses "HH:MM" into minutes after midnight; "24:00" ends a window at midnight
+func parseClock(value string) (int, error) {
+	hours, minutes, ok := strings.Cut(strings.TrimSpace(value), ":")
+	h, errH := strconv.Atoi(hours)
+	m, errM := strconv.Atoi(minutes)
+	if !ok || errH != nil || errM != nil || h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("%w: time %q must be HH:MM", ErrInvalidSendWindow, value)
+	}
+
+	return h*60 + m, nil
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// loadTimezone loads an IANA time zone
+func loadTimezone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+
+	return loc, nil
+}
+
+// campaignSendWindows returns the parsed send windows of a campaign; a
+// campaign without windows may send at any time
+func campaignSendWindows(c *db.CampaignModel) ([]sendWindow, error) {
+	raw, ok := c.SendWindows()
+	if !ok {
+		return nil, nil
+	}
+
+	var windows []models.SendWindow
+	if err := json.Unmarshal(raw, &windows); err != nil {
+		return nil, fmt.Errorf("failed to decode send windows: %w", err)
+	}
+
+	parsed := make([]sendWindow, 0, len(windows))
+	for _, window := range windows {
+		w, err := parseSendWindow(window)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, w)
+	}
+
+	return parsed, nil
+}
+
+// nextWindowOpen returns t when it falls inside a window, otherwise the time
+// the next window opens. Without windows every time is inside.
+func nextWindowOpen(windows []sendWindow, loc *time.Location, t time.Time) time.Time {
+	if len(windows) == 0 {
+		return t
+	}
+
+	local := t.In(loc)
+	var next time.Time
+	// A week ahead covers every weekday; one more day covers a window that
+	// already closed today and opens again next week
+	for offset := 0; offset <= 7; offset++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, loc)
+		for _, window := range windows {
+			if !window.days[day.Weekday()] {
+				continue
+			}
+			opens := time.Date(day.Year(), day.Month(), day.Day(), window.start/60, window.start%60, 0, 0, loc)
+			closes := time.Date(day.Year(), day.Month(), day.Day(), window.end/60, window.end%60, 0, 0, loc)
+			if !t.Before(opens) && t.Before(closes) {
+				return t
+			}
+			if opens.After(t) && (next.IsZero() || opens.Before(next)) {
+				next = opens
+			}
+		}
+		if !next.IsZero() {
+			return next
+		}
+	}
+
+	return next
+}
@@ -0,0 +1,555 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/internals/utils"
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+)
+
+const (
+	// maxSenderAccountsPerUser limits how many mailboxes a user can add
+	maxSenderAccountsPerUser = 25
+	// defaultSenderDailyLimit is used when a new account has no daily limit
+	defaultSenderDailyLimit = 20
+	// defaultSMTPHost and defaultSMTPPort are Gmail's SMTPS server
+	defaultSMTPHost = "smtp.gmail.com"
+	defaultSMTPPort = 465
+	// defaultIMAPHost and defaultIMAPPort are Gmail's IMAPS server, used for reply detection
+	defaultIMAPHost = "imap.gmail.com"
+	defaultIMAPPort = 993
+)
+
+// ErrSenderAccountNotFound is returned when an account does not exist or belongs to another user
+var ErrSenderAccountNotFound = errors.New("sender account not found")
+
+// ErrInvalidSenderAccount is returned when the settings of a sender account are invalid
+var ErrInvalidSenderAccount = errors.New("invalid sender account")
+
+// ErrSenderAccountExists is returned when the user already added the email address
+var ErrSenderAccountExists = errors.New("a sender account with this email already exists")
+
+// ErrTooManySenderAccounts is returned when the user already has the maximum number of accounts
+var ErrTooManySenderAccounts = fmt.Errorf("a user can have at most %d sender accounts", maxSenderAccountsPerUser)
+
+// ErrInvalidSenderRotation is returned when a campaign asks for an unknown rotation
+var ErrInvalidSenderRotation = errors.New("sender_rotation must be round_robin or least_used")
+
+// ErrNoSenderAvailable is returned when every sender account of a campaign
+// reached its daily limit or was deleted
+var ErrNoSenderAvailable = errors.New("no sender account is below its daily limit")
+
+// SenderAccountService manages the mailboxes a user sends from
+type SenderAccountService struct {
+	client     *db.PrismaClient
+	activities *ActivityService
+}
+
+// NewSenderAccountService creates a new sender account service
+func NewSenderAccountService(client *db.PrismaClient) *SenderAccountService {
+	return &SenderAccountService{
+		client:     client,
+		activities: NewActivityService(client),
+	}
+}
+
+// CreateSenderAccount adds a mailbox. The password is stored encrypted.
+func (s *SenderAccountService) CreateSenderAccount(ctx context.Context, userID string, req models.CreateSenderAccountRequest) (*models.SenderAccountResponse, error) {
+	email := strings.TrimSpace(req.Email)
+	if !strings.Contains(email, "@") {
+		return nil, fmt.Errorf("%w: a valid email is required", ErrInvalidSenderAccount)
+	}
+	if req.Password == "" {
+		return nil, fmt.Errorf("%w: password is required", ErrInvalidSenderAccount)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = email
+	}
+	host := strings.TrimSpace(req.SMTPHost)
+	if host == "" {
+		host = defaultSMTPHost
+	}
+	port := req.SMTPPort
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+	username := strings.TrimSpace(req.Username)
+	if username == "" {
+		username = email
+	}
+	dailyLimit := req.DailyLimit
+	if dailyLimit == 0 {
+		dailyLimit = defaultSenderDailyLimit
+	}
+	imapHost := strings.TrimSpace(req.IMAPHost)
+	if imapHost == "" {
+		imapHost = defaultIMAPHost
+	}
+	imapPort := req.IMAPPort
+	if imapPort == 0 {
+		imapPort = defaultIMAPPort
+	}
+	if err := validateSenderSettings(port, imapPort, dailyLimit); err != nil {
+		return nil, err
+	}
+	if err := validateMailHost(ctx, "smtp_host", host); err != nil {
+		return nil, err
+	}
+	if err := validateMailHost(ctx, "imap_host", imapHost); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.client.SenderAccount.FindMany(
+		db.SenderAccount.UserID.Equals(userID),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sender accounts: %w", err)
+	}
+	if len(existing) >= maxSenderAccountsPerUser {
+		return nil, ErrTooManySenderAccounts
+	}
+
+	encryptedPassword, err := utils.EncryptSecret(req.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt password: %w", err)
+	}
+
+	params := []db.SenderAccountSetParam{
+		db.SenderAccount.SMTPHost.Set(host),
+		db.SenderAccount.SMTPPort.Set(port),
+		db.SenderAccount.DailyLimit.Set(dailyLimit),
+		db.SenderAccount.ImapEnabled.Set(req.IMAPEnabled),
+		db.SenderAccount.ImapHost.Set(imapHost),
+		db.SenderAccount.ImapPort.Set(imapPort),
+	}
+	if signature := strings.TrimSpace(req.Signature); signature != "" {
+		params = append(params, db.SenderAccount.Signature.Set(signature))
+	}
+
+	account, err := s.client.SenderAccount.CreateOne(
+		db.SenderAccount.Name.Set(name),
+		db.SenderAccount.Email.Set(email),
+		db.SenderAccount.Username.Set(username),
+		db.SenderAccount.Password.Set(encryptedPassword),
+		db.SenderAccount.User.Link(db.User.ID.Equals(userID)),
+		params...,
+	).Exec(ctx)
+	if err != nil {
+		if _, ok := db.IsErrUniqueConstraint(err); ok {
+			return nil, ErrSenderAccountExists
+		}
+		return nil, fmt.Errorf("failed to create sender account: %w", err)
+	}
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeSenderAccountCreated,
+		Description: "Added sender account " + email,
+		TargetType:  models.ActivityTargetSenderAccount,
+		TargetID:    account.ID,
+		Metadata: map[string]interface{}{
+			"email":        email,
+			"daily_limit":  dailyLimit,
+			"imap_enabled": req.IMAPEnabled,
+		},
+	})
+
+	response := toSenderAccountResponse(account, 0)
+	return &response, nil
+}
+
+// ListSenderAccounts returns the user's accounts, oldest first, with how many
+// emails each sent today
+func (s *SenderAccountService) ListSenderAccounts(ctx context.Context, userID string) ([]models.SenderAccountResponse, error) {
+	accounts, err := s.client.SenderAccount.FindMany(
+		db.SenderAccount.UserID.Equals(userID),
+	).OrderBy(
+		db.SenderAccount.CreatedAt.Order(db.SortOrderAsc),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sender accounts: %w", err)
+	}
+
+	sentToday, err := s.sentToday(ctx, accounts, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	response := []models.SenderAccountResponse{}
+	for i := range accounts {
+		response = append(response, toSenderAccountResponse(&accounts[i], sentToday[accounts[i].ID]))
+	}
+
+	return response, nil
+}
+
+// UpdateSenderAccount changes the settings of one of the user's accounts
+func (s *SenderAccountService) UpdateSenderAccount(ctx context.Context, userID string, accountID string, req models.UpdateSenderAccountRequest) (*models.SenderAccountResponse, error) {
+	account, err := s.findSenderAccount(ctx, userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	port := account.SMTPPort
+	if req.SMTPPort != nil {
+		port = *req.SMTPPort
+	}
+	dailyLimit := account.DailyLimit
+	if req.DailyLimit != nil {
+		dailyLimit = *req.DailyLimit
+	}
+	imapPort := account.ImapPort
+	if req.IMAPPort != nil {
+		imapPort = *req.IMAPPort
+	}
+	if err := validateSenderSettings(port, imapPort, dailyLimit); err != nil {
+		return nil, err
+	}
+
+	params := []db.SenderAccountSetParam{
+		db.SenderAccount.SMTPPort.Set(port),
+		db.SenderAccount.DailyLimit.Set(dailyLimit),
+		db.SenderAccount.ImapPort.Set(imapPort),
+	}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: name cannot be empty", ErrInvalidSenderAccount)
+		}
+		params = append(params, db.SenderAccount.Name.Set(name))
+	}
+	if req.SMTPHost != nil {
+		host := strings.TrimSpace(*req.SMTPHost)
+		if host == "" {
+			return nil, fmt.Errorf("%w: smtp_host cannot be empty", ErrInvalidSenderAccount)
+		}
+		if err := validateMailHost(ctx, "smtp_host", host); err != nil {
+			return nil, err
+		}
+		params = append(params, db.SenderAccount.SMTPHost.Set(host))
+	}
+	if req.Username != nil {
+		username := strings.TrimSpace(*req.Username)
+		if username == "" {
+			return nil, fmt.Errorf("%w: username cannot be empty", ErrInvalidSenderAccount)
+		}
+		params = append(params, db.SenderAccount.Username.Set(username))
+	}
+	if req.Password != nil {
+		if *req.Password == "" {
+			return nil, fmt.Errorf("%w: password cannot be empty", ErrInvalidSenderAccount)
+		}
+		encryptedPassword, err := utils.EncryptSecret(*req.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt password: %w", err)
+		}
+		params = append(params, db.SenderAccount.Password.Set(encryptedPassword))
+	}
+	if req.Signature != nil {
+		if signature := strings.TrimSpace(*req.Signature); signature != "" {
+			params = append(params, db.SenderAccount.Signature.Set(signature))
+		} else {
+			params = append(params, db.SenderAccount.Signature.SetOptional(nil))
+		}
+	}
+	if req.IMAPHost != nil {
+		imapHost := strings.TrimSpace(*req.IMAPHost)
+		if imapHost == "" {
+			return nil, fmt.Errorf("%w: imap_host cannot be empty", ErrInvalidSenderAccount)
+		}
+		if err := validateMailHost(ctx, "imap_host", imapHost); err != nil {
+			return nil, err
+		}
+		params = append(params, db.SenderAccount.ImapHost.Set(imapHost))
+	}
+	if req.IMAPEnabled != nil {
+		params = append(params, db.SenderAccount.ImapEnabled.Set(*req.IMAPEnabled))
+		if !*req.IMAPEnabled {
+			params = append(params, db.SenderAccount.ImapError.SetOptional(nil))
+		}
+	}
+
+	updated, err := s.client.SenderAccount.FindUnique(
+		db.SenderAccount.ID.Equals(account.ID),
+	).Update(
+		params...,
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update sender account: %w", err)
+	}
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeSenderAccountUpdated,
+		Description: "Updated sender account " + updated.Email,
+		TargetType:  models.ActivityTargetSenderAccount,
+		TargetID:    updated.ID,
+		Metadata: map[string]interface{}{
+			"password_changed": req.Password != nil,
+			"imap_enabled":     updated.ImapEnabled,
+		},
+	})
+
+	sentToday, err := s.sentToday(ctx, []db.SenderAccountModel{*updated}, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	response := toSenderAccountResponse(updated, sentToday[updated.ID])
+	return &response, nil
+}
+
+// DeleteSenderAccount removes one of the user's accounts. Running campaigns
+// continue with their other accounts.
+func (s *SenderAccountService) DeleteSenderAccount(ctx context.Context, userID string, accountID string) error {
+	account, err := s.findSenderAccount(ctx, userID, accountID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.SenderAccount.FindUnique(
+		db.SenderAccount.ID.Equals(account.ID),
+	).Delete().Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete sender account: %w", err)
+	}
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeSenderAccountDeleted,
+		Description: "Removed sender account " + account.Email,
+		TargetType:  models.ActivityTargetSenderAccount,
+		TargetID:    account.ID,
+		Metadata: map[string]interface{}{
+			"email": account.Email,
+		},
+	})
+
+	return nil
+}
+
+// findSenderAccount fetches an account of the user
+func (s *SenderAccountService) findSenderAccount(ctx context.Context, userID string, accountID string) (*db.SenderAccountModel, error) {
+	account, err := s.client.SenderAccount.FindFirst(
+		db.SenderAccount.ID.Equals(accountID),
+		db.SenderAccount.UserID.Equals(userID),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrSenderAccountNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch sender account: %w", err)
+	}
+
+	return account, nil
+}
+
+// resolveSenderAccounts checks that every ID is one of the user's accounts and
+// returns the IDs without duplicates
+func (s *SenderAccountService) resolveSenderAccounts(ctx context.Context, userID string, accountIDs []string) ([]string, error) {
+	seen := map[string]bool{}
+	ids := []string{}
+	for _, id := range accountIDs {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+
+	accounts, err := s.client.SenderAccount.FindMany(
+		db.SenderAccount.ID.In(ids),
+		db.SenderAccount.UserID.Equals(userID),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sender accounts: %w", err)
+	}
+	if len(accounts) != len(ids) {
+		return nil, ErrSenderAccountNotFound
+	}
+
+	return ids, nil
+}
+
+// nextSender picks the account of a campaign that sends the next email.
+// Accounts that reached their daily limit (UTC), lowered while they warm up,
+// are passed over.
+func (s *SenderAccountService) nextSender(ctx context.Context, campaignID string, rotation db.SenderRotation, lastAccountID string, warmUpDays int, now time.Time) (*db.SenderAccountModel, error) {
+	// Fetched for every email so edits and deletions apply to running campaigns
+	accounts, err := s.client.SenderAccount.FindMany(
+		db.SenderAccount.Campaigns.Some(
+			db.CampaignSender.CampaignID.Equals(campaignID),
+		),
+	).OrderBy(
+		db.SenderAccount.CreatedAt.Order(db.SortOrderAsc),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sender accounts: %w", err)
+	}
+
+	sentToday, err := s.sentToday(ctx, accounts, now)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := make(map[string]int, len(accounts))
+	for i := range accounts {
+		limits[accounts[i].ID] = warmUpLimit(&accounts[i], warmUpDays, now)
+	}
+
+	account := pickSender(accounts, sentToday, limits, rotation, lastAccountID)
+	if account == nil {
+		return nil, ErrNoSenderAvailable
+	}
+
+	return account, nil
+}
+
+// pickSender applies the rotation to accounts below their limit. Round
+// robin takes the first one after the last used account; least used takes the
+// one that sent the fewest emails today.
+func pickSender(accounts []db.SenderAccountModel, sentToday, limits map[string]int, rotation db.SenderRotation, lastAccountID string) *db.SenderAccountModel {
+	if rotation == db.SenderRotationLeastUsed {
+		var least *db.SenderAccountModel
+		for i := range accounts {
+			account := &accounts[i]
+			if sentToday[account.ID] >= limits[account.ID] {
+				continue
+			}
+			if least == nil || sentToday[account.ID] < sentToday[least.ID] {
+				least = account
+			}
+		}
+		return least
+	}
+
+	start := 0
+	for i := range accounts {
+		if accounts[i].ID == lastAccountID {
+			start = i + 1
+			break
+		}
+	}
+	for i := range accounts {
+		account := &accounts[(start+i)%len(accounts)]
+		if sentToday[account.ID] < limits[account.ID] {
+			return account
+		}
+	}
+
+	return nil
+}
+
+// sentToday counts the emails each account sent since midnight (UTC)
+func (s *SenderAccountService) sentToday(ctx context.Context, accounts []db.SenderAccountModel, now time.Time) (map[string]int, error) {
+	counts := map[string]int{}
+	if len(accounts) == 0 {
+		return counts, nil
+	}
+
+	ids := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		ids = append(ids, account.ID)
+	}
+
+	now = now.UTC()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	logs, err := s.client.EmailLog.FindMany(
+		db.EmailLog.SenderAccountID.In(ids),
+		db.EmailLog.Status.Equals(db.EmailStatusSent),
+		db.EmailLog.CreatedAt.Gte(startOfDay),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count sent emails: %w", err)
+	}
+
+	for _, log := range logs {
+		if id, ok := log.SenderAccountID(); ok {
+			counts[id]++
+		}
+	}
+
+	return counts, nil
+}
+
+// senderAccountCredentials returns the SMTP login of an account as the sender
+// fields of a request. The password is only decrypted here, right before sending.
+func senderAccountCredentials(account *db.SenderAccountModel) (models.SendEmailRequest, error) {
+	password, err := utils.DecryptSecret(account.Password)
+	if err != nil {
+		return models.SendEmailRequest{}, fmt.Errorf("failed to decrypt password of sender account %s: %w", account.Email, err)
+	}
+
+	return models.SendEmailRequest{
+		SenderEmail:    account.Email,
+		SenderUsername: account.Username,
+		SenderPassword: password,
+		SMTPHost:       account.SMTPHost,
+		SMTPPort:       account.SMTPPort,
+	}, nil
+}
+
+// parseSenderRotation converts a client supplied rotation (e.g. "least_used")
+// into a SenderRotation; empty means round robin
+func parseSenderRotation(value string) (db.SenderRotation, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return db.SenderRotationRoundRobin, nil
+	}
+
+	rotation := db.SenderRotation(strings.ToUpper(value))
+	switch rotation {
+	case db.SenderRotationRoundRobin, db.SenderRotationLeastUsed:
+		return rotation, nil
+	}
+
+	return "", ErrInvalidSenderRotation
+}
+
+func validateSenderSettings(port int, imapPort int, dailyLimit int) error {
+	if !smtpPorts[port] {
+		return fmt.Errorf("%w: smtp_port must be 25, 465, 587 or 2525", ErrInvalidSenderAccount)
+	}
+	if !imapPorts[imapPort] {
+		return fmt.Errorf("%w: imap_port must be 143 or 993", ErrInvalidSenderAccount)
+	}
+	if dailyLimit < 1 || dailyLimit > maxDailyLimit {
+		return fmt.Errorf("%w: daily_limit must be between 1 and %d", ErrInvalidSenderAccount, maxDailyLimit)
+	}
+	return nil
+}
+
+func toSenderAccountResponse(a *db.SenderAccountModel, sentToday int) models.SenderAccountResponse {
+	response := models.SenderAccountResponse{
+		ID:          a.ID,
+		Name:        a.Name,
+		Email:       a.Email,
+		SMTPHost:    a.SMTPHost,
+		SMTPPort:    a.SMTPPort,
+		Username:    a.Username,
+		DailyLimit:  a.DailyLimit,
+		SentToday:   sentToday,
+		IMAPEnabled: a.ImapEnabled,
+		IMAPHost:    a.ImapHost,
+		IMAPPort:    a.ImapPort,
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
+	}
+	if v, ok := a.Signature(); ok {
+		response.Signature = v
+	}
+	if v, ok := a.ImapSyncedAt(); ok {
+		response.IMAPSyncedAt = &v
+	}
+	if v, ok := a.ImapError(); ok {
+		response.IMAPError = v
+	}
+
+	return response
+}
@@ -0,0 +1,457 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+)
+
+const (
+	// maxSequenceSteps limits how many follow-ups a sequence can have
+	maxSequenceSteps = 10
+	// maxFollowUpDelayDays caps the delay before a follow-up
+	maxFollowUpDelayDays = 90
+)
+
+// ErrSequenceNotFound is returned when a sequence does not exist or belongs to another organization
+var ErrSequenceNotFound = errors.New("sequence not found")
+
+// ErrInvalidSequence is returned when a sequence has no name, no steps or invalid steps
+var ErrInvalidSequence = errors.New("invalid sequence")
+
+// ErrEnrollmentNotFound is returned when a contact is not enrolled in the sequence
+var ErrEnrollmentNotFound = errors.New("enrollment not found")
+
+// SequenceService manages follow-up sequences and the contacts enrolled in them
+type SequenceService struct {
+	client     *db.PrismaClient
+	activities *ActivityService
+}
+
+// NewSequenceService creates a new sequence service
+func NewSequenceService(client *db.PrismaClient) *SequenceService {
+	return &SequenceService{
+		client:     client,
+		activities: NewActivityService(client),
+	}
+}
+
+// CreateSequence adds a sequence to the organization. Every member can create one.
+func (s *SequenceService) CreateSequence(ctx context.Context, userID string, organizationID string, req models.SaveSequenceRequest) (*models.SequenceResponse, error) {
+	name, err := validateSequence(req)
+	if err != nil {
+		return nil, err
+	}
+
+	membership, err := resolveMembership(ctx, s.client, userID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The ID is chosen here so the steps can be created in the same transaction
+	sequenceID := uuid.NewString()
+	txs := []db.PrismaTransaction{
+		s.client.Sequence.CreateOne(
+			db.Sequence.Name.Set(name),
+			db.Sequence.User.Link(db.User.ID.Equals(userID)),
+			db.Sequence.Organization.Link(db.Organization.ID.Equals(membership.OrganizationID)),
+			db.Sequence.ID.Set(sequenceID),
+		).Tx(),
+	}
+	txs = append(txs, s.createSteps(sequenceID, req.Steps)...)
+	if err := s.client.Prisma.Transaction(txs...).Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create sequence: %w", err)
+	}
+
+	sequence, err := findSequence(ctx, s.client, membership.OrganizationID, sequenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeSequenceCreated,
+		Description: "Created sequence \"" + sequence.Name + "\"",
+		TargetType:  models.ActivityTargetSequence,
+		TargetID:    sequence.ID,
+		Metadata: map[string]interface{}{
+			"steps": len(req.Steps),
+		},
+	})
+
+	return s.sequenceResponse(ctx, sequence)
+}
+
+// ListSequences returns the organization's sequences, newest first
+func (s *SequenceService) ListSequences(ctx context.Context, userID string, organizationID string) ([]models.SequenceResponse, error) {
+	membership, err := resolveMembership(ctx, s.client, userID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	sequences, err := s.client.Sequence.FindMany(
+		db.Sequence.OrganizationID.Equals(membership.OrganizationID),
+	).With(
+		db.Sequence.Steps.Fetch().OrderBy(db.SequenceStep.Position.Order(db.SortOrderAsc)),
+	).OrderBy(
+		db.Sequence.CreatedAt.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sequences: %w", err)
+	}
+
+	response := make([]models.SequenceResponse, 0, len(sequences))
+	for i := range sequences {
+		item, err := s.sequenceResponse(ctx, &sequences[i])
+		if err != nil {
+			return nil, err
+		}
+		response = append(response, *item)
+	}
+
+	return response, nil
+}
+
+// GetSequence returns one of the organization's sequences
+func (s *SequenceService) GetSequence(ctx context.Context, userID string, organizationID string, sequenceID string) (*models.SequenceResponse, error) {
+	membership, err := resolveMembership(ctx, s.client, userID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	sequence, err := findSequence(ctx, s.client, membership.OrganizationID, sequenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sequenceResponse(ctx, sequence)
+}
+
+// UpdateSequence renames a sequence and replaces its steps. Enrolled contacts
+// continue with the new step after the ones they already received.
+func (s *SequenceService) UpdateSequence(ctx context.Context, userID string, organizationID string, sequenceID string, req models.SaveSequenceRequest) (*models.SequenceResponse, error) {
+	name, err := validateSequence(req)
+	if err != nil {
+		return nil, err
+	}
+
+	membership, err := resolveMembership(ctx, s.client, userID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	sequence, err := findSequence(ctx, s.client, membership.OrganizationID, sequenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := []db.PrismaTransaction{
+		s.client.Sequence.FindUnique(
+			db.Sequence.ID.Equals(sequence.ID),
+		).Update(
+			db.Sequence.Name.Set(name),
+		).Tx(),
+		s.client.SequenceStep.FindMany(
+			db.SequenceStep.SequenceID.Equals(sequence.ID),
+		).Delete().Tx(),
+	}
+	txs = append(txs, s.createSteps(sequence.ID, req.Steps)...)
+	if err := s.client.Prisma.Transaction(txs...).Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to update sequence: %w", err)
+	}
+
+	sequence, err = findSequence(ctx, s.client, membership.OrganizationID, sequence.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeSequenceUpdated,
+		Description: "Updated sequence \"" + sequence.Name + "\"",
+		TargetType:  models.ActivityTargetSequence,
+		TargetID:    sequence.ID,
+		Metadata: map[string]interface{}{
+			"steps": len(req.Steps),
+		},
+	})
+
+	return s.sequenceResponse(ctx, sequence)
+}
+
+// DeleteSequence removes a sequence and every pending follow-up of it.
+// Members can only delete a sequence they created.
+func (s *SequenceService) DeleteSequence(ctx context.Context, userID string, organizationID string, sequenceID string) error {
+	membership, err := resolveMembership(ctx, s.client, userID, organizationID)
+	if err != nil {
+		return err
+	}
+
+	sequence, err := findSequence(ctx, s.client, membership.OrganizationID, sequenceID)
+	if err != nil {
+		return err
+	}
+	if !canModerate(membership, sequence.UserID) {
+		return ErrInsufficientRole
+	}
+
+	_, err = s.client.Sequence.FindUnique(
+		db.Sequence.ID.Equals(sequence.ID),
+	).Delete().Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete sequence: %w", err)
+	}
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeSequenceDeleted,
+		Description: "Deleted sequence \"" + sequence.Name + "\"",
+		TargetType:  models.ActivityTargetSequence,
+		TargetID:    sequence.ID,
+	})
+
+	return nil
+}
+
+// ListEnrollments returns the contacts enrolled in a sequence, newest first
+func (s *SequenceService) ListEnrollments(ctx context.Context, userID string, organizationID string, sequenceID string) ([]models.EnrollmentResponse, error) {
+	membership, err := resolveMembership(ctx, s.client, userID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	sequence, err := findSequence(ctx, s.client, membership.OrganizationID, sequenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollments, err := s.client.SequenceEnrollment.FindMany(
+		db.SequenceEnrollment.SequenceID.Equals(sequence.ID),
+	).OrderBy(
+		db.SequenceEnrollment.CreatedAt.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch enrollments: %w", err)
+	}
+
+	response := make([]models.EnrollmentResponse, 0, len(enrollments))
+	for i := range enrollments {
+		response = append(response, toEnrollmentResponse(&enrollments[i]))
+	}
+
+	return response, nil
+}
+
+// StopEnrollment stops the follow-ups of one contact in a sequence
+func (s *SequenceService) StopEnrollment(ctx context.Context, userID string, organizationID string, sequenceID string, enrollmentID string) (*models.EnrollmentResponse, error) {
+	membership, err := resolveMembership(ctx, s.client, userID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	sequence, err := findSequence(ctx, s.client, membership.OrganizationID, sequenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollment, err := s.client.SequenceEnrollment.FindFirst(
+		db.SequenceEnrollment.ID.Equals(enrollmentID),
+		db.SequenceEnrollment.SequenceID.Equals(sequence.ID),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrEnrollmentNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch enrollment: %w", err)
+	}
+
+	if enrollment.Status == db.EnrollmentStatusActive {
+		if _, err := stopEnrollments(ctx, s.client, db.EnrollmentStopReasonManual, time.Now(),
+			db.SequenceEnrollment.ID.Equals(enrollment.ID),
+		); err != nil {
+			return nil, err
+		}
+
+		s.activities.Record(ctx, userID, ActivityEvent{
+			Type:        db.ActivityTypeFollowUpsStopped,
+			Description: "Stopped follow-ups of sequence \"" + sequence.Name + "\"",
+			TargetType:  models.ActivityTargetContact,
+			TargetID:    enrollment.ContactID,
+			Metadata: map[string]interface{}{
+				"sequence_id": sequence.ID,
+				"campaign_id": enrollment.CampaignID,
+			},
+		})
+
+		enrollment, err = s.client.SequenceEnrollment.FindUnique(
+			db.SequenceEnrollment.ID.Equals(enrollment.ID),
+		).Exec(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch enrollment: %w", err)
+		}
+	}
+
+	response := toEnrollmentResponse(enrollment)
+	return &response, nil
+}
+
+// findSequence fetches a sequence of the organization with its steps in order
+func findSequence(ctx context.Context, client *db.PrismaClient, organizationID string, sequenceID string) (*db.SequenceModel, error) {
+	sequence, err := client.Sequence.FindFirst(
+		db.Sequence.ID.Equals(sequenceID),
+		db.Sequence.OrganizationID.Equals(organizationID),
+	).With(
+		db.Sequence.Steps.Fetch().OrderBy(db.SequenceStep.Position.Order(db.SortOrderAsc)),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrSequenceNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch sequence: %w", err)
+	}
+
+	return sequence, nil
+}
+
+// createSteps builds the transaction steps that store the steps of a sequence, numbered from 1
+func (s *SequenceService) createSteps(sequenceID string, steps []models.SequenceStepRequest) []db.PrismaTransaction {
+	txs := make([]db.PrismaTransaction, 0, len(steps))
+	for i, step := range steps {
+		params := []db.SequenceStepSetParam{}
+		if subject := strings.TrimSpace(step.Subject); subject != "" {
+			params = append(params, db.SequenceStep.Subject.Set(subject))
+		}
+		txs = append(txs, s.client.SequenceStep.CreateOne(
+			db.SequenceStep.Position.Set(i+1),
+			db.SequenceStep.DelayDays.Set(step.DelayDays),
+			db.SequenceStep.Body.Set(step.Body),
+			db.SequenceStep.Sequence.Link(db.Sequence.ID.Equals(sequenceID)),
+			params...,
+		).Tx())
+	}
+
+	return txs
+}
+
+// sequenceResponse adds the enrollment counts to a sequence
+func (s *SequenceService) sequenceResponse(ctx context.Context, sequence *db.SequenceModel) (*models.SequenceResponse, error) {
+	enrollments, err := s.client.SequenceEnrollment.FindMany(
+		db.SequenceEnrollment.SequenceID.Equals(sequence.ID),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count enrollments: %w", err)
+	}
+
+	response := toSequenceResponse(sequence)
+	for _, enrollment := range enrollments {
+		switch enrollment.Status {
+		case db.EnrollmentStatusActive:
+			response.Active++
+		case db.EnrollmentStatusCompleted:
+			response.Completed++
+		case db.EnrollmentStatusStopped:
+			response.Stopped++
+		}
+	}
+
+	return &response, nil
+}
+
+// validateSequence checks the name and steps and returns the trimmed name
+func validateSequence(req models.SaveSequenceRequest) (string, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return "", fmt.Errorf("%w: name is required", ErrInvalidSequence)
+	}
+	if len(req.Steps) == 0 || len(req.Steps) > maxSequenceSteps {
+		return "", fmt.Errorf("%w: a sequence needs between 1 and %d steps", ErrInvalidSequence, maxSequenceSteps)
+	}
+	for i, step := range req.Steps {
+		if step.DelayDays < 1 || step.DelayDays > maxFollowUpDelayDays {
+			return "", fmt.Errorf("%w: delay_days of step %d must be between 1 and %d", ErrInvalidSequence, i+1, maxFollowUpDelayDays)
+		}
+		if strings.TrimSpace(step.Body) == "" {
+			return "", fmt.Errorf("%w: body of step %d is required", ErrInvalidSequence, i+1)
+		}
+	}
+
+	return name, nil
+}
+
+// stopEnrollments stops the active enrollments matching where and returns how many were stopped
+func stopEnrollments(ctx context.Context, client *db.PrismaClient, reason db.EnrollmentStopReason, stoppedAt time.Time, where ...db.SequenceEnrollmentWhereParam) (int, error) {
+	where = append(where, db.SequenceEnrollment.Status.Equals(db.EnrollmentStatusActive))
+	result, err := client.SequenceEnrollment.FindMany(
+		where...,
+	).Update(
+		db.SequenceEnrollment.Status.Set(db.EnrollmentStatusStopped),
+		db.SequenceEnrollment.StopReason.Set(reason),
+		db.SequenceEnrollment.StoppedAt.Set(stoppedAt),
+		db.SequenceEnrollment.NextSendAt.SetOptional(nil),
+	).Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stop follow-ups: %w", err)
+	}
+
+	return result.Count, nil
+}
+
+// hasReplied reports whether a contact's status means they answered, which
+// ends their follow-ups
+func hasReplied(status db.ContactStatus) bool {
+	switch status {
+	case db.ContactStatusReplied, db.ContactStatusInterviewing, db.ContactStatusRejected:
+		return true
+	}
+
+	return false
+}
+
+func toSequenceResponse(sequence *db.SequenceModel) models.SequenceResponse {
+	response := models.SequenceResponse{
+		ID:        sequence.ID,
+		Name:      sequence.Name,
+		Steps:     []models.SequenceStepResponse{},
+		CreatedAt: sequence.CreatedAt,
+		UpdatedAt: sequence.UpdatedAt,
+	}
+	for _, step := range sequence.Steps() {
+		item := models.SequenceStepResponse{
+			Position:  step.Position,
+			DelayDays: step.DelayDays,
+			Body:      step.Body,
+		}
+		if v, ok := step.Subject(); ok {
+			item.Subject = v
+		}
+		response.Steps = append(response.Steps, item)
+	}
+
+	return response
+}
+
+func toEnrollmentResponse(e *db.SequenceEnrollmentModel) models.EnrollmentResponse {
+	response := models.EnrollmentResponse{
+		ID:         e.ID,
+		SequenceID: e.SequenceID,
+		CampaignID: e.CampaignID,
+		ContactID:  e.ContactID,
+		Status:     strings.ToLower(string(e.Status)),
+		StepsSent:  e.StepsSent,
+		CreatedAt:  e.CreatedAt,
+	}
+	if v, ok := e.NextSendAt(); ok {
+		response.NextSendAt = &v
+	}
+	if v, ok := e.StopReason(); ok {
+		response.StopReason = strings.ToLower(string(v))
+	}
+	if v, ok := e.StoppedAt(); ok {
+		response.StoppedAt = &v
+	}
+
+	return response
+}
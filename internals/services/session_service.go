@@ -0,0 +1,384 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/internals/utils"
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+)
+
+// ErrInvalidRefreshToken is returned when a refresh token is unknown, expired or revoked
+var ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+
+// ErrRefreshTokenReused is returned when an already rotated refresh token is presented again
+var ErrRefreshTokenReused = errors.New("refresh token reuse detected, session revoked")
+
+// ErrSessionRevoked is returned when an access token belongs to a revoked or unknown session
+var ErrSessionRevoked = errors.New("session has been revoked")
+
+// ErrSessionNotFound is returned when a session does not exist or belongs to another user
+var ErrSessionNotFound = errors.New("session not found")
+
+// lastSeenInterval limits how often a session's last seen time is written
+const lastSeenInterval = 5 * time.Minute
+
+// sessionFamily holds what every refresh token issued for one login shares
+type sessionFamily struct {
+	id        string
+	startedAt time.Time
+	// expiresAt fixes the expiry of every token in the family; when zero each
+	// rotation extends the session by the refresh token expiry
+	expiresAt      time.Time
+	impersonatorID string
+}
+
+// SessionService issues, rotates and revokes refresh-token sessions
+type SessionService struct {
+	client     *db.PrismaClient
+	activities *ActivityService
+}
+
+// NewSessionService creates a new session service
+func NewSessionService(client *db.PrismaClient) *SessionService {
+	return &SessionService{
+		client:     client,
+		activities: NewActivityService(client),
+	}
+}
+
+// Issue starts a new session family for the user and returns its first token pair
+func (s *SessionService) Issue(ctx context.Context, userID string, email string, client models.ClientInfo) (*models.TokenResponse, error) {
+	return s.issue(ctx, userID, email, sessionFamily{id: uuid.NewString(), startedAt: time.Now()}, client)
+}
+
+// IssueImpersonation starts a session in which an admin acts as the user. It
+// cannot be extended past ttl and is marked as impersonated in the user's session list.
+func (s *SessionService) IssueImpersonation(ctx context.Context, userID string, email string, impersonatorID string, ttl time.Duration, client models.ClientInfo) (*models.TokenResponse, error) {
+	return s.issue(ctx, userID, email, sessionFamily{
+		id:             uuid.NewString(),
+		startedAt:      time.Now(),
+		expiresAt:      time.Now().Add(ttl),
+		impersonatorID: impersonatorID,
+	}, client)
+}
+
+// Refresh exchanges a refresh token for a new token pair. The presented token
+// is rotated out; presenting it again revokes the whole family.
+func (s *SessionService) Refresh(ctx context.Context, refreshToken string, client models.ClientInfo) (*models.TokenResponse, error) {
+	session, err := s.client.Session.FindUnique(
+		db.Session.TokenHash.Equals(utils.HashOpaqueToken(refreshToken)),
+	).With(
+		db.Session.User.Fetch(),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("failed to fetch session: %w", err)
+	}
+
+	if _, revoked := session.RevokedAt(); revoked || session.ExpiresAt.Before(time.Now()) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if isSuspended(session.User()) {
+		return nil, ErrAccountSuspended
+	}
+
+	// Claim the token atomically so two concurrent refreshes cannot both succeed
+	result, err := s.client.Session.FindMany(
+		db.Session.ID.Equals(session.ID),
+		db.Session.RotatedAt.IsNull(),
+	).Update(
+		db.Session.RotatedAt.Set(time.Now()),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate session: %w", err)
+	}
+
+	if result.Count == 0 {
+		if err := s.revokeFamily(ctx, session.FamilyID); err != nil {
+			return nil, err
+		}
+		s.activities.Record(ctx, session.UserID, ActivityEvent{
+			Type:        db.ActivityTypeRefreshTokenReused,
+			Description: "Refresh token reused, session revoked",
+			TargetType:  models.ActivityTargetUser,
+			TargetID:    session.UserID,
+		})
+		return nil, ErrRefreshTokenReused
+	}
+
+	family := sessionFamily{id: session.FamilyID, startedAt: session.StartedAt}
+	if impersonatorID, ok := session.ImpersonatorID(); ok {
+		family.expiresAt = session.ExpiresAt
+		family.impersonatorID = impersonatorID
+	}
+
+	return s.issue(ctx, session.UserID, session.User().Email, family, client)
+}
+
+// Revoke ends the session family the given access token session belongs to
+func (s *SessionService) Revoke(ctx context.Context, userID string, sessionID string) error {
+	session, err := s.findOwnedSession(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	return s.revokeFamily(ctx, session.FamilyID)
+}
+
+// RevokeAll ends every session of the user
+func (s *SessionService) RevokeAll(ctx context.Context, userID string) error {
+	_, err := s.client.Session.FindMany(
+		db.Session.UserID.Equals(userID),
+		db.Session.RevokedAt.IsNull(),
+	).Update(
+		db.Session.RevokedAt.Set(time.Now()),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return nil
+}
+
+// ListSessions returns the user's active sessions, most recently used first.
+// Sessions are identified by their family so the ID survives token rotation.
+func (s *SessionService) ListSessions(ctx context.Context, userID string, currentSessionID string) ([]models.SessionResponse, error) {
+	current, err := s.findOwnedSession(ctx, userID, currentSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.client.Session.FindMany(
+		db.Session.UserID.Equals(userID),
+		db.Session.RotatedAt.IsNull(),
+		db.Session.RevokedAt.IsNull(),
+		db.Session.ExpiresAt.After(time.Now()),
+	).OrderBy(
+		db.Session.LastSeenAt.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+
+	response := []models.SessionResponse{}
+	for i := range sessions {
+		session := toSessionResponse(&sessions[i])
+		session.Current = sessions[i].FamilyID == current.FamilyID
+		response = append(response, session)
+	}
+
+	return response, nil
+}
+
+// RevokeSession ends one of the user's sessions by its public (family) ID
+func (s *SessionService) RevokeSession(ctx context.Context, userID string, familyID string) error {
+	_, err := s.client.Session.FindFirst(
+		db.Session.FamilyID.Equals(familyID),
+		db.Session.UserID.Equals(userID),
+		db.Session.RevokedAt.IsNull(),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("failed to fetch session: %w", err)
+	}
+
+	if err := s.revokeFamily(ctx, familyID); err != nil {
+		return err
+	}
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeSessionRevoked,
+		Description: "Revoked a session",
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    userID,
+		Metadata: map[string]interface{}{
+			"session_id": familyID,
+		},
+	})
+
+	return nil
+}
+
+// RevokeOtherSessions ends every session of the user except the current one
+func (s *SessionService) RevokeOtherSessions(ctx context.Context, userID string, currentSessionID string) (*models.RevokeSessionsResponse, error) {
+	current, err := s.findOwnedSession(ctx, userID, currentSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := s.client.Session.FindMany(
+		db.Session.UserID.Equals(userID),
+		db.Session.RotatedAt.IsNull(),
+		db.Session.RevokedAt.IsNull(),
+		db.Session.ExpiresAt.After(time.Now()),
+		db.Session.Not(db.Session.FamilyID.Equals(current.FamilyID)),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+
+	_, err = s.client.Session.FindMany(
+		db.Session.UserID.Equals(userID),
+		db.Session.RevokedAt.IsNull(),
+		db.Session.Not(db.Session.FamilyID.Equals(current.FamilyID)),
+	).Update(
+		db.Session.RevokedAt.Set(time.Now()),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	if len(active) > 0 {
+		s.activities.Record(ctx, userID, ActivityEvent{
+			Type:        db.ActivityTypeSessionRevoked,
+			Description: fmt.Sprintf("Logged out of %d other sessions", len(active)),
+			TargetType:  models.ActivityTargetUser,
+			TargetID:    userID,
+			Metadata: map[string]interface{}{
+				"revoked": len(active),
+			},
+		})
+	}
+
+	return &models.RevokeSessionsResponse{Revoked: len(active)}, nil
+}
+
+// ValidateSession checks that an access token's session is still active. For
+// an impersonation session it returns the ID of the admin acting as the user.
+func (s *SessionService) ValidateSession(ctx context.Context, userID string, sessionID string) (string, error) {
+	session, err := s.client.Session.FindFirst(
+		db.Session.ID.Equals(sessionID),
+		db.Session.UserID.Equals(userID),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return "", ErrSessionRevoked
+		}
+		return "", fmt.Errorf("failed to fetch session: %w", err)
+	}
+
+	if _, revoked := session.RevokedAt(); revoked {
+		return "", ErrSessionRevoked
+	}
+
+	// Track activity on the family's live token without writing on every request
+	_, err = s.client.Session.FindMany(
+		db.Session.FamilyID.Equals(session.FamilyID),
+		db.Session.RotatedAt.IsNull(),
+		db.Session.LastSeenAt.Before(time.Now().Add(-lastSeenInterval)),
+	).Update(
+		db.Session.LastSeenAt.Set(time.Now()),
+	).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to update last seen for session %s: %v\n", session.FamilyID, err)
+	}
+
+	impersonatorID, _ := session.ImpersonatorID()
+	return impersonatorID, nil
+}
+
+// issue stores a new refresh token in the family and signs an access token for it
+func (s *SessionService) issue(ctx context.Context, userID string, email string, family sessionFamily, client models.ClientInfo) (*models.TokenResponse, error) {
+	refreshToken, tokenHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	expiresAt := family.expiresAt
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(utils.RefreshTokenExpiry())
+	}
+
+	session, err := s.client.Session.CreateOne(
+		db.Session.FamilyID.Set(family.id),
+		db.Session.TokenHash.Set(tokenHash),
+		db.Session.ExpiresAt.Set(expiresAt),
+		db.Session.User.Link(db.User.ID.Equals(userID)),
+		db.Session.StartedAt.Set(family.startedAt),
+		db.Session.UserAgent.SetIfPresent(optionalString(client.UserAgent)),
+		db.Session.IPAddress.SetIfPresent(optionalString(client.IPAddress)),
+		db.Session.ImpersonatorID.SetIfPresent(optionalString(family.impersonatorID)),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	token, err := utils.GenerateToken(userID, email, session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return &models.TokenResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresAt:    time.Now().Add(utils.AccessTokenExpiry()),
+	}, nil
+}
+
+// findOwnedSession fetches a session row and verifies it belongs to the user
+func (s *SessionService) findOwnedSession(ctx context.Context, userID string, sessionID string) (*db.SessionModel, error) {
+	session, err := s.client.Session.FindFirst(
+		db.Session.ID.Equals(sessionID),
+		db.Session.UserID.Equals(userID),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch session: %w", err)
+	}
+
+	return session, nil
+}
+
+// revokeFamily revokes every refresh token issued for one login
+func (s *SessionService) revokeFamily(ctx context.Context, familyID string) error {
+	_, err := s.client.Session.FindMany(
+		db.Session.FamilyID.Equals(familyID),
+		db.Session.RevokedAt.IsNull(),
+	).Update(
+		db.Session.RevokedAt.Set(time.Now()),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return nil
+}
+
+func toSessionResponse(s *db.SessionModel) models.SessionResponse {
+	response := models.SessionResponse{
+		ID:         s.FamilyID,
+		CreatedAt:  s.StartedAt,
+		LastSeenAt: s.LastSeenAt,
+		ExpiresAt:  s.ExpiresAt,
+	}
+	if v, ok := s.UserAgent(); ok {
+		response.UserAgent = v
+	}
+	if v, ok := s.IPAddress(); ok {
+		response.IPAddress = v
+	}
+	if _, ok := s.ImpersonatorID(); ok {
+		response.Impersonated = true
+	}
+
+	return response
+}
+
+// optionalString returns nil for an empty string so optional columns stay NULL
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/gomail.v2"
+)
+
+// SystemMailer sends account emails (password resets, verification links)
+// from the application's own mailbox rather than the user's
+type SystemMailer struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+}
+
+// NewSystemMailer creates a system mailer configured from the SYSTEM_SMTP_* environment variables
+func NewSystemMailer() *SystemMailer {
+	port, err := strconv.Atoi(os.Getenv("SYSTEM_SMTP_PORT"))
+	if err != nil || port == 0 {
+		port = 587
+	}
+
+	from := os.Getenv("SYSTEM_MAIL_FROM")
+	if from == "" {
+		from = os.Getenv("SYSTEM_SMTP_USERNAME")
+	}
+
+	return &SystemMailer{
+		host:     os.Getenv("SYSTEM_SMTP_HOST"),
+		port:     port,
+		username: os.Getenv("SYSTEM_SMTP_USERNAME"),
+		password: os.Getenv("SYSTEM_SMTP_PASSWORD"),
+		from:     from,
+	}
+}
+
+// Send delivers a plain text email. Without SMTP configuration the message is
+// printed instead, which keeps the flows usable in local development.
+func (m *SystemMailer) Send(to string, subject string, body string) error {
+	if m.host == "" {
+		fmt.Printf("System mailer not configured, email to %s:\nSubject: %s\n%s\n", to, subject, body)
+		return nil
+	}
+
+	msg := gomail.NewMessage()
+	msg.SetHeader("From", m.from)
+	msg.SetHeader("To", to)
+	msg.SetHeader("Subject", subject)
+	msg.SetBody("text/plain", body)
+
+	dialer := gomail.NewDialer(m.host, m.port, m.username, m.password)
+	if err := dialer.DialAndSend(msg); err != nil {
+		return fmt.Errorf("failed to send system email: %w", err)
+	}
+
+	return nil
+}
+
+// appURL builds a link into the frontend application
+func appURL(path string) string {
+	base := strings.TrimRight(os.Getenv("APP_URL"), "/")
+	if base == "" {
+		base = "http://localhost:5173"
+	}
+	return base + path
+}
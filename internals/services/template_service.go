@@ -2,75 +2,157 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/satyam-svg/hr-message-backend/internals/models"
 	"github.com/satyam-svg/hr-message-backend/prisma/db"
 )
 
+// ErrTemplateNotFound is returned when the organization has no template outside the trash
+var ErrTemplateNotFound = errors.New("template not found")
+
 // TemplateService handles template business logic
 type TemplateService struct {
-	client *db.PrismaClient
+	client     *db.PrismaClient
+	activities *ActivityService
 }
 
 // NewTemplateService creates a new template service
 func NewTemplateService(client *db.PrismaClient) *TemplateService {
 	return &TemplateService{
-		client: client,
+		client:     client,
+		activities: NewActivityService(client),
 	}
 }
 
-// GetTemplate retrieves the user's template
-func (s *TemplateService) GetTemplate(ctx context.Context, userID string) (*models.TemplateResponse, error) {
-	template, err := s.client.Template.FindUnique(
-		db.Template.UserID.Equals(userID),
-	).Exec(ctx)
+// GetTemplate retrieves the organization's active template
+func (s *TemplateService) GetTemplate(ctx context.Context, userID string, organizationID string) (*models.TemplateResponse, error) {
+	membership, err := resolveMembership(ctx, s.client, userID, organizationID)
+	if err != nil {
+		return nil, err
+	}
 
+	template, err := activeTemplate(ctx, s.client, membership.OrganizationID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch template: %w", err)
+		return nil, err
 	}
 
-	return &models.TemplateResponse{
-		ID:        template.ID,
-		Name:      template.Name,
-		Subject:   template.Subject,
-		Body:      template.Body,
-		CreatedAt: template.CreatedAt,
-		UpdatedAt: template.UpdatedAt,
-	}, nil
+	response := toTemplateResponse(template)
+	return &response, nil
 }
 
-// UpdateTemplate updates the user's template
-func (s *TemplateService) UpdateTemplate(ctx context.Context, userID string, req models.UpdateTemplateRequest) (*models.TemplateResponse, error) {
+// UpdateTemplate updates the organization's active template. Every member can edit it.
+func (s *TemplateService) UpdateTemplate(ctx context.Context, userID string, organizationID string, req models.UpdateTemplateRequest) (*models.TemplateResponse, error) {
+	membership, err := resolveMembership(ctx, s.client, userID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Try to find existing template
-	template, err := s.client.Template.FindUnique(
-		db.Template.UserID.Equals(userID),
-	).Update(
-		db.Template.Name.Set(req.Name),
-		db.Template.Subject.Set(req.Subject),
-		db.Template.Body.Set(req.Body),
-	).Exec(ctx)
+	template, err := activeTemplate(ctx, s.client, membership.OrganizationID)
+	switch {
+	case err == nil:
+		template, err = s.client.Template.FindUnique(
+			db.Template.ID.Equals(template.ID),
+		).Update(
+			db.Template.Name.Set(req.Name),
+			db.Template.Subject.Set(req.Subject),
+			db.Template.Body.Set(req.Body),
+		).Exec(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update template: %w", err)
+		}
 
-	// If template doesn't exist (e.g. old user), create one
-	if err != nil {
+	case errors.Is(err, ErrTemplateNotFound):
+		// No template yet (e.g. old user or the previous one was deleted), so create one
 		template, err = s.client.Template.CreateOne(
 			db.Template.Name.Set(req.Name),
 			db.Template.Subject.Set(req.Subject),
 			db.Template.Body.Set(req.Body),
 			db.Template.User.Link(db.User.ID.Equals(userID)),
+			db.Template.Organization.Link(db.Organization.ID.Equals(membership.OrganizationID)),
 		).Exec(ctx)
-
 		if err != nil {
-			return nil, fmt.Errorf("failed to update/create template: %w", err)
+			return nil, fmt.Errorf("failed to create template: %w", err)
+		}
+
+	default:
+		return nil, err
+	}
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeTemplateUpdated,
+		Description: "Updated template \"" + template.Name + "\"",
+		TargetType:  models.ActivityTargetTemplate,
+		TargetID:    template.ID,
+	})
+
+	response := toTemplateResponse(template)
+	return &response, nil
+}
+
+// DeleteTemplate moves the organization's active template to the trash.
+// Members can only delete a template they created.
+func (s *TemplateService) DeleteTemplate(ctx context.Context, userID string, organizationID string) error {
+	membership, err := resolveMembership(ctx, s.client, userID, organizationID)
+	if err != nil {
+		return err
+	}
+
+	template, err := activeTemplate(ctx, s.client, membership.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if !canModerate(membership, template.UserID) {
+		return ErrInsufficientRole
+	}
+
+	_, err = s.client.Template.FindUnique(
+		db.Template.ID.Equals(template.ID),
+	).Update(
+		db.Template.DeletedAt.Set(time.Now()),
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeTemplateDeleted,
+		Description: "Moved template \"" + template.Name + "\" to trash",
+		TargetType:  models.ActivityTargetTemplate,
+		TargetID:    template.ID,
+	})
+
+	return nil
+}
+
+// activeTemplate returns the organization's most recently updated template that is not in the trash
+func activeTemplate(ctx context.Context, client *db.PrismaClient, organizationID string) (*db.TemplateModel, error) {
+	template, err := client.Template.FindFirst(
+		db.Template.OrganizationID.Equals(organizationID),
+		db.Template.DeletedAt.IsNull(),
+	).OrderBy(
+		db.Template.UpdatedAt.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrTemplateNotFound
 		}
+		return nil, fmt.Errorf("failed to fetch template: %w", err)
 	}
 
-	return &models.TemplateResponse{
-		ID:        template.ID,
-		Name:      template.Name,
-		Subject:   template.Subject,
-		Body:      template.Body,
-		CreatedAt: template.CreatedAt,
-		UpdatedAt: template.UpdatedAt,
-	}, nil
+	return template, nil
+}
+
+func toTemplateResponse(t *db.TemplateModel) models.TemplateResponse {
+	return models.TemplateResponse{
+		ID:        t.ID,
+		Name:      t.Name,
+		Subject:   t.Subject,
+		Body:      t.Body,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
 }
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"strings"
+
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+)
+
+// emailThread is what a follow-up needs to reply to the earlier emails of a
+// campaign to one contact
+type emailThread struct {
+	// subject of the last email
+	subject string
+	// messageIDs of the earlier emails, oldest first
+	messageIDs []string
+}
+
+// newMessageID returns a unique Message-ID in the sender's domain, without angle brackets
+func newMessageID(senderEmail string) string {
+	domain := "localhost"
+	if _, d, ok := strings.Cut(senderEmail, "@"); ok && d != "" {
+		domain = d
+	}
+
+	return strings.ToLower(rand.Text()) + "@" + domain
+}
+
+// formatMessageIDs formats Message-IDs for the In-Reply-To and References headers
+func formatMessageIDs(ids []string) string {
+	formatted := make([]string, 0, len(ids))
+	for _, id := range ids {
+		formatted = append(formatted, "<"+id+">")
+	}
+
+	return strings.Join(formatted, " ")
+}
+
+// campaignThread returns the emails the campaign sent to a contact. Without
+// any, the subject is the template's.
+func (s *EmailService) campaignThread(ctx context.Context, campaign *db.CampaignModel, contactId string) emailThread {
+	thread := emailThread{}
+	logs, err := s.client.EmailLog.FindMany(
+		db.EmailLog.CampaignID.Equals(campaign.ID),
+		db.EmailLog.ContactID.Equals(contactId),
+		db.EmailLog.Status.Equals(db.EmailStatusSent),
+	).OrderBy(
+		db.EmailLog.CreatedAt.Order(db.SortOrderAsc),
+	).Exec(ctx)
+	if err != nil {
+		fmt.Printf("Failed to fetch emails of campaign %s to %s: %v\n", campaign.ID, contactId, err)
+	}
+
+	for _, log := range logs {
+		thread.subject = log.Subject
+		if id, ok := log.MessageID(); ok {
+			thread.messageIDs = append(thread.messageIDs, id)
+		}
+	}
+	if thread.subject == "" {
+		if template, err := activeTemplate(ctx, s.client, campaign.OrganizationID); err == nil {
+			thread.subject = template.Subject
+		}
+	}
+
+	return thread
+}
+
+// replySubject prefixes a subject with "Re: " unless it already has it
+func replySubject(subject string) string {
+	if strings.HasPrefix(strings.ToLower(subject), "re:") {
+		return subject
+	}
+
+	return "Re: " + subject
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+	"github.com/satyam-svg/hr-message-backend/prisma/db"
+)
+
+// defaultTrashRetentionDays is how long deleted items are kept when TRASH_RETENTION_DAYS is not set
+const defaultTrashRetentionDays = 30
+
+// ErrTrashItemNotFound is returned when an item is not in the trash of one of the user's organizations
+var ErrTrashItemNotFound = errors.New("item not found in trash")
+
+// TrashService handles listing, restoring and purging soft-deleted contacts and templates
+type TrashService struct {
+	client     *db.PrismaClient
+	activities *ActivityService
+	retention  time.Duration
+}
+
+// NewTrashService creates a new trash service
+func NewTrashService(client *db.PrismaClient) *TrashService {
+	days := defaultTrashRetentionDays
+	if v, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS")); err == nil && v > 0 {
+		days = v
+	}
+
+	return &TrashService{
+		client:     client,
+		activities: NewActivityService(client),
+		retention:  time.Duration(days) * 24 * time.Hour,
+	}
+}
+
+// ListTrash returns the organization's deleted contacts and templates, most recently deleted first
+func (s *TrashService) ListTrash(ctx context.Context, userID string, organizationID string) (*models.TrashResponse, error) {
+	membership, err := resolveMembership(ctx, s.client, userID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	contacts, err := s.client.Contact.FindMany(
+		db.Contact.OrganizationID.Equals(membership.OrganizationID),
+		db.Contact.Not(db.Contact.DeletedAt.IsNull()),
+	).OrderBy(
+		db.Contact.DeletedAt.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch deleted contacts: %w", err)
+	}
+
+	templates, err := s.client.Template.FindMany(
+		db.Template.OrganizationID.Equals(membership.OrganizationID),
+		db.Template.Not(db.Template.DeletedAt.IsNull()),
+	).OrderBy(
+		db.Template.DeletedAt.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch deleted templates: %w", err)
+	}
+
+	response := &models.TrashResponse{
+		Contacts:      []models.TrashedContact{},
+		Templates:     []models.TrashedTemplate{},
+		RetentionDays: int(s.retention / (24 * time.Hour)),
+	}
+	for i := range contacts {
+		deletedAt, _ := contacts[i].DeletedAt()
+		response.Contacts = append(response.Contacts, models.TrashedContact{
+			ContactResponse: toContactResponse(&contacts[i]),
+			DeletedAt:       deletedAt,
+			PurgeAt:         deletedAt.Add(s.retention),
+		})
+	}
+	for i := range templates {
+		deletedAt, _ := templates[i].DeletedAt()
+		response.Templates = append(response.Templates, models.TrashedTemplate{
+			TemplateResponse: toTemplateResponse(&templates[i]),
+			DeletedAt:        deletedAt,
+			PurgeAt:          deletedAt.Add(s.retention),
+		})
+	}
+
+	return response, nil
+}
+
+// RestoreContact takes a contact out of the trash. Like deleting, members can
+// only restore contacts they created.
+func (s *TrashService) RestoreContact(ctx context.Context, userID string, contactID string) (*models.ContactResponse, error) {
+	contact, err := s.client.Contact.FindFirst(
+		db.Contact.ID.Equals(contactID),
+		contactAccessibleBy(userID),
+		db.Contact.Not(db.Contact.DeletedAt.IsNull()),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrTrashItemNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch contact: %w", err)
+	}
+
+	membership, err := findMembership(ctx, s.client, contact.OrganizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canModerate(membership, contact.UserID) {
+		return nil, ErrInsufficientRole
+	}
+
+	restored, err := s.client.Contact.FindUnique(
+		db.Contact.ID.Equals(contact.ID),
+	).Update(
+		db.Contact.DeletedAt.SetOptional(nil),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore contact: %w", err)
+	}
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeContactRestored,
+		Description: "Restored contact " + restored.Name + " from trash",
+		TargetType:  models.ActivityTargetContact,
+		TargetID:    restored.ID,
+	})
+
+	response := toContactResponse(restored)
+	return &response, nil
+}
+
+// RestoreTemplate takes a template out of the trash. The restored template
+// becomes the organization's active template.
+func (s *TrashService) RestoreTemplate(ctx context.Context, userID string, templateID string) (*models.TemplateResponse, error) {
+	template, err := s.client.Template.FindFirst(
+		db.Template.ID.Equals(templateID),
+		db.Template.Organization.Where(memberOf(userID)),
+		db.Template.Not(db.Template.DeletedAt.IsNull()),
+	).Exec(ctx)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrTrashItemNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch template: %w", err)
+	}
+
+	membership, err := findMembership(ctx, s.client, template.OrganizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canModerate(membership, template.UserID) {
+		return nil, ErrInsufficientRole
+	}
+
+	restored, err := s.client.Template.FindUnique(
+		db.Template.ID.Equals(template.ID),
+	).Update(
+		db.Template.DeletedAt.SetOptional(nil),
+	).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore template: %w", err)
+	}
+
+	s.activities.Record(ctx, userID, ActivityEvent{
+		Type:        db.ActivityTypeTemplateRestored,
+		Description: "Restored template \"" + restored.Name + "\" from trash",
+		TargetType:  models.ActivityTargetTemplate,
+		TargetID:    restored.ID,
+	})
+
+	response := toTemplateResponse(restored)
+	return &response, nil
+}
+
+// PurgeExpired permanently deletes contacts and templates that have been in
+// the trash for longer than the retention period
+func (s *TrashService) PurgeExpired(ctx context.Context) error {
+	cutoff := time.Now().Add(-s.retention)
+
+	contacts, err := s.client.Contact.FindMany(
+		db.Contact.DeletedAt.Lt(cutoff),
+	).Delete().Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to purge contacts: %w", err)
+	}
+
+	templates, err := s.client.Template.FindMany(
+		db.Template.DeletedAt.Lt(cutoff),
+	).Delete().Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to purge templates: %w", err)
+	}
+
+	if contacts.Count > 0 || templates.Count > 0 {
+		fmt.Printf("Purged %d contacts and %d templates from trash\n", contacts.Count, templates.Count)
+	}
+
+	return nil
+}
+
+// RunPurgeLoop calls PurgeExpired immediately and then on every interval until ctx is cancelled
+func (s *TrashService) RunPurgeLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.PurgeExpired(ctx); err != nil {
+			fmt.Printf("Failed to purge trash: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
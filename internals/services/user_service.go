@@ -3,24 +3,25 @@ package services
 import (
 	"context"
 	"fmt"
-	"time"
 
+	"github.com/satyam-svg/hr-message-backend/internals/models"
 	"github.com/satyam-svg/hr-message-backend/prisma/db"
 )
 
 type UserService struct {
-	client *db.PrismaClient
+	client     *db.PrismaClient
+	activities *ActivityService
 }
 
 func NewUserService(client *db.PrismaClient) *UserService {
 	return &UserService{
-		client: client,
+		client:     client,
+		activities: NewActivityService(client),
 	}
 }
 
 // IncrementPDFUploadCount increments the pdfUploadCount for a user and logs an activity
-func (s *UserService) IncrementPDFUploadCount(userId string) error {
-	ctx := context.Background()
+func (s *UserService) IncrementPDFUploadCount(ctx context.Context, userId string, contactsSaved int) error {
 	// Increment count and create activity sequentially
 
 	// 1. Increment count
@@ -34,15 +35,15 @@ func (s *UserService) IncrementPDFUploadCount(userId string) error {
 	}
 
 	// 2. Create Activity
-	_, err = s.client.Activity.CreateOne(
-		db.Activity.Description.Set("Uploaded a PDF"),
-		db.Activity.User.Link(db.User.ID.Equals(userId)),
-		db.Activity.CreatedAt.Set(time.Now()),
-	).Exec(ctx)
-
-	if err != nil {
-		return fmt.Errorf("failed to create activity log: %w", err)
-	}
+	s.activities.Record(ctx, userId, ActivityEvent{
+		Type:        db.ActivityTypePdfUploaded,
+		Description: "Uploaded a PDF",
+		TargetType:  models.ActivityTargetUser,
+		TargetID:    userId,
+		Metadata: map[string]interface{}{
+			"contacts_saved": contactsSaved,
+		},
+	})
 
 	return nil
 }
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// freeMailDomains are personal mailbox providers whose domain says nothing about the employer
+var freeMailDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+	"yahoo.com":      true,
+	"yahoo.co.in":    true,
+	"yahoo.co.uk":    true,
+	"ymail.com":      true,
+	"outlook.com":    true,
+	"hotmail.com":    true,
+	"live.com":       true,
+	"msn.com":        true,
+	"icloud.com":     true,
+	"me.com":         true,
+	"mac.com":        true,
+	"aol.com":        true,
+	"protonmail.com": true,
+	"proton.me":      true,
+	"pm.me":          true,
+	"zoho.com":       true,
+	"zohomail.com":   true,
+	"yandex.com":     true,
+	"gmx.com":        true,
+	"mail.com":       true,
+	"rediffmail.com": true,
+	"fastmail.com":   true,
+	"hey.com":        true,
+	"qq.com":         true,
+}
+
+// legalSuffixes are dropped when normalizing a company name so that
+// "Google", "Google LLC" and "google inc." resolve to the same company
+var legalSuffixes = map[string]bool{
+	"inc":          true,
+	"incorporated": true,
+	"llc":          true,
+	"llp":          true,
+	"ltd":          true,
+	"limited":      true,
+	"pvt":          true,
+	"private":      true,
+	"corp":         true,
+	"corporation":  true,
+	"co":           true,
+	"company":      true,
+	"plc":          true,
+	"gmbh":         true,
+	"ag":           true,
+	"sa":           true,
+	"bv":           true,
+	"pte":          true,
+	"pty":          true,
+}
+
+// twoLevelSuffixes are public suffixes with two labels, e.g. "co.uk"
+var twoLevelSuffixes = map[string]bool{
+	"co.uk":  true,
+	"co.in":  true,
+	"co.jp":  true,
+	"co.nz":  true,
+	"co.za":  true,
+	"com.au": true,
+	"com.br": true,
+	"com.sg": true,
+	"com.cn": true,
+	"net.in": true,
+	"org.in": true,
+	"org.uk": true,
+	"ac.in":  true,
+	"ac.uk":  true,
+}
+
+// NormalizeCompanyName lowercases a company name, strips punctuation and
+// trailing legal suffixes such as "LLC" or "Pvt Ltd"
+func NormalizeCompanyName(name string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		if r == '&' {
+			return r
+		}
+		return ' '
+	}, name)
+
+	words := strings.Fields(cleaned)
+	for len(words) > 1 && legalSuffixes[words[len(words)-1]] {
+		words = words[:len(words)-1]
+	}
+
+	return strings.Join(words, " ")
+}
+
+// CompanyDomainFromEmail returns the organisation domain of an email address,
+// or an empty string for personal mailbox providers and malformed addresses
+func CompanyDomainFromEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 || at == len(email)-1 {
+		return ""
+	}
+
+	domain := RegistrableDomain(email[at+1:])
+	if domain == "" || freeMailDomains[domain] {
+		return ""
+	}
+
+	return domain
+}
+
+// RegistrableDomain reduces a host name like "careers.google.com" or
+// "www.example.co.uk" to the domain an organisation registers
+func RegistrableDomain(host string) string {
+	host = strings.Trim(strings.ToLower(strings.TrimSpace(host)), ".")
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 {
+		return ""
+	}
+
+	keep := 2
+	if len(labels) >= 3 && twoLevelSuffixes[strings.Join(labels[len(labels)-2:], ".")] {
+		keep = 3
+	}
+
+	return strings.Join(labels[len(labels)-keep:], ".")
+}
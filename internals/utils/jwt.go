@@ -2,46 +2,59 @@ package utils
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// defaultAccessTokenExpiry is used when JWT_EXPIRY is not set or invalid
+const defaultAccessTokenExpiry = 15 * time.Minute
+
 // Claims represents JWT claims
 type Claims struct {
-	UserID string `json:"userId"`
-	Email  string `json:"email"`
+	UserID    string `json:"userId"`
+	Email     string `json:"email"`
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken generates a JWT token for a user
-func GenerateToken(userID, email string) (string, error) {
-	// Get JWT secret from environment
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "your-secret-key-change-in-production" // Fallback for development
+// AccessTokenExpiry returns how long access tokens are valid
+func AccessTokenExpiry() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("JWT_EXPIRY")); err == nil && d > 0 {
+		return d
 	}
+	return defaultAccessTokenExpiry
+}
 
-	// Get expiry duration from environment or use default (24 hours)
-	expiryHours := 24
+// GenerateToken generates a short-lived JWT access token bound to a session.
+// It is signed with the active signing key, named in the "kid" header.
+func GenerateToken(userID, email, sessionID string) (string, error) {
+	ring, err := currentSigningKeys()
+	if err != nil {
+		return "", err
+	}
+	key := ring.keys[ring.activeID]
 
 	// Create claims
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
+		UserID:    userID,
+		Email:     email,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * time.Duration(expiryHours))),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenExpiry())),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
 	// Create token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
 
 	// Sign token
-	tokenString, err := token.SignedString([]byte(secret))
+	tokenString, err := token.SignedString(key.signer)
 	if err != nil {
 		return "", err
 	}
@@ -49,21 +62,27 @@ func GenerateToken(userID, email string) (string, error) {
 	return tokenString, nil
 }
 
-// ValidateToken validates a JWT token and returns claims
+// ValidateToken validates a JWT token and returns claims. Tokens signed with
+// any configured key are accepted so rotating the active key does not log
+// anyone out.
 func ValidateToken(tokenString string) (*Claims, error) {
-	// Get JWT secret from environment
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "your-secret-key-change-in-production" // Fallback for development
+	ring, err := currentSigningKeys()
+	if err != nil {
+		return nil, err
 	}
 
 	// Parse token
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := ring.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
 		// Validate signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		if token.Method.Alg() != key.method.Alg() {
 			return nil, errors.New("invalid signing method")
 		}
-		return []byte(secret), nil
+		return key.signer.Public(), nil
 	})
 
 	if err != nil {
@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// secretVersion prefixes values produced by EncryptSecret
+const secretVersion = "v1"
+
+// ErrEncryptionNotConfigured is returned when no key encryption key is configured
+var ErrEncryptionNotConfigured = errors.New("encryption keys are not configured")
+
+// keyring holds the key encryption keys loaded from configuration
+type keyring struct {
+	activeID string
+	keys     map[string][]byte
+}
+
+// loadKeyring reads ENCRYPTION_KEYS ("id:base64key,id:base64key") and
+// ENCRYPTION_ACTIVE_KEY_ID. Without an active ID the first key is used for
+// new values; the others stay available for decryption during rotation.
+func loadKeyring() (*keyring, error) {
+	raw := strings.TrimSpace(os.Getenv("ENCRYPTION_KEYS"))
+	if raw == "" {
+		return nil, ErrEncryptionNotConfigured
+	}
+
+	ring := &keyring{keys: map[string][]byte{}}
+	for _, entry := range strings.Split(raw, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid ENCRYPTION_KEYS entry %q", entry)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("encryption key %q must be 32 bytes, base64 encoded", id)
+		}
+		ring.keys[id] = key
+		if ring.activeID == "" {
+			ring.activeID = id
+		}
+	}
+
+	if active := os.Getenv("ENCRYPTION_ACTIVE_KEY_ID"); active != "" {
+		if _, ok := ring.keys[active]; !ok {
+			return nil, fmt.Errorf("active encryption key %q is not in ENCRYPTION_KEYS", active)
+		}
+		ring.activeID = active
+	}
+
+	return ring, nil
+}
+
+// EncryptSecret encrypts a value with a fresh data key (AES-256-GCM) and wraps
+// the data key with the active key encryption key. The result has the form
+// "v1:<key id>:<wrapped data key>:<ciphertext>".
+func EncryptSecret(plaintext string) (string, error) {
+	ring, err := loadKeyring()
+	if err != nil {
+		return "", err
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+
+	ciphertext, err := sealGCM(dataKey, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	wrappedKey, err := sealGCM(ring.keys[ring.activeID], dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join([]string{
+		secretVersion,
+		ring.activeID,
+		base64.StdEncoding.EncodeToString(wrappedKey),
+		base64.StdEncoding.EncodeToString(ciphertext),
+	}, ":"), nil
+}
+
+// DecryptSecret reverses EncryptSecret. Values stored before encryption was
+// introduced are returned unchanged until they are re-encrypted.
+func DecryptSecret(stored string) (string, error) {
+	if !IsEncryptedSecret(stored) {
+		return stored, nil
+	}
+
+	parts := strings.Split(stored, ":")
+	ring, err := loadKeyring()
+	if err != nil {
+		return "", err
+	}
+	kek, ok := ring.keys[parts[1]]
+	if !ok {
+		return "", fmt.Errorf("encryption key %q is not configured", parts[1])
+	}
+
+	wrappedKey, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("invalid wrapped key: %w", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return "", fmt.Errorf("invalid ciphertext: %w", err)
+	}
+
+	dataKey, err := openGCM(kek, wrappedKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	plaintext, err := openGCM(dataKey, ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+
+	return string(plaintext), nil
+}
+
+// IsEncryptedSecret reports whether a stored value was produced by EncryptSecret
+func IsEncryptedSecret(stored string) bool {
+	parts := strings.Split(stored, ":")
+	return len(parts) == 4 && parts[0] == secretVersion
+}
+
+// NeedsReencryption reports whether a stored value is plaintext or was
+// encrypted with a key other than the active one
+func NeedsReencryption(stored string) (bool, error) {
+	if !IsEncryptedSecret(stored) {
+		return true, nil
+	}
+
+	ring, err := loadKeyring()
+	if err != nil {
+		return false, err
+	}
+
+	return strings.Split(stored, ":")[1] != ring.activeID, nil
+}
+
+// sealGCM encrypts with AES-GCM and prepends the random nonce
+func sealGCM(key []byte, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// openGCM decrypts a value produced by sealGCM
+func openGCM(key []byte, sealed []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,217 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/satyam-svg/hr-message-backend/internals/models"
+)
+
+// minRSAKeyBits is the smallest RSA key accepted for signing tokens
+const minRSAKeyBits = 2048
+
+// ErrSigningKeysNotConfigured is returned in production when JWT_SIGNING_KEYS is empty
+var ErrSigningKeysNotConfigured = errors.New("JWT signing keys are not configured")
+
+// signingKey is one asymmetric key access tokens are signed or verified with
+type signingKey struct {
+	id     string
+	method jwt.SigningMethod
+	signer crypto.Signer
+}
+
+// signingKeyring holds the keys loaded from configuration. Every key is
+// published and accepted for verification; only the active one signs.
+type signingKeyring struct {
+	activeID string
+	keys     map[string]*signingKey
+	order    []string
+}
+
+var (
+	signingKeysMu sync.Mutex
+	signingKeys   *signingKeyring
+)
+
+// IsProduction reports whether APP_ENV is set to production
+func IsProduction() bool {
+	return strings.EqualFold(strings.TrimSpace(os.Getenv("APP_ENV")), "production")
+}
+
+// LoadSigningKeys reads JWT_SIGNING_KEYS ("id:base64 PEM,id:base64 PEM") and
+// JWT_ACTIVE_KEY_ID. Without an active ID the first key signs new tokens; the
+// others are still accepted so tokens signed before a rotation stay valid.
+// Outside production a temporary Ed25519 key is generated when none is
+// configured; in production that is an error.
+func LoadSigningKeys() error {
+	ring, err := loadSigningKeyring()
+	if err != nil {
+		return err
+	}
+
+	signingKeysMu.Lock()
+	signingKeys = ring
+	signingKeysMu.Unlock()
+
+	return nil
+}
+
+// currentSigningKeys returns the loaded keyring, loading it on first use
+func currentSigningKeys() (*signingKeyring, error) {
+	signingKeysMu.Lock()
+	ring := signingKeys
+	signingKeysMu.Unlock()
+	if ring != nil {
+		return ring, nil
+	}
+
+	if err := LoadSigningKeys(); err != nil {
+		return nil, err
+	}
+
+	signingKeysMu.Lock()
+	defer signingKeysMu.Unlock()
+	return signingKeys, nil
+}
+
+func loadSigningKeyring() (*signingKeyring, error) {
+	raw := strings.TrimSpace(os.Getenv("JWT_SIGNING_KEYS"))
+	if raw == "" {
+		if IsProduction() {
+			return nil, ErrSigningKeysNotConfigured
+		}
+		return temporarySigningKeyring()
+	}
+
+	ring := &signingKeyring{keys: map[string]*signingKey{}}
+	for _, entry := range strings.Split(raw, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid JWT_SIGNING_KEYS entry %q", entry)
+		}
+		if _, exists := ring.keys[id]; exists {
+			return nil, fmt.Errorf("duplicate signing key id %q", id)
+		}
+
+		key, err := parseSigningKey(id, encoded)
+		if err != nil {
+			return nil, err
+		}
+		ring.keys[id] = key
+		ring.order = append(ring.order, id)
+		if ring.activeID == "" {
+			ring.activeID = id
+		}
+	}
+
+	if active := os.Getenv("JWT_ACTIVE_KEY_ID"); active != "" {
+		if _, ok := ring.keys[active]; !ok {
+			return nil, fmt.Errorf("active signing key %q is not in JWT_SIGNING_KEYS", active)
+		}
+		ring.activeID = active
+	}
+
+	return ring, nil
+}
+
+// temporarySigningKeyring generates a key for local development. Tokens signed
+// with it stop validating when the server restarts.
+func temporarySigningKeyring() (*signingKeyring, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	log.Println("Warning: JWT_SIGNING_KEYS is not set, using a temporary signing key")
+
+	return &signingKeyring{
+		activeID: "dev",
+		keys: map[string]*signingKey{
+			"dev": {id: "dev", method: jwt.SigningMethodEdDSA, signer: private},
+		},
+		order: []string{"dev"},
+	}, nil
+}
+
+// parseSigningKey decodes a base64 encoded PEM private key. RSA keys sign
+// with RS256 and Ed25519 keys with EdDSA.
+func parseSigningKey(id string, encoded string) (*signingKey, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("signing key %q must be a base64 encoded PEM private key", id)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %q must be a base64 encoded PEM private key", id)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key %q: %w", id, err)
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA signing key %q must be at least %d bits", id, minRSAKeyBits)
+		}
+		return &signingKey{id: id, method: jwt.SigningMethodRS256, signer: key}, nil
+	case ed25519.PrivateKey:
+		return &signingKey{id: id, method: jwt.SigningMethodEdDSA, signer: key}, nil
+	}
+
+	return nil, fmt.Errorf("signing key %q must be an RSA or Ed25519 key", id)
+}
+
+// SigningKeySet returns the public keys tokens may be signed with, as a JSON
+// Web Key Set for other services to verify access tokens
+func SigningKeySet() (*models.JWKSResponse, error) {
+	ring, err := currentSigningKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.JWKSResponse{Keys: []models.JWK{}}
+	for _, id := range ring.order {
+		key := ring.keys[id]
+		jwk := models.JWK{
+			KeyID:     key.id,
+			Use:       "sig",
+			Algorithm: key.method.Alg(),
+		}
+
+		switch public := key.signer.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.Modulus = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.Exponent = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+
+		response.Keys = append(response.Keys, jwk)
+	}
+
+	return response, nil
+}
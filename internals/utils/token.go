@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"time"
+)
+
+// defaultRefreshTokenExpiry is used when REFRESH_TOKEN_EXPIRY is not set or invalid
+const defaultRefreshTokenExpiry = 30 * 24 * time.Hour
+
+// RefreshTokenExpiry returns how long refresh tokens are valid
+func RefreshTokenExpiry() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("REFRESH_TOKEN_EXPIRY")); err == nil && d > 0 {
+		return d
+	}
+	return defaultRefreshTokenExpiry
+}
+
+// GenerateOpaqueToken returns a random URL-safe token (refresh, reset or
+// verification token) and the hash to store for it
+func GenerateOpaqueToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashOpaqueToken(token), nil
+}
+
+// HashOpaqueToken hashes a token for storage and lookup.
+// Tokens are random, so a fast hash is sufficient.
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyPrefix marks personal API keys so they can be told apart from JWTs
+const APIKeyPrefix = "hrk_"
+
+// GenerateAPIKey returns a new API key, its display prefix and the hash to store for it
+func GenerateAPIKey() (string, string, string, error) {
+	token, _, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	key := APIKeyPrefix + token
+	return key, key[:len(APIKeyPrefix)+8], HashOpaqueToken(key), nil
+}
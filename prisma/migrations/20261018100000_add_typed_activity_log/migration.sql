@@ -0,0 +1,50 @@
+-- CreateEnum
+CREATE TYPE "ActivityType" AS ENUM ('SIGNUP', 'LOGIN', 'SETTINGS_UPDATED', 'TEMPLATE_UPDATED', 'CONTACT_UPDATED', 'PDF_UPLOADED', 'CAMPAIGN_STARTED', 'CAMPAIGN_FINISHED', 'EMAIL_SEND_FAILED');
+
+-- CreateEnum
+CREATE TYPE "CampaignStatus" AS ENUM ('RUNNING', 'COMPLETED');
+
+-- AlterTable
+-- Existing activities were only ever written for PDF uploads
+ALTER TABLE "Activity" ADD COLUMN     "metadata" JSONB,
+ADD COLUMN     "targetId" TEXT,
+ADD COLUMN     "targetType" TEXT,
+ADD COLUMN     "type" "ActivityType" NOT NULL DEFAULT 'PDF_UPLOADED';
+
+ALTER TABLE "Activity" ALTER COLUMN "type" DROP DEFAULT;
+
+-- AlterTable
+ALTER TABLE "EmailLog" ADD COLUMN     "campaignId" TEXT;
+
+-- CreateTable
+CREATE TABLE "Campaign" (
+    "id" TEXT NOT NULL,
+    "status" "CampaignStatus" NOT NULL DEFAULT 'RUNNING',
+    "totalContacts" INTEGER NOT NULL,
+    "sentCount" INTEGER NOT NULL DEFAULT 0,
+    "failedCount" INTEGER NOT NULL DEFAULT 0,
+    "startedAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "finishedAt" TIMESTAMP(3),
+    "updatedAt" TIMESTAMP(3) NOT NULL,
+    "userId" TEXT NOT NULL,
+
+    CONSTRAINT "Campaign_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE INDEX "Activity_userId_createdAt_idx" ON "Activity"("userId", "createdAt");
+
+-- CreateIndex
+CREATE INDEX "Activity_targetType_targetId_idx" ON "Activity"("targetType", "targetId");
+
+-- CreateIndex
+CREATE INDEX "Campaign_userId_idx" ON "Campaign"("userId");
+
+-- CreateIndex
+CREATE INDEX "EmailLog_campaignId_idx" ON "EmailLog"("campaignId");
+
+-- AddForeignKey
+ALTER TABLE "Campaign" ADD CONSTRAINT "Campaign_userId_fkey" FOREIGN KEY ("userId") REFERENCES "User"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "EmailLog" ADD CONSTRAINT "EmailLog_campaignId_fkey" FOREIGN KEY ("campaignId") REFERENCES "Campaign"("id") ON DELETE SET NULL ON UPDATE CASCADE;
@@ -0,0 +1,3 @@
+-- AlterTable
+ALTER TABLE "Contact" ADD COLUMN     "customFields" JSONB,
+ADD COLUMN     "tags" TEXT[] DEFAULT ARRAY[]::TEXT[];
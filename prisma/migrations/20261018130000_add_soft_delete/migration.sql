@@ -0,0 +1,30 @@
+-- AlterEnum
+-- This migration adds more than one value to an enum.
+-- With PostgreSQL versions 11 and earlier, this is not possible
+-- in a single migration. This can be worked around by creating
+-- multiple migrations, each migration adding only one value to
+-- the enum.
+
+
+ALTER TYPE "ActivityType" ADD VALUE 'CONTACT_DELETED';
+ALTER TYPE "ActivityType" ADD VALUE 'CONTACT_RESTORED';
+ALTER TYPE "ActivityType" ADD VALUE 'TEMPLATE_DELETED';
+ALTER TYPE "ActivityType" ADD VALUE 'TEMPLATE_RESTORED';
+
+-- DropIndex
+DROP INDEX "Template_userId_key";
+
+-- AlterTable
+ALTER TABLE "Contact" ADD COLUMN     "deletedAt" TIMESTAMP(3);
+
+-- AlterTable
+ALTER TABLE "Template" ADD COLUMN     "deletedAt" TIMESTAMP(3);
+
+-- CreateIndex
+CREATE INDEX "Contact_deletedAt_idx" ON "Contact"("deletedAt");
+
+-- CreateIndex
+CREATE INDEX "Template_userId_idx" ON "Template"("userId");
+
+-- CreateIndex
+CREATE INDEX "Template_deletedAt_idx" ON "Template"("deletedAt");
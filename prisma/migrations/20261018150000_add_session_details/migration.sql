@@ -0,0 +1,8 @@
+-- AlterEnum
+ALTER TYPE "ActivityType" ADD VALUE 'SESSION_REVOKED';
+
+-- AlterTable
+ALTER TABLE "Session" ADD COLUMN     "ipAddress" TEXT,
+ADD COLUMN     "lastSeenAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+ADD COLUMN     "startedAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+ADD COLUMN     "userAgent" TEXT;
@@ -0,0 +1,11 @@
+-- AlterEnum
+ALTER TYPE "ActivityType" ADD VALUE 'EMAIL_VERIFIED';
+
+-- AlterEnum
+ALTER TYPE "AuthTokenPurpose" ADD VALUE 'EMAIL_VERIFICATION';
+
+-- AlterTable
+ALTER TABLE "User" ADD COLUMN     "emailVerifiedAt" TIMESTAMP(3);
+
+-- Existing accounts predate verification and keep their sending rights
+UPDATE "User" SET "emailVerifiedAt" = "createdAt";
@@ -0,0 +1,124 @@
+-- AlterEnum
+-- This migration adds more than one value to an enum.
+-- With PostgreSQL versions 11 and earlier, this is not possible
+-- in a single migration. This can be worked around by creating
+-- multiple migrations, each migration adding only one value to
+-- the enum.
+
+
+ALTER TYPE "ActivityType" ADD VALUE 'ORGANIZATION_CREATED';
+ALTER TYPE "ActivityType" ADD VALUE 'ORGANIZATION_UPDATED';
+ALTER TYPE "ActivityType" ADD VALUE 'MEMBER_INVITED';
+ALTER TYPE "ActivityType" ADD VALUE 'MEMBER_JOINED';
+ALTER TYPE "ActivityType" ADD VALUE 'MEMBER_ROLE_CHANGED';
+ALTER TYPE "ActivityType" ADD VALUE 'MEMBER_REMOVED';
+
+-- CreateEnum
+CREATE TYPE "OrganizationRole" AS ENUM ('OWNER', 'ADMIN', 'MEMBER');
+
+-- CreateTable
+CREATE TABLE "Organization" (
+    "id" TEXT NOT NULL,
+    "name" TEXT NOT NULL,
+    "personal" BOOLEAN NOT NULL DEFAULT false,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updatedAt" TIMESTAMP(3) NOT NULL,
+
+    CONSTRAINT "Organization_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateTable
+CREATE TABLE "Membership" (
+    "id" TEXT NOT NULL,
+    "role" "OrganizationRole" NOT NULL DEFAULT 'MEMBER',
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updatedAt" TIMESTAMP(3) NOT NULL,
+    "userId" TEXT NOT NULL,
+    "organizationId" TEXT NOT NULL,
+
+    CONSTRAINT "Membership_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateTable
+CREATE TABLE "OrganizationInvitation" (
+    "id" TEXT NOT NULL,
+    "email" TEXT NOT NULL,
+    "role" "OrganizationRole" NOT NULL DEFAULT 'MEMBER',
+    "tokenHash" TEXT NOT NULL,
+    "expiresAt" TIMESTAMP(3) NOT NULL,
+    "acceptedAt" TIMESTAMP(3),
+    "revokedAt" TIMESTAMP(3),
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "organizationId" TEXT NOT NULL,
+    "invitedById" TEXT NOT NULL,
+
+    CONSTRAINT "OrganizationInvitation_pkey" PRIMARY KEY ("id")
+);
+
+-- Backfill a personal organization for every existing user. The organization
+-- reuses the user's id so existing rows can be linked without a lookup table.
+INSERT INTO "Organization" ("id", "name", "personal", "updatedAt")
+SELECT "id", "name" || '''s workspace', true, CURRENT_TIMESTAMP FROM "User";
+
+INSERT INTO "Membership" ("id", "role", "updatedAt", "userId", "organizationId")
+SELECT gen_random_uuid()::text, 'OWNER', CURRENT_TIMESTAMP, "id", "id" FROM "User";
+
+-- AlterTable
+ALTER TABLE "Contact" ADD COLUMN     "organizationId" TEXT;
+UPDATE "Contact" SET "organizationId" = "userId";
+ALTER TABLE "Contact" ALTER COLUMN "organizationId" SET NOT NULL;
+
+-- AlterTable
+ALTER TABLE "Template" ADD COLUMN     "organizationId" TEXT;
+UPDATE "Template" SET "organizationId" = "userId";
+ALTER TABLE "Template" ALTER COLUMN "organizationId" SET NOT NULL;
+
+-- AlterTable
+ALTER TABLE "Campaign" ADD COLUMN     "organizationId" TEXT;
+UPDATE "Campaign" SET "organizationId" = "userId";
+ALTER TABLE "Campaign" ALTER COLUMN "organizationId" SET NOT NULL;
+
+-- CreateIndex
+CREATE INDEX "Contact_organizationId_idx" ON "Contact"("organizationId");
+
+-- CreateIndex
+CREATE INDEX "Template_organizationId_idx" ON "Template"("organizationId");
+
+-- CreateIndex
+CREATE INDEX "Campaign_organizationId_idx" ON "Campaign"("organizationId");
+
+-- CreateIndex
+CREATE INDEX "Membership_userId_idx" ON "Membership"("userId");
+
+-- CreateIndex
+CREATE UNIQUE INDEX "Membership_organizationId_userId_key" ON "Membership"("organizationId", "userId");
+
+-- CreateIndex
+CREATE UNIQUE INDEX "OrganizationInvitation_tokenHash_key" ON "OrganizationInvitation"("tokenHash");
+
+-- CreateIndex
+CREATE INDEX "OrganizationInvitation_organizationId_idx" ON "OrganizationInvitation"("organizationId");
+
+-- CreateIndex
+CREATE INDEX "OrganizationInvitation_email_idx" ON "OrganizationInvitation"("email");
+
+-- AddForeignKey
+ALTER TABLE "Contact" ADD CONSTRAINT "Contact_organizationId_fkey" FOREIGN KEY ("organizationId") REFERENCES "Organization"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "Template" ADD CONSTRAINT "Template_organizationId_fkey" FOREIGN KEY ("organizationId") REFERENCES "Organization"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "Campaign" ADD CONSTRAINT "Campaign_organizationId_fkey" FOREIGN KEY ("organizationId") REFERENCES "Organization"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "Membership" ADD CONSTRAINT "Membership_userId_fkey" FOREIGN KEY ("userId") REFERENCES "User"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "Membership" ADD CONSTRAINT "Membership_organizationId_fkey" FOREIGN KEY ("organizationId") REFERENCES "Organization"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "OrganizationInvitation" ADD CONSTRAINT "OrganizationInvitation_organizationId_fkey" FOREIGN KEY ("organizationId") REFERENCES "Organization"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "OrganizationInvitation" ADD CONSTRAINT "OrganizationInvitation_invitedById_fkey" FOREIGN KEY ("invitedById") REFERENCES "User"("id") ON DELETE CASCADE ON UPDATE CASCADE;
@@ -0,0 +1,42 @@
+-- AlterEnum
+ALTER TYPE "ActivityType" ADD VALUE 'IDENTITY_LINKED';
+
+-- CreateTable
+CREATE TABLE "OidcLoginState" (
+    "id" TEXT NOT NULL,
+    "stateHash" TEXT NOT NULL,
+    "nonce" TEXT NOT NULL,
+    "codeVerifier" TEXT NOT NULL,
+    "expiresAt" TIMESTAMP(3) NOT NULL,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "OidcLoginState_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateTable
+CREATE TABLE "UserIdentity" (
+    "id" TEXT NOT NULL,
+    "issuer" TEXT NOT NULL,
+    "subject" TEXT NOT NULL,
+    "email" TEXT NOT NULL,
+    "lastLoginAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "userId" TEXT NOT NULL,
+
+    CONSTRAINT "UserIdentity_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE UNIQUE INDEX "OidcLoginState_stateHash_key" ON "OidcLoginState"("stateHash");
+
+-- CreateIndex
+CREATE INDEX "OidcLoginState_expiresAt_idx" ON "OidcLoginState"("expiresAt");
+
+-- CreateIndex
+CREATE INDEX "UserIdentity_userId_idx" ON "UserIdentity"("userId");
+
+-- CreateIndex
+CREATE UNIQUE INDEX "UserIdentity_issuer_subject_key" ON "UserIdentity"("issuer", "subject");
+
+-- AddForeignKey
+ALTER TABLE "UserIdentity" ADD CONSTRAINT "UserIdentity_userId_fkey" FOREIGN KEY ("userId") REFERENCES "User"("id") ON DELETE CASCADE ON UPDATE CASCADE;
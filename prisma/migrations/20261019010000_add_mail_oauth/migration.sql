@@ -0,0 +1,36 @@
+-- AlterEnum
+-- This migration adds more than one value to an enum.
+-- With PostgreSQL versions 11 and earlier, this is not possible
+-- in a single migration. This can be worked around by creating
+-- multiple migrations, each migration adding only one value to
+-- the enum.
+
+
+ALTER TYPE "ActivityType" ADD VALUE 'MAIL_ACCOUNT_CONNECTED';
+ALTER TYPE "ActivityType" ADD VALUE 'MAIL_ACCOUNT_DISCONNECTED';
+
+-- AlterTable
+ALTER TABLE "User" ADD COLUMN     "mailOAuthConnectedAt" TIMESTAMP(3),
+ADD COLUMN     "mailOAuthEmail" TEXT,
+ADD COLUMN     "mailOAuthRefreshToken" TEXT;
+
+-- CreateTable
+CREATE TABLE "MailOAuthState" (
+    "id" TEXT NOT NULL,
+    "stateHash" TEXT NOT NULL,
+    "codeVerifier" TEXT NOT NULL,
+    "expiresAt" TIMESTAMP(3) NOT NULL,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "userId" TEXT NOT NULL,
+
+    CONSTRAINT "MailOAuthState_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE UNIQUE INDEX "MailOAuthState_stateHash_key" ON "MailOAuthState"("stateHash");
+
+-- CreateIndex
+CREATE INDEX "MailOAuthState_userId_idx" ON "MailOAuthState"("userId");
+
+-- AddForeignKey
+ALTER TABLE "MailOAuthState" ADD CONSTRAINT "MailOAuthState_userId_fkey" FOREIGN KEY ("userId") REFERENCES "User"("id") ON DELETE CASCADE ON UPDATE CASCADE;
@@ -0,0 +1,70 @@
+-- CreateEnum
+CREATE TYPE "SenderRotation" AS ENUM ('ROUND_ROBIN', 'LEAST_USED');
+
+-- AlterEnum
+-- This migration adds more than one value to an enum.
+-- With PostgreSQL versions 11 and earlier, this is not possible
+-- in a single migration. This can be worked around by creating
+-- multiple migrations, each migration adding only one value to
+-- the enum.
+
+
+ALTER TYPE "ActivityType" ADD VALUE 'SENDER_ACCOUNT_CREATED';
+ALTER TYPE "ActivityType" ADD VALUE 'SENDER_ACCOUNT_UPDATED';
+ALTER TYPE "ActivityType" ADD VALUE 'SENDER_ACCOUNT_DELETED';
+
+-- AlterTable
+ALTER TABLE "Campaign" ADD COLUMN     "senderRotation" "SenderRotation" NOT NULL DEFAULT 'ROUND_ROBIN';
+
+-- AlterTable
+ALTER TABLE "EmailLog" ADD COLUMN     "senderAccountId" TEXT;
+
+-- CreateTable
+CREATE TABLE "SenderAccount" (
+    "id" TEXT NOT NULL,
+    "name" TEXT NOT NULL,
+    "email" TEXT NOT NULL,
+    "smtpHost" TEXT NOT NULL DEFAULT 'smtp.gmail.com',
+    "smtpPort" INTEGER NOT NULL DEFAULT 465,
+    "username" TEXT NOT NULL,
+    "password" TEXT NOT NULL,
+    "dailyLimit" INTEGER NOT NULL DEFAULT 20,
+    "signature" TEXT,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updatedAt" TIMESTAMP(3) NOT NULL,
+    "userId" TEXT NOT NULL,
+
+    CONSTRAINT "SenderAccount_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateTable
+CREATE TABLE "CampaignSender" (
+    "campaignId" TEXT NOT NULL,
+    "senderAccountId" TEXT NOT NULL,
+
+    CONSTRAINT "CampaignSender_pkey" PRIMARY KEY ("campaignId","senderAccountId")
+);
+
+-- CreateIndex
+CREATE INDEX "EmailLog_senderAccountId_idx" ON "EmailLog"("senderAccountId");
+
+-- CreateIndex
+CREATE INDEX "SenderAccount_userId_idx" ON "SenderAccount"("userId");
+
+-- CreateIndex
+CREATE UNIQUE INDEX "SenderAccount_userId_email_key" ON "SenderAccount"("userId", "email");
+
+-- CreateIndex
+CREATE INDEX "CampaignSender_senderAccountId_idx" ON "CampaignSender"("senderAccountId");
+
+-- AddForeignKey
+ALTER TABLE "EmailLog" ADD CONSTRAINT "EmailLog_senderAccountId_fkey" FOREIGN KEY ("senderAccountId") REFERENCES "SenderAccount"("id") ON DELETE SET NULL ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "SenderAccount" ADD CONSTRAINT "SenderAccount_userId_fkey" FOREIGN KEY ("userId") REFERENCES "User"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "CampaignSender" ADD CONSTRAINT "CampaignSender_campaignId_fkey" FOREIGN KEY ("campaignId") REFERENCES "Campaign"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "CampaignSender" ADD CONSTRAINT "CampaignSender_senderAccountId_fkey" FOREIGN KEY ("senderAccountId") REFERENCES "SenderAccount"("id") ON DELETE CASCADE ON UPDATE CASCADE;
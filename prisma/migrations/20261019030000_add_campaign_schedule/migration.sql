@@ -0,0 +1,26 @@
+-- AlterEnum
+-- This migration adds more than one value to an enum.
+-- With PostgreSQL versions 11 and earlier, this is not possible
+-- in a single migration. This can be worked around by creating
+-- multiple migrations, each migration adding only one value to
+-- the enum.
+
+
+ALTER TYPE "CampaignStatus" ADD VALUE 'SCHEDULED';
+ALTER TYPE "CampaignStatus" ADD VALUE 'PAUSED';
+
+-- AlterTable
+ALTER TABLE "Campaign" ADD COLUMN     "nextRunAt" TIMESTAMP(3),
+ADD COLUMN     "scheduledAt" TIMESTAMP(3),
+ADD COLUMN     "sendWindows" JSONB,
+ADD COLUMN     "timezone" TEXT,
+ADD COLUMN     "useRecipientTimezone" BOOLEAN NOT NULL DEFAULT false;
+
+-- AlterTable
+ALTER TABLE "Contact" ADD COLUMN     "timezone" TEXT;
+
+-- AlterTable
+ALTER TABLE "User" ADD COLUMN     "timezone" TEXT;
+
+-- CreateIndex
+CREATE INDEX "Campaign_status_nextRunAt_idx" ON "Campaign"("status", "nextRunAt");
@@ -0,0 +1,5 @@
+-- AlterTable
+ALTER TABLE "Campaign" ADD COLUMN     "maxDelaySeconds" INTEGER NOT NULL DEFAULT 120,
+ADD COLUMN     "maxPerHour" INTEGER,
+ADD COLUMN     "minDelaySeconds" INTEGER NOT NULL DEFAULT 120,
+ADD COLUMN     "warmUpDays" INTEGER;
@@ -0,0 +1,116 @@
+-- CreateEnum
+CREATE TYPE "EnrollmentStatus" AS ENUM ('ACTIVE', 'COMPLETED', 'STOPPED');
+
+-- CreateEnum
+CREATE TYPE "EnrollmentStopReason" AS ENUM ('REPLIED', 'BOUNCED', 'UNSUBSCRIBED', 'MANUAL', 'FAILED');
+
+-- AlterEnum
+-- This migration adds more than one value to an enum.
+-- With PostgreSQL versions 11 and earlier, this is not possible
+-- in a single migration. This can be worked around by creating
+-- multiple migrations, each migration adding only one value to
+-- the enum.
+
+
+ALTER TYPE "ActivityType" ADD VALUE 'SEQUENCE_CREATED';
+ALTER TYPE "ActivityType" ADD VALUE 'SEQUENCE_UPDATED';
+ALTER TYPE "ActivityType" ADD VALUE 'SEQUENCE_DELETED';
+ALTER TYPE "ActivityType" ADD VALUE 'FOLLOW_UPS_STOPPED';
+ALTER TYPE "ActivityType" ADD VALUE 'CONTACT_UNSUBSCRIBED';
+
+-- AlterTable
+ALTER TABLE "Campaign" ADD COLUMN     "sequenceId" TEXT;
+
+-- AlterTable
+ALTER TABLE "Contact" ADD COLUMN     "unsubscribedAt" TIMESTAMP(3);
+
+-- CreateTable
+CREATE TABLE "Sequence" (
+    "id" TEXT NOT NULL,
+    "name" TEXT NOT NULL,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updatedAt" TIMESTAMP(3) NOT NULL,
+    "userId" TEXT NOT NULL,
+    "organizationId" TEXT NOT NULL,
+
+    CONSTRAINT "Sequence_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateTable
+CREATE TABLE "SequenceStep" (
+    "id" TEXT NOT NULL,
+    "position" INTEGER NOT NULL,
+    "delayDays" INTEGER NOT NULL,
+    "subject" TEXT,
+    "body" TEXT NOT NULL,
+    "sequenceId" TEXT NOT NULL,
+
+    CONSTRAINT "SequenceStep_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateTable
+CREATE TABLE "SequenceEnrollment" (
+    "id" TEXT NOT NULL,
+    "status" "EnrollmentStatus" NOT NULL DEFAULT 'ACTIVE',
+    "stepsSent" INTEGER NOT NULL DEFAULT 0,
+    "nextSendAt" TIMESTAMP(3),
+    "failedAttempts" INTEGER NOT NULL DEFAULT 0,
+    "stopReason" "EnrollmentStopReason",
+    "stoppedAt" TIMESTAMP(3),
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updatedAt" TIMESTAMP(3) NOT NULL,
+    "sequenceId" TEXT NOT NULL,
+    "campaignId" TEXT NOT NULL,
+    "contactId" TEXT NOT NULL,
+    "senderAccountId" TEXT,
+
+    CONSTRAINT "SequenceEnrollment_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE INDEX "Campaign_sequenceId_idx" ON "Campaign"("sequenceId");
+
+-- CreateIndex
+CREATE INDEX "Sequence_organizationId_idx" ON "Sequence"("organizationId");
+
+-- CreateIndex
+CREATE UNIQUE INDEX "SequenceStep_sequenceId_position_key" ON "SequenceStep"("sequenceId", "position");
+
+-- CreateIndex
+CREATE INDEX "SequenceEnrollment_status_nextSendAt_idx" ON "SequenceEnrollment"("status", "nextSendAt");
+
+-- CreateIndex
+CREATE INDEX "SequenceEnrollment_sequenceId_idx" ON "SequenceEnrollment"("sequenceId");
+
+-- CreateIndex
+CREATE INDEX "SequenceEnrollment_contactId_idx" ON "SequenceEnrollment"("contactId");
+
+-- CreateIndex
+CREATE INDEX "SequenceEnrollment_senderAccountId_idx" ON "SequenceEnrollment"("senderAccountId");
+
+-- CreateIndex
+CREATE UNIQUE INDEX "SequenceEnrollment_campaignId_contactId_key" ON "SequenceEnrollment"("campaignId", "contactId");
+
+-- AddForeignKey
+ALTER TABLE "Campaign" ADD CONSTRAINT "Campaign_sequenceId_fkey" FOREIGN KEY ("sequenceId") REFERENCES "Sequence"("id") ON DELETE SET NULL ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "Sequence" ADD CONSTRAINT "Sequence_userId_fkey" FOREIGN KEY ("userId") REFERENCES "User"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "Sequence" ADD CONSTRAINT "Sequence_organizationId_fkey" FOREIGN KEY ("organizationId") REFERENCES "Organization"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "SequenceStep" ADD CONSTRAINT "SequenceStep_sequenceId_fkey" FOREIGN KEY ("sequenceId") REFERENCES "Sequence"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "SequenceEnrollment" ADD CONSTRAINT "SequenceEnrollment_sequenceId_fkey" FOREIGN KEY ("sequenceId") REFERENCES "Sequence"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "SequenceEnrollment" ADD CONSTRAINT "SequenceEnrollment_campaignId_fkey" FOREIGN KEY ("campaignId") REFERENCES "Campaign"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "SequenceEnrollment" ADD CONSTRAINT "SequenceEnrollment_contactId_fkey" FOREIGN KEY ("contactId") REFERENCES "Contact"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "SequenceEnrollment" ADD CONSTRAINT "SequenceEnrollment_senderAccountId_fkey" FOREIGN KEY ("senderAccountId") REFERENCES "SenderAccount"("id") ON DELETE SET NULL ON UPDATE CASCADE;
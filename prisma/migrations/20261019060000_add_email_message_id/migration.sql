@@ -0,0 +1,5 @@
+-- AlterTable
+ALTER TABLE "EmailLog" ADD COLUMN     "messageId" TEXT;
+
+-- CreateIndex
+CREATE INDEX "EmailLog_messageId_idx" ON "EmailLog"("messageId");
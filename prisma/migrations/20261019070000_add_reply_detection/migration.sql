@@ -0,0 +1,47 @@
+-- AlterEnum
+ALTER TYPE "ActivityType" ADD VALUE 'REPLY_RECEIVED';
+
+-- AlterTable
+ALTER TABLE "SenderAccount" ADD COLUMN     "imapEnabled" BOOLEAN NOT NULL DEFAULT false,
+ADD COLUMN     "imapError" TEXT,
+ADD COLUMN     "imapHost" TEXT NOT NULL DEFAULT 'imap.gmail.com',
+ADD COLUMN     "imapLastUid" BIGINT,
+ADD COLUMN     "imapPort" INTEGER NOT NULL DEFAULT 993,
+ADD COLUMN     "imapSyncedAt" TIMESTAMP(3),
+ADD COLUMN     "imapUidValidity" BIGINT;
+
+-- CreateTable
+CREATE TABLE "InboundMessage" (
+    "id" TEXT NOT NULL,
+    "messageId" TEXT NOT NULL,
+    "inReplyTo" TEXT,
+    "fromEmail" TEXT NOT NULL,
+    "fromName" TEXT,
+    "subject" TEXT NOT NULL,
+    "body" TEXT NOT NULL,
+    "receivedAt" TIMESTAMP(3) NOT NULL,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "contactId" TEXT NOT NULL,
+    "emailLogId" TEXT,
+    "senderAccountId" TEXT,
+
+    CONSTRAINT "InboundMessage_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE INDEX "InboundMessage_contactId_idx" ON "InboundMessage"("contactId");
+
+-- CreateIndex
+CREATE INDEX "InboundMessage_emailLogId_idx" ON "InboundMessage"("emailLogId");
+
+-- CreateIndex
+CREATE UNIQUE INDEX "InboundMessage_senderAccountId_messageId_key" ON "InboundMessage"("senderAccountId", "messageId");
+
+-- AddForeignKey
+ALTER TABLE "InboundMessage" ADD CONSTRAINT "InboundMessage_contactId_fkey" FOREIGN KEY ("contactId") REFERENCES "Contact"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "InboundMessage" ADD CONSTRAINT "InboundMessage_emailLogId_fkey" FOREIGN KEY ("emailLogId") REFERENCES "EmailLog"("id") ON DELETE SET NULL ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "InboundMessage" ADD CONSTRAINT "InboundMessage_senderAccountId_fkey" FOREIGN KEY ("senderAccountId") REFERENCES "SenderAccount"("id") ON DELETE SET NULL ON UPDATE CASCADE;
@@ -0,0 +1,48 @@
+-- AlterTable
+ALTER TABLE "Company" ADD COLUMN     "organizationId" TEXT;
+
+-- Companies move to the owner's personal organization, which reuses the user's id
+UPDATE "Company" SET "organizationId" = "userId";
+ALTER TABLE "Company" ALTER COLUMN "organizationId" SET NOT NULL;
+
+-- Contacts of team organizations get a company of their own organization,
+-- merging the companies of members with the same normalized name
+INSERT INTO "Company" ("id", "name", "normalizedName", "domain", "website", "notes", "createdAt", "updatedAt", "userId", "organizationId")
+SELECT DISTINCT ON (ct."organizationId", co."normalizedName")
+    gen_random_uuid()::text, co."name", co."normalizedName", co."domain", co."website", co."notes", co."createdAt", CURRENT_TIMESTAMP, co."userId", ct."organizationId"
+FROM "Contact" ct
+JOIN "Company" co ON co."id" = ct."companyId"
+WHERE ct."organizationId" <> co."organizationId"
+  AND NOT EXISTS (
+    SELECT 1 FROM "Company" x
+    WHERE x."organizationId" = ct."organizationId" AND x."normalizedName" = co."normalizedName"
+  )
+ORDER BY ct."organizationId", co."normalizedName", co."createdAt";
+
+UPDATE "Contact" ct SET "companyId" = x."id"
+FROM "Company" co, "Company" x
+WHERE co."id" = ct."companyId"
+  AND ct."organizationId" <> co."organizationId"
+  AND x."organizationId" = ct."organizationId"
+  AND x."normalizedName" = co."normalizedName";
+
+-- DropForeignKey
+ALTER TABLE "Company" DROP CONSTRAINT "Company_userId_fkey";
+
+-- DropIndex
+DROP INDEX "Company_userId_domain_idx";
+
+-- DropIndex
+DROP INDEX "Company_userId_normalizedName_key";
+
+-- AlterTable
+ALTER TABLE "Company" DROP COLUMN "userId";
+
+-- CreateIndex
+CREATE INDEX "Company_organizationId_domain_idx" ON "Company"("organizationId", "domain");
+
+-- CreateIndex
+CREATE UNIQUE INDEX "Company_organizationId_normalizedName_key" ON "Company"("organizationId", "normalizedName");
+
+-- AddForeignKey
+ALTER TABLE "Company" ADD CONSTRAINT "Company_organizationId_fkey" FOREIGN KEY ("organizationId") REFERENCES "Organization"("id") ON DELETE CASCADE ON UPDATE CASCADE;